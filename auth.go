@@ -1,9 +1,16 @@
+// Package auth is a thin gear middleware on top of package jwt.
+// It only extracts the token from a gear.Context and maps verification results to
+// the context and HTTP errors, all signing, verifying and claims logic lives in package jwt,
+// so adapters for other routers can be built on package jwt without duplicating crypto code.
 package auth
 
 import (
-	"strings"
+	"context"
+	"errors"
+	"time"
 
 	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/go-http-utils/cookie"
 	"github.com/teambition/gear"
 	"github.com/teambition/gear-auth/jwt"
 )
@@ -14,23 +21,39 @@ const Version = "1.7.0"
 // TokenExtractor is a function that takes a gear.Context as input and
 // returns either a string token or an empty string. Default to:
 //
-//  func(ctx *gear.Context) (token string) {
-//  	if val := ctx.Get("Authorization"); strings.HasPrefix(val, "BEARER ") {
-//  		token = val[7:]
-//  	} else {
-//  		token = ctx.Param("access_token")
-//  	}
-//  	return
-//  }
+//  auth.ExtractorChain(auth.BearerExtractor, auth.FromQuery("access_token"))
 //
 type TokenExtractor func(ctx *gear.Context) (token string)
 
 // Auth is helper type. It combine JWT and Crypto object, and some useful mothod for JWT.
 // You can use it as a gear middleware.
 type Auth struct {
-	j       *jwt.JWT
-	ex      TokenExtractor
-	skipper func(*gear.Context) bool
+	j                 *jwt.JWT
+	v                 jwt.Verifier
+	ex                TokenExtractor
+	skipper           func(*gear.Context) bool
+	remember          *jwt.JWT
+	rememberEx        TokenExtractor
+	renew             func(ctx *gear.Context, token string) error
+	fingerprint       string
+	fingerprintOpts   *cookie.Options
+	refreshCookie     string
+	refreshCookieOpts *cookie.Options
+	binding           func(*gear.Context, josejwt.Claims) error
+	authorizer        func(*gear.Context, josejwt.Claims) error
+	tenant            jwt.TenantExtractor
+	report            func(*gear.Context, josejwt.Claims, error)
+	canary            *jwt.JWT
+	divergence        func(ctx *gear.Context, token string, err, canaryErr error)
+	nearRatio         float64
+	nearExpiry        func(ctx *gear.Context, claims josejwt.Claims, remaining time.Duration)
+	checks            []healthCheck
+	timeout           time.Duration
+	timeoutErr        *gear.Error
+	schemes           map[string]*SecurityScheme
+	realm             string
+	errorHandler      func(*gear.Context, error) error
+	rolesClaim        string
 }
 
 // New returns a Auth instance.
@@ -40,14 +63,9 @@ type Auth struct {
 func New(keys ...interface{}) *Auth {
 	a := new(Auth)
 	a.SetJWT(jwt.New(keys...))
-	a.ex = func(ctx *gear.Context) (token string) {
-		if val := ctx.GetHeader("Authorization"); strings.HasPrefix(val, "Bearer ") {
-			token = val[7:]
-		} else {
-			token = ctx.Query("access_token")
-		}
-		return
-	}
+	a.ex = ExtractorChain(BearerExtractor, FromQuery("access_token"))
+	a.schemes = defaultSecuritySchemes()
+	a.rolesClaim = "roles"
 	return a
 }
 
@@ -56,23 +74,309 @@ func (a *Auth) JWT() *jwt.JWT {
 	return a.j
 }
 
-// SetJWT set a JWT instance to auth.
+// SetJWT set a JWT instance to auth, it is also used as the verifier.
 func (a *Auth) SetJWT(j *jwt.JWT) {
 	a.j = j
+	a.v = j
+}
+
+// SetVerifier set a custom verifier to auth, tokens will be verified by it instead of the JWT instance.
+// It is useful for handler tests with jwttest.Static:
+//
+//  auther.SetVerifier(jwttest.NewStatic().Add("alice", josejwt.Claims{"sub": "alice"}))
+//
+func (a *Auth) SetVerifier(v jwt.Verifier) *Auth {
+	if v == nil {
+		panic(errors.New("invalid verifier"))
+	}
+	a.v = v
+	return a
+}
+
+// WithJWT returns a copy of auth for a route group that verifies tokens with j, all other options are shared.
+// It is used with jwt.JWT.WithValidator to override validators per route without cloning the configuration.
+// The verified claims are cached apart from auth's, use FromCtx of the returned Auth in the route's handlers.
+//
+//  payments := auther.WithJWT(auther.JWT().WithValidator(&josejwt.Validator{
+//  	Expected: josejwt.Claims{"aud": "payments"},
+//  }))
+//  router := gear.NewRouter(gear.RouterOptions{Root: "/payments"})
+//  router.Use(payments.Serve)
+//
+func (a *Auth) WithJWT(j *jwt.JWT) *Auth {
+	if j == nil {
+		panic(errors.New("invalid jwt"))
+	}
+	r := *a
+	r.SetJWT(j)
+	return &r
 }
 
 // SetTokenParser set a custom tokenExtractor to auth.
+// The security schemes of the default extractor are removed, see SetSecurityScheme.
 func (a *Auth) SetTokenParser(ex TokenExtractor) {
 	a.ex = ex
+	a.schemes = nil
 }
 
 // SetSkipper set a skip function to auth.
 // If skip function return true, the auth middleware process will be skipped.
+// See SkipPaths, SkipMethods and SkipAny for common rules:
+//
+//  auther.SetSkipper(auth.SkipPaths("/health", "/metrics", "/login"))
+//
 func (a *Auth) SetSkipper(fn func(*gear.Context) bool) *Auth {
 	a.skipper = fn
 	return a
 }
 
+// SetVerifyTimeout set a time budget for token verification, including any remote lookups such as JWKS.
+// If verification doesn't finish in time, the request will be rejected with err (default to 401)
+// instead of stalling, the verification itself will go on in background.
+//
+//  auther.SetVerifyTimeout(time.Second, gear.ErrServiceUnavailable)
+//
+func (a *Auth) SetVerifyTimeout(timeout time.Duration, err *gear.Error) *Auth {
+	if timeout <= 0 {
+		panic(errors.New("invalid verify timeout"))
+	}
+	if err == nil {
+		err = gear.ErrUnauthorized
+	}
+	a.timeout = timeout
+	a.timeoutErr = err.WithMsg("token verification timed out")
+	return a
+}
+
+// verify verifies the token with the request's ctx, it gives up when the client has gone away.
+// Verifiers implementing jwt.ContextVerifier (such as jwt.JWT and jwt.RemoteVerifier) abandon their
+// remote lookups and key trials as well.
+func (a *Auth) verify(ctx context.Context, token string) (*jwt.Token, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if a.timeout <= 0 {
+		return a.verifyToken(ctx, token)
+	}
+	type result struct {
+		t   *jwt.Token
+		err error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		t, err := a.verifyToken(ctx, token)
+		ch <- result{t, err}
+	}()
+	timer := time.NewTimer(a.timeout)
+	defer timer.Stop()
+	select {
+	case res := <-ch:
+		return res.t, res.err
+	case <-timer.C:
+		return nil, a.timeoutErr
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (a *Auth) verifyToken(ctx context.Context, token string) (*jwt.Token, error) {
+	if v, ok := a.v.(jwt.ContextVerifier); ok {
+		return v.VerifyContext(ctx, token)
+	}
+	if v, ok := a.v.(jwt.TokenVerifier); ok {
+		return v.VerifyToken(token)
+	}
+	claims, err := a.v.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+	return &jwt.Token{Raw: token, Claims: claims, KeyIndex: -1}, nil
+}
+
+// SetBindingValidator set a per-request binding validator to auth. It will be called with
+// the verified claims, so claims like "device_id" or "ip_hash" can be checked against the live request,
+// which josejwt.Validator can't see. If it returns error, the request will be rejected with 401.
+//
+//  auther.SetBindingValidator(func(ctx *gear.Context, claims josejwt.Claims) error {
+//  	if claims.Get("device_id") != ctx.GetHeader("X-Device-Id") {
+//  		return errors.New("device mismatch")
+//  	}
+//  	return nil
+//  })
+//
+func (a *Auth) SetBindingValidator(fn func(ctx *gear.Context, claims josejwt.Claims) error) *Auth {
+	a.binding = fn
+	return a
+}
+
+// SetAuthorizer sets a function to authorize the request with the verified claims. It is called after
+// successful verification (and binding validation), requests are rejected with 403 if it returns error,
+// or with the error itself if it is a *gear.Error. Set nil to disable it.
+//
+//  auther.SetAuthorizer(func(ctx *gear.Context, claims josejwt.Claims) error {
+//  	if ctx.Method != http.MethodGet && claims.Get("role") != "admin" {
+//  		return errors.New("read only")
+//  	}
+//  	return nil
+//  })
+//
+func (a *Auth) SetAuthorizer(fn func(ctx *gear.Context, claims josejwt.Claims) error) *Auth {
+	a.authorizer = fn
+	return a
+}
+
+// SetTenant sets a TenantExtractor to extract the tenant identifier from the verified claims, it is
+// stored in the request's context and can be read by jwt.TenantFromContext with the gear.Context
+// or its children contexts. Tokens without tenant are rejected with 401. Set nil to disable it.
+//
+//  auther.SetTenant(jwt.TenantClaim("tid"))
+//  // or
+//  auther.SetTenant(jwt.TenantFromIssuer())
+//
+func (a *Auth) SetTenant(ex jwt.TenantExtractor) *Auth {
+	a.tenant = ex
+	return a
+}
+
+// SetReportOnly switches the middleware to shadow (report-only) mode: tokens are still verified and
+// the outcome is passed to report, but requests are never blocked. It is useful to roll the middleware
+// out in front of existing traffic before enforcing. FromCtx still returns the verification error.
+// Set nil to switch back to enforcing mode.
+//
+//  auther.SetReportOnly(func(ctx *gear.Context, claims josejwt.Claims, err error) {
+//  	if err != nil {
+//  		logger.Warning("auth failure", ctx.Path, err)
+//  	}
+//  })
+//
+func (a *Auth) SetReportOnly(report func(ctx *gear.Context, claims josejwt.Claims, err error)) *Auth {
+	a.report = report
+	return a
+}
+
+// SetCanary set a candidate JWT instance (e.g. with new keys) for dual-verify during key migrations.
+// Every token will be verified by the candidate too, and divergence will be called when the outcomes of
+// the current and candidate verification differ. It never affects the request outcome.
+//
+//  candidate := jwt.New([]byte("new key"))
+//  auther.SetCanary(candidate, func(ctx *gear.Context, token string, err, canaryErr error) {
+//  	logger.Warning("canary divergence", err, canaryErr)
+//  })
+//
+func (a *Auth) SetCanary(j *jwt.JWT, divergence func(ctx *gear.Context, token string, err, canaryErr error)) *Auth {
+	if j == nil || divergence == nil {
+		panic(errors.New("invalid canary arguments"))
+	}
+	a.canary = j
+	a.divergence = divergence
+	return a
+}
+
+func (a *Auth) verifyCanary(ctx *gear.Context, token string, err error) {
+	if _, canaryErr := a.canary.Verify(token); (err == nil) != (canaryErr == nil) {
+		a.divergence(ctx, token, err, canaryErr)
+	}
+}
+
+// SetNearExpiry set a hook that will be called when accepted tokens are within ratio (0 ~ 1) of
+// their lifetime ("exp" - "iat"), so operators can see whether clients refresh tokens properly
+// before tightening TTLs. Tokens without "exp" or "iat" are ignored.
+//
+//  auther.SetNearExpiry(0.1, func(ctx *gear.Context, claims josejwt.Claims, remaining time.Duration) {
+//  	nearExpiryCounter.Inc()
+//  })
+//
+func (a *Auth) SetNearExpiry(ratio float64, fn func(ctx *gear.Context, claims josejwt.Claims, remaining time.Duration)) *Auth {
+	if ratio <= 0 || ratio >= 1 {
+		panic(errors.New("invalid near expiry ratio"))
+	}
+	a.nearRatio = ratio
+	a.nearExpiry = fn
+	return a
+}
+
+func (a *Auth) checkNearExpiry(ctx *gear.Context, claims josejwt.Claims) {
+	exp, ok := claims.Expiration()
+	if !ok {
+		return
+	}
+	iat, ok := claims.IssuedAt()
+	if !ok || !exp.After(iat) {
+		return
+	}
+	remaining := time.Until(exp)
+	if float64(remaining) <= float64(exp.Sub(iat))*a.nearRatio {
+		a.nearExpiry(ctx, claims, remaining)
+	}
+}
+
+// SetRememberMe enables long-lived "remember me" tokens. The remember-me tokens are signed
+// and verified by j, which should have its own keys, expiresIn and validator. When no session
+// token is found in the request but ex extracts a valid remember-me token, a new short-lived
+// session token will be signed with the claims of remember-me token, except the time claims, "jti"
+// and "ver" as jwt.Refresh does, and renew will be called to deliver it to client, such as setting
+// a cookie or a response header.
+//
+//  remember := jwt.New([]byte("remember key"))
+//  remember.SetExpiresIn(time.Hour * 24 * 30)
+//  auther.SetRememberMe(remember, func(ctx *gear.Context) string {
+//  	cookie, _ := ctx.Req.Cookie("remember_me")
+//  	if cookie == nil {
+//  		return ""
+//  	}
+//  	return cookie.Value
+//  }, func(ctx *gear.Context, token string) error {
+//  	ctx.SetHeader("X-Access-Token", token)
+//  	return nil
+//  })
+//
+func (a *Auth) SetRememberMe(j *jwt.JWT, ex TokenExtractor, renew func(ctx *gear.Context, token string) error) *Auth {
+	if j == nil || ex == nil || renew == nil {
+		panic(errors.New("invalid remember-me arguments"))
+	}
+	a.remember = j
+	a.rememberEx = ex
+	a.renew = renew
+	return a
+}
+
+// RememberMe returns the JWT instance for remember-me tokens, it can be used to sign them.
+// It returns nil if remember-me is not enabled.
+func (a *Auth) RememberMe() *jwt.JWT {
+	return a.remember
+}
+
+// renewFromRememberMe verifies the remember-me token and signs a new session token.
+func (a *Auth) renewFromRememberMe(ctx *gear.Context) (*jwt.Token, error) {
+	token := a.rememberEx(ctx)
+	if token == "" {
+		return nil, nil
+	}
+	claims, err := a.remember.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+	session := josejwt.Claims{}
+	for key, val := range claims {
+		switch key {
+		case "exp", "iat", "nbf", "jti", "ver":
+		default:
+			session.Set(key, val)
+		}
+	}
+	if token, err = a.j.Sign(session); err == nil {
+		err = a.renew(ctx, token)
+	}
+	if err == nil {
+		// the session claims with "iat", "exp" and "jti" set by Sign.
+		session, err = a.j.Verify(token)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &jwt.Token{Raw: token, Claims: session, KeyIndex: -1}, nil
+}
+
 // New implements gear.Any interface, then we can use it with ctx.Any:
 //
 //  any, err := ctx.Any(auther)
@@ -84,22 +388,93 @@ func (a *Auth) SetSkipper(fn func(*gear.Context) bool) *Auth {
 // that is auth.FromCtx doing for us.
 //
 func (a *Auth) New(ctx *gear.Context) (val interface{}, err error) {
+	var t *jwt.Token
 	if token := a.ex(ctx); token != "" {
-		val, err = a.j.Verify(token)
+		t, err = a.verify(jwt.WithLookupCache(ctx), token)
+		if a.canary != nil {
+			a.verifyCanary(ctx, token, err)
+		}
+	} else if a.remember != nil {
+		t, err = a.renewFromRememberMe(ctx)
 	}
-	if val == nil {
+	if t != nil && a.fingerprint != "" {
+		if err = a.checkFingerprint(ctx, t.Claims); err != nil {
+			t = nil
+		}
+	}
+	if t != nil && a.binding != nil {
+		if err = a.binding(ctx, t.Claims); err != nil {
+			t = nil
+		}
+	}
+	if t != nil && a.tenant != nil {
+		if tenant := a.tenant(t.Claims); tenant == "" {
+			t, err = nil, errors.New("missing tenant")
+		} else {
+			ctx.WithContext(jwt.WithTenant(ctx.Context(), tenant))
+		}
+	}
+	if t != nil && a.authorizer != nil {
+		if e := a.authorizer(ctx, t.Claims); e != nil {
+			t, err = nil, failure(gear.ErrForbidden, e)
+		}
+	}
+	if t != nil && a.nearExpiry != nil {
+		a.checkNearExpiry(ctx, t.Claims)
+	}
+	if t != nil {
+		val = t.Claims
+		ctx.SetAny(authToken{a}, t)
+	} else {
 		// create a empty jwt.Claims
 		val = josejwt.Claims{}
 		if err == nil {
-			err = gear.ErrUnauthorized.WithMsg("no token found")
-		} else {
-			err = gear.ErrUnauthorized.From(err)
+			err = ErrNoToken
+			if e, _ := ctx.Any(bearerError{}); e != nil {
+				err = e.(error)
+			}
 		}
+		err = failure(gear.ErrUnauthorized, err)
 	}
 	ctx.SetAny(a, val)
+	if err != nil {
+		ctx.SetAny(authError{a}, err)
+	}
 	return
 }
 
+// authError is the key to cache verification error on gear.Context,
+// because gear.Context caches value only.
+type authError struct {
+	a *Auth
+}
+
+// authToken is the key to cache the verified token on gear.Context.
+type authToken struct {
+	a *Auth
+}
+
+// TokenFromCtx will parse and validate token from the ctx as FromCtx, and return the verified token,
+// with the raw token string, parsed header and verification metadata (such as the matched "kid"),
+// so downstream code can forward the token or log the kid without re-extracting and re-parsing.
+// If token not exists or validate failure, a error and nil returned.
+//
+//  t, err := auther.TokenFromCtx(ctx)
+//  if err == nil {
+//  	req.Header.Set("Authorization", "Bearer "+t.Raw)
+//  }
+//
+func (a *Auth) TokenFromCtx(ctx *gear.Context) (*jwt.Token, error) {
+	if _, err := a.FromCtx(ctx); err != nil {
+		return nil, err
+	}
+	val, err := ctx.Any(authToken{a})
+	if err != nil {
+		return nil, err
+	}
+	return val.(*jwt.Token), nil
+}
+
 // FromCtx will parse and validate token from the ctx, and return it as jwt.Claims.
 // If token not exists or validate failure, a error and a empty jwt.Claims instance returned.
 //
@@ -108,6 +483,11 @@ func (a *Auth) New(ctx *gear.Context) (val interface{}, err error) {
 //
 func (a *Auth) FromCtx(ctx *gear.Context) (josejwt.Claims, error) {
 	val, err := ctx.Any(a)
+	if err == nil {
+		if cached, e := ctx.Any(authError{a}); e == nil {
+			err = cached.(error)
+		}
+	}
 	return val.(josejwt.Claims), err
 }
 
@@ -125,6 +505,32 @@ func (a *Auth) Serve(ctx *gear.Context) error {
 	if a.skipper != nil && a.skipper(ctx) {
 		return nil
 	}
-	_, err := ctx.Any(a)
-	return err
+	claims, err := a.FromCtx(ctx)
+	if a.report != nil {
+		a.report(ctx, claims, err)
+		return nil
+	}
+	if err != nil {
+		return a.challenge(ctx, err)
+	}
+	return nil
+}
+
+// ServeOptional is a gear middleware as Serve, but requests without a valid token are not rejected.
+// Their claims in the context are empty and FromCtx returns the error, so downstream handlers can
+// branch on anonymous vs. authenticated users.
+//
+//  app.Use(auther.ServeOptional)
+//  app.Use(func(ctx *gear.Context) error {
+//  	if claims, err := auther.FromCtx(ctx); err == nil {
+//  		return ctx.JSON(200, claims) // authenticated
+//  	}
+//  	return ctx.JSON(200, map[string]string{"user": "anonymous"})
+//  })
+//
+func (a *Auth) ServeOptional(ctx *gear.Context) error {
+	if a.skipper == nil || !a.skipper(ctx) {
+		ctx.Any(a)
+	}
+	return nil
 }
@@ -1,16 +1,22 @@
 package auth
 
 import (
+	"context"
+	"errors"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/SermoDigital/jose/jws"
 	"github.com/SermoDigital/jose/jwt"
 	"github.com/mozillazg/request"
 	"github.com/stretchr/testify/assert"
 	"github.com/teambition/gear"
+	authjwt "github.com/teambition/gear-auth/jwt"
+	"github.com/teambition/gear-auth/jwt/jwttest"
 )
 
 func NewRequst() *request.Request {
@@ -144,4 +150,660 @@ func TestGearAuth(t *testing.T) {
 		assert.Equal(401, res.StatusCode)
 		res.Body.Close()
 	})
+	t.Run("should renew session token with remember-me token", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := New([]byte("my key 1"))
+		remember := authjwt.New([]byte("remember key"))
+		remember.SetExpiresIn(time.Hour)
+		assert.Panics(func() {
+			a.SetRememberMe(remember, nil, nil)
+		})
+		a.SetRememberMe(remember, func(ctx *gear.Context) string {
+			return ctx.GetHeader("X-Remember-Me")
+		}, func(ctx *gear.Context, token string) error {
+			ctx.SetHeader("X-Access-Token", token)
+			return nil
+		})
+		assert.Equal(remember, a.RememberMe())
+
+		app := gear.New()
+		app.UseHandler(a)
+		app.Use(func(ctx *gear.Context) error {
+			claims, _ := a.FromCtx(ctx)
+			return ctx.JSON(200, claims)
+		})
+		srv := app.Start()
+		defer srv.Close()
+
+		req := NewRequst()
+		host := "http://" + srv.Addr().String()
+
+		token, _ := remember.Sign(jwt.Claims{"hello": "world", "jti": "remember-jti", "ver": 2})
+		req.Headers["X-Remember-Me"] = token
+		res, err := req.Get(host)
+		assert.Nil(err)
+		assert.Equal(200, res.StatusCode)
+		session := res.Header.Get("X-Access-Token")
+		res.Body.Close()
+
+		claims, err := a.JWT().Verify(session)
+		assert.Nil(err)
+		assert.Equal("world", claims.Get("hello"))
+		assert.False(claims.Has("exp"))
+		// the session token has its own "jti", and "ver" of the remember-me token is not copied
+		assert.True(claims.Has("jti"))
+		assert.NotEqual("remember-jti", claims.Get("jti"))
+		assert.False(claims.Has("ver"))
+
+		req.Headers["X-Remember-Me"] = session
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(401, res.StatusCode)
+		assert.Equal("", res.Header.Get("X-Access-Token"))
+		res.Body.Close()
+	})
+	t.Run("should work with binding validator", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := New([]byte("my key"))
+		a.SetBindingValidator(func(ctx *gear.Context, claims jwt.Claims) error {
+			if claims.Get("device_id") != ctx.GetHeader("X-Device-Id") {
+				return errors.New("device mismatch")
+			}
+			return nil
+		})
+		app := gear.New()
+		app.UseHandler(a)
+		app.Use(func(ctx *gear.Context) error {
+			return ctx.End(204)
+		})
+		srv := app.Start()
+		defer srv.Close()
+
+		req := NewRequst()
+		host := "http://" + srv.Addr().String()
+
+		token, _ := a.JWT().Sign(jwt.Claims{"device_id": "abc"})
+		req.Headers["Authorization"] = "Bearer " + token
+		req.Headers["X-Device-Id"] = "xyz"
+		res, err := req.Get(host)
+		assert.Nil(err)
+		assert.Equal(401, res.StatusCode)
+		body, _ := res.Text()
+		assert.Equal(`{"error":"Unauthorized","message":"device mismatch"}`, body)
+
+		req.Headers["X-Device-Id"] = "abc"
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(204, res.StatusCode)
+		res.Body.Close()
+	})
+	t.Run("should work with authorizer", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := New([]byte("my key"))
+		a.SetAuthorizer(func(ctx *gear.Context, claims jwt.Claims) error {
+			if ctx.Method != http.MethodGet && claims.Get("role") != "admin" {
+				return errors.New("read only")
+			}
+			if claims.Get("role") == "banned" {
+				return gear.ErrNotFound.WithMsg("no such resource")
+			}
+			return nil
+		})
+		app := gear.New()
+		app.UseHandler(a)
+		app.Use(func(ctx *gear.Context) error {
+			return ctx.End(204)
+		})
+		srv := app.Start()
+		defer srv.Close()
+
+		req := NewRequst()
+		host := "http://" + srv.Addr().String()
+
+		res, err := req.Post(host)
+		assert.Nil(err)
+		assert.Equal(401, res.StatusCode)
+		res.Body.Close()
+
+		token, _ := a.JWT().Sign(jwt.Claims{"role": "user"})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(204, res.StatusCode)
+		res.Body.Close()
+
+		res, err = req.Post(host)
+		assert.Nil(err)
+		assert.Equal(403, res.StatusCode)
+		body, _ := res.Text()
+		assert.Equal(`{"error":"Forbidden","message":"read only"}`, body)
+
+		token, _ = a.JWT().Sign(jwt.Claims{"role": "banned"})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(404, res.StatusCode)
+		res.Body.Close()
+
+		token, _ = a.JWT().Sign(jwt.Claims{"role": "admin"})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err = req.Post(host)
+		assert.Nil(err)
+		assert.Equal(204, res.StatusCode)
+		res.Body.Close()
+	})
+	t.Run("should store tenant in context", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := New([]byte("my key"))
+		a.SetTenant(authjwt.TenantClaim("tid"))
+		app := gear.New()
+		app.UseHandler(a)
+		app.Use(func(ctx *gear.Context) error {
+			return ctx.HTML(200, authjwt.TenantFromContext(ctx))
+		})
+		srv := app.Start()
+		defer srv.Close()
+
+		req := NewRequst()
+		host := "http://" + srv.Addr().String()
+
+		token, _ := a.JWT().Sign(jwt.Claims{"sub": "alice"})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err := req.Get(host)
+		assert.Nil(err)
+		assert.Equal(401, res.StatusCode)
+		body, _ := res.Text()
+		assert.Equal(`{"error":"Unauthorized","message":"missing tenant"}`, body)
+
+		token, _ = a.JWT().Sign(jwt.Claims{"sub": "alice", "tid": "acme"})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(200, res.StatusCode)
+		body, _ = res.Text()
+		assert.Equal("acme", body)
+	})
+	t.Run("should not block in report-only mode", func(t *testing.T) {
+		assert := assert.New(t)
+
+		var reported []error
+		a := New([]byte("my key"))
+		a.SetReportOnly(func(ctx *gear.Context, claims jwt.Claims, err error) {
+			reported = append(reported, err)
+		})
+		app := gear.New()
+		app.UseHandler(a)
+		app.Use(func(ctx *gear.Context) error {
+			claims, err := a.FromCtx(ctx)
+			if err != nil {
+				return ctx.End(204)
+			}
+			return ctx.JSON(200, claims)
+		})
+		srv := app.Start()
+		defer srv.Close()
+
+		req := NewRequst()
+		host := "http://" + srv.Addr().String()
+
+		res, err := req.Get(host)
+		assert.Nil(err)
+		assert.Equal(204, res.StatusCode)
+		res.Body.Close()
+
+		token, _ := a.JWT().Sign(jwt.Claims{"hello": "world"})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(200, res.StatusCode)
+		res.Body.Close()
+
+		assert.Equal(2, len(reported))
+		assert.NotNil(reported[0])
+		assert.Nil(reported[1])
+	})
+	t.Run("should serve anonymous requests in optional mode", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := New([]byte("my key"))
+		app := gear.New()
+		app.Use(a.ServeOptional)
+		app.Use(func(ctx *gear.Context) error {
+			claims, err := a.FromCtx(ctx)
+			if err != nil {
+				return ctx.JSON(200, map[string]interface{}{"anonymous": true, "claims": len(claims)})
+			}
+			return ctx.JSON(200, claims)
+		})
+		srv := app.Start()
+		defer srv.Close()
+
+		req := NewRequst()
+		host := "http://" + srv.Addr().String()
+
+		res, err := req.Get(host)
+		assert.Nil(err)
+		assert.Equal(200, res.StatusCode)
+		body, _ := res.Text()
+		assert.Equal(`{"anonymous":true,"claims":0}`, body)
+
+		req.Headers["Authorization"] = "Bearer invalid"
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(200, res.StatusCode)
+		body, _ = res.Text()
+		assert.Equal(`{"anonymous":true,"claims":0}`, body)
+
+		token, _ := a.JWT().Sign(jwt.Claims{"sub": "alice"}, time.Duration(0))
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(200, res.StatusCode)
+		body, _ = res.Text()
+		assert.Contains(body, `"sub":"alice"`)
+	})
+
+	t.Run("should reject requests of Serve after ServeOptional", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := New([]byte("my key"))
+		app := gear.New()
+		app.Use(a.ServeOptional)
+		app.Use(a.Serve)
+		app.Use(func(ctx *gear.Context) error {
+			return ctx.End(204)
+		})
+		srv := app.Start()
+		defer srv.Close()
+
+		req := NewRequst()
+		host := "http://" + srv.Addr().String()
+
+		res, err := req.Get(host)
+		assert.Nil(err)
+		assert.Equal(401, res.StatusCode)
+		res.Body.Close()
+
+		req.Headers["Authorization"] = "Bearer invalid"
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(401, res.StatusCode)
+		res.Body.Close()
+
+		token, _ := a.JWT().Sign(jwt.Claims{"sub": "alice"})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(204, res.StatusCode)
+		res.Body.Close()
+	})
+
+	t.Run("should keep the cause of errors", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := New([]byte("my key"))
+		a.SetAuthorizer(func(ctx *gear.Context, claims jwt.Claims) error {
+			if claims.Get("sub") == "mallory" {
+				return errors.New("banned")
+			}
+			return nil
+		})
+		app := gear.New()
+		app.Use(func(ctx *gear.Context) error {
+			_, err := a.FromCtx(ctx)
+			var e *gear.Error
+			switch {
+			case errors.Is(err, ErrNoToken):
+				return ctx.End(200, []byte("no token"))
+			case errors.Is(err, authjwt.ErrTokenExpired) && errors.As(err, &e):
+				return ctx.End(200, []byte("refresh "+strconv.Itoa(e.Code)))
+			case errors.Is(err, authjwt.ErrBadSignature):
+				return ctx.End(200, []byte("forged"))
+			}
+			return err
+		})
+		srv := app.Start()
+		defer srv.Close()
+
+		req := NewRequst()
+		host := "http://" + srv.Addr().String()
+
+		res, err := req.Get(host)
+		assert.Nil(err)
+		body, _ := res.Text()
+		assert.Equal("no token", body)
+
+		token, _ := a.JWT().SignAt(time.Now().Add(-2*time.Hour), jwt.Claims{"sub": "alice"}, time.Hour)
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err = req.Get(host)
+		assert.Nil(err)
+		body, _ = res.Text()
+		assert.Equal("refresh 401", body)
+
+		token, _ = New([]byte("wrong key")).JWT().Sign(jwt.Claims{"sub": "alice"})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err = req.Get(host)
+		assert.Nil(err)
+		body, _ = res.Text()
+		assert.Equal("forged", body)
+
+		token, _ = a.JWT().Sign(jwt.Claims{"sub": "mallory"})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(403, res.StatusCode)
+		body, _ = res.Text()
+		assert.Equal(`{"error":"Forbidden","message":"banned"}`, body)
+	})
+
+	t.Run("should report canary divergence", func(t *testing.T) {
+		assert := assert.New(t)
+
+		var diverged []error
+		a := New([]byte("old key"), []byte("new key"))
+		assert.Panics(func() {
+			a.SetCanary(nil, nil)
+		})
+		a.SetCanary(authjwt.New([]byte("new key")), func(ctx *gear.Context, token string, err, canaryErr error) {
+			diverged = append(diverged, err, canaryErr)
+		})
+		app := gear.New()
+		app.UseHandler(a)
+		app.Use(func(ctx *gear.Context) error {
+			return ctx.End(204)
+		})
+		srv := app.Start()
+		defer srv.Close()
+
+		req := NewRequst()
+		host := "http://" + srv.Addr().String()
+
+		token, _ := authjwt.New([]byte("new key")).Sign(jwt.Claims{"hello": "world"})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err := req.Get(host)
+		assert.Nil(err)
+		assert.Equal(204, res.StatusCode)
+		assert.Equal(0, len(diverged))
+
+		token, _ = a.JWT().Sign(jwt.Claims{"hello": "world"})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(204, res.StatusCode)
+		assert.Equal(2, len(diverged))
+		assert.Nil(diverged[0])
+		assert.NotNil(diverged[1])
+	})
+	t.Run("should report near-expiry tokens", func(t *testing.T) {
+		assert := assert.New(t)
+
+		var remainings []time.Duration
+		a := New([]byte("my key"))
+		assert.Panics(func() {
+			a.SetNearExpiry(1, nil)
+		})
+		a.SetNearExpiry(0.2, func(ctx *gear.Context, claims jwt.Claims, remaining time.Duration) {
+			remainings = append(remainings, remaining)
+		})
+		app := gear.New()
+		app.UseHandler(a)
+		app.Use(func(ctx *gear.Context) error {
+			return ctx.End(204)
+		})
+		srv := app.Start()
+		defer srv.Close()
+
+		req := NewRequst()
+		host := "http://" + srv.Addr().String()
+
+		now := time.Now()
+		for _, iat := range []time.Time{now, now.Add(-50 * time.Minute)} {
+			claims := jwt.Claims{}
+			claims.SetIssuedAt(iat)
+			claims.SetExpiration(iat.Add(time.Hour))
+			token, _ := a.JWT().Sign(claims)
+			req.Headers["Authorization"] = "Bearer " + token
+			res, err := req.Get(host)
+			assert.Nil(err)
+			assert.Equal(204, res.StatusCode)
+		}
+
+		token, _ := a.JWT().Sign(jwt.Claims{})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err := req.Get(host)
+		assert.Nil(err)
+		assert.Equal(204, res.StatusCode)
+
+		assert.Equal(1, len(remainings))
+		assert.True(remainings[0] <= 10*time.Minute)
+	})
+
+	t.Run("should work with custom verifier", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := New()
+		assert.Panics(func() {
+			a.SetVerifier(nil)
+		})
+		a.SetVerifier(jwttest.NewStatic().
+			Add("alice", jwt.Claims{"sub": "alice"}).
+			AddError("expired", errors.New("token is expired")))
+		app := gear.New()
+		app.UseHandler(a)
+		app.Use(func(ctx *gear.Context) error {
+			claims, _ := a.FromCtx(ctx)
+			return ctx.HTML(200, claims.Get("sub").(string))
+		})
+		srv := app.Start()
+		defer srv.Close()
+
+		req := NewRequst()
+		host := "http://" + srv.Addr().String()
+
+		req.Headers["Authorization"] = "Bearer alice"
+		res, err := req.Get(host)
+		assert.Nil(err)
+		assert.Equal(200, res.StatusCode)
+		body, _ := res.Text()
+		assert.Equal("alice", body)
+
+		req.Headers["Authorization"] = "Bearer expired"
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(401, res.StatusCode)
+		body, _ = res.Text()
+		assert.Equal(`{"error":"Unauthorized","message":"token is expired"}`, body)
+	})
+
+	t.Run("should expose verified token in context", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := New([]byte("my key"))
+		app := gear.New()
+		app.UseHandler(a)
+		app.Use(func(ctx *gear.Context) error {
+			t, err := a.TokenFromCtx(ctx)
+			if err != nil {
+				return err
+			}
+			return ctx.JSON(200, map[string]interface{}{"raw": t.Raw, "alg": t.Header.Get("alg"), "sub": t.Claims.Get("sub")})
+		})
+		srv := app.Start()
+		defer srv.Close()
+
+		req := NewRequst()
+		host := "http://" + srv.Addr().String()
+
+		token, _ := a.JWT().Sign(jwt.Claims{"sub": "alice"})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err := req.Get(host)
+		assert.Nil(err)
+		assert.Equal(200, res.StatusCode)
+		body, _ := res.Text()
+		assert.Equal(`{"alg":"HS256","raw":"`+token+`","sub":"alice"}`, body)
+
+		req.Headers["Authorization"] = "Bearer " + token[1:]
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(401, res.StatusCode)
+		res.Body.Close()
+	})
+
+	t.Run("should respond when verification timed out", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := New()
+		assert.Panics(func() {
+			a.SetVerifyTimeout(0, nil)
+		})
+		a.SetVerifier(slowVerifier(100 * time.Millisecond))
+		a.SetVerifyTimeout(10*time.Millisecond, gear.ErrServiceUnavailable)
+		app := gear.New()
+		app.UseHandler(a)
+		app.Use(func(ctx *gear.Context) error {
+			return ctx.End(204)
+		})
+		srv := app.Start()
+		defer srv.Close()
+
+		req := NewRequst()
+		host := "http://" + srv.Addr().String()
+
+		req.Headers["Authorization"] = "Bearer alice"
+		res, err := req.Get(host)
+		assert.Nil(err)
+		assert.Equal(503, res.StatusCode)
+		body, _ := res.Text()
+		assert.Equal(`{"error":"ServiceUnavailable","message":"token verification timed out"}`, body)
+
+		a.SetVerifyTimeout(10*time.Millisecond, nil)
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(401, res.StatusCode)
+		res.Body.Close()
+
+		a.SetVerifyTimeout(time.Second, nil)
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(204, res.StatusCode)
+		res.Body.Close()
+	})
+
+	t.Run("should verify with request context", func(t *testing.T) {
+		assert := assert.New(t)
+
+		epochs := map[string]int64{"alice": 1}
+		lookups := 0
+		a := New([]byte("my key"))
+		a.JWT().AddContextValidator(authjwt.SessionEpochValidator("epoch", func(ctx context.Context, sub string) (int64, error) {
+			lookups++
+			return epochs[sub], nil
+		}))
+		app := gear.New()
+		app.UseHandler(a)
+		app.Use(func(ctx *gear.Context) error {
+			return ctx.End(204)
+		})
+		srv := app.Start()
+		defer srv.Close()
+
+		req := NewRequst()
+		host := "http://" + srv.Addr().String()
+
+		token, _ := a.JWT().Sign(jwt.Claims{"sub": "alice", "epoch": 1})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err := req.Get(host)
+		assert.Nil(err)
+		assert.Equal(204, res.StatusCode)
+		res.Body.Close()
+		assert.Equal(1, lookups)
+
+		epochs["alice"] = 2
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(401, res.StatusCode)
+		body, _ := res.Text()
+		assert.Contains(body, "session has been invalidated")
+		assert.Equal(2, lookups)
+	})
+
+	t.Run("should override validators per route", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := New([]byte("my key"))
+		assert.Panics(func() {
+			a.WithJWT(nil)
+		})
+		payments := a.WithJWT(a.JWT().WithValidator(&jwt.Validator{Expected: jwt.Claims{"aud": "payments"}}))
+		router := gear.NewRouter(gear.RouterOptions{Root: "/payments"})
+		router.Use(payments.Serve)
+		router.Get("", func(ctx *gear.Context) error {
+			claims, err := payments.FromCtx(ctx)
+			if err != nil {
+				return err
+			}
+			return ctx.End(200, []byte(claims.Get("sub").(string)))
+		})
+		app := gear.New()
+		app.UseHandler(a)
+		app.UseHandler(router)
+		app.Use(func(ctx *gear.Context) error {
+			return ctx.End(204)
+		})
+		srv := app.Start()
+		defer srv.Close()
+
+		req := NewRequst()
+		host := "http://" + srv.Addr().String()
+
+		token, _ := a.JWT().Sign(jwt.Claims{"sub": "alice", "aud": "orders"})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err := req.Get(host)
+		assert.Nil(err)
+		assert.Equal(204, res.StatusCode)
+		res.Body.Close()
+		res, err = req.Get(host + "/payments")
+		assert.Nil(err)
+		assert.Equal(401, res.StatusCode)
+		res.Body.Close()
+
+		token, _ = a.JWT().Sign(jwt.Claims{"sub": "alice", "aud": "payments"})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err = req.Get(host + "/payments")
+		assert.Nil(err)
+		assert.Equal(200, res.StatusCode)
+		body, _ := res.Text()
+		assert.Equal("alice", body)
+	})
+
+	t.Run("should give up verification when the client has gone away", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := New([]byte("my key"))
+		token, _ := a.JWT().Sign(jwt.Claims{"sub": "alice"})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := a.verify(ctx, token)
+		assert.Equal(context.Canceled, err)
+
+		a.SetVerifier(slowVerifier(time.Second))
+		a.SetVerifyTimeout(time.Minute, nil)
+		ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		_, err = a.verify(ctx, "alice")
+		assert.Equal(context.DeadlineExceeded, err)
+		assert.True(time.Since(start) < time.Second)
+	})
+}
+
+type slowVerifier time.Duration
+
+func (d slowVerifier) Verify(token string) (jwt.Claims, error) {
+	time.Sleep(time.Duration(d))
+	return jwt.Claims{"sub": token}, nil
 }
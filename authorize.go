@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"errors"
+
+	"github.com/teambition/gear"
+	"github.com/teambition/gear-auth/jwt"
+)
+
+// RequirePermissions returns a gear middleware that requires the permissions in claim covering
+// all the required permissions, see jwt.MatchPermission. Requests without a valid token are
+// rejected with 401, requests lacking permissions with 403.
+//
+//  router.Delete("/orders/:id", auther.RequirePermissions("permissions", "orders:delete"), deleteOrder)
+//
+func (a *Auth) RequirePermissions(claim string, required ...string) gear.Middleware {
+	if claim == "" || len(required) == 0 {
+		panic(errors.New("invalid required permissions"))
+	}
+	return func(ctx *gear.Context) error {
+		claims, err := a.FromCtx(ctx)
+		if err != nil {
+			return a.challenge(ctx, err)
+		}
+		if !jwt.HasPermissions(claims, claim, required...) {
+			return a.challenge(ctx, gear.ErrForbidden.WithMsg("insufficient permissions"))
+		}
+		return nil
+	}
+}
+
+// RequireScopes returns a gear middleware that requires the verified claims having all the scopes,
+// from the "scope" or "scp" claim, see jwt.HasScopes. Requests without a valid token are rejected
+// with 401, requests lacking scopes with 403.
+//
+//  router.Post("/orders", auther.RequireScopes("orders:read", "orders:write"), createOrder)
+//
+func (a *Auth) RequireScopes(scopes ...string) gear.Middleware {
+	if len(scopes) == 0 {
+		panic(errors.New("invalid required scopes"))
+	}
+	return func(ctx *gear.Context) error {
+		claims, err := a.FromCtx(ctx)
+		if err != nil {
+			return a.challenge(ctx, err)
+		}
+		if !jwt.HasScopes(claims, scopes...) {
+			return a.challenge(ctx, gear.ErrForbidden.WithMsg("insufficient scopes"), scopes...)
+		}
+		return nil
+	}
+}
+
+// SetRolesClaim set the claim of roles for RequireRoles, default to "roles". The claim can be an array
+// of strings or a space-delimited string. It panics if the claim is empty.
+//
+//  auther.SetRolesClaim("realm_roles")
+//
+func (a *Auth) SetRolesClaim(claim string) *Auth {
+	if claim == "" {
+		panic(errors.New("invalid roles claim"))
+	}
+	a.rolesClaim = claim
+	return a
+}
+
+// RequireRoles returns a gear middleware that requires the roles claim (see SetRolesClaim) containing
+// at least one of the roles. Requests without a valid token are rejected with 401, requests lacking
+// roles with 403.
+//
+//  router.Delete("/users/:id", auther.RequireRoles("admin", "owner"), deleteUser)
+//
+func (a *Auth) RequireRoles(roles ...string) gear.Middleware {
+	if len(roles) == 0 {
+		panic(errors.New("invalid required roles"))
+	}
+	return func(ctx *gear.Context) error {
+		claims, err := a.FromCtx(ctx)
+		if err != nil {
+			return a.challenge(ctx, err)
+		}
+		granted := jwt.Permissions(claims, a.rolesClaim)
+		for _, role := range roles {
+			for _, r := range granted {
+				if r == role {
+					return nil
+				}
+			}
+		}
+		return a.challenge(ctx, gear.ErrForbidden.WithMsg("insufficient roles"))
+	}
+}
@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/teambition/gear"
+)
+
+func TestAuthorize(t *testing.T) {
+	t.Run("RequirePermissions", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := New([]byte("my key"))
+		assert.Panics(func() {
+			a.RequirePermissions("permissions")
+		})
+		app := gear.New()
+		app.Use(a.RequirePermissions("permissions", "orders:delete"))
+		app.Use(func(ctx *gear.Context) error {
+			return ctx.End(204)
+		})
+		srv := app.Start()
+		defer srv.Close()
+
+		req := NewRequst()
+		host := "http://" + srv.Addr().String()
+
+		res, err := req.Get(host)
+		assert.Nil(err)
+		assert.Equal(401, res.StatusCode)
+		res.Body.Close()
+
+		token, _ := a.JWT().Sign(jwt.Claims{"permissions": []string{"orders:read"}})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(403, res.StatusCode)
+		body, _ := res.Text()
+		assert.Equal(`{"error":"Forbidden","message":"insufficient permissions"}`, body)
+
+		token, _ = a.JWT().Sign(jwt.Claims{"permissions": []string{"orders:*"}})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(204, res.StatusCode)
+		res.Body.Close()
+	})
+	t.Run("RequireScopes", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := New([]byte("my key"))
+		assert.Panics(func() {
+			a.RequireScopes()
+		})
+		app := gear.New()
+		app.Use(a.RequireScopes("orders:read", "orders:write"))
+		app.Use(func(ctx *gear.Context) error {
+			return ctx.End(204)
+		})
+		srv := app.Start()
+		defer srv.Close()
+
+		req := NewRequst()
+		host := "http://" + srv.Addr().String()
+
+		res, err := req.Get(host)
+		assert.Nil(err)
+		assert.Equal(401, res.StatusCode)
+		res.Body.Close()
+
+		token, _ := a.JWT().Sign(jwt.Claims{"scope": "orders:read"})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(403, res.StatusCode)
+		body, _ := res.Text()
+		assert.Equal(`{"error":"Forbidden","message":"insufficient scopes"}`, body)
+
+		token, _ = a.JWT().Sign(jwt.Claims{"scope": "orders:read orders:write"})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(204, res.StatusCode)
+		res.Body.Close()
+
+		token, _ = a.JWT().Sign(jwt.Claims{"scp": []string{"orders:write", "orders:read"}})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(204, res.StatusCode)
+		res.Body.Close()
+	})
+
+	t.Run("RequireRoles", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := New([]byte("my key"))
+		assert.Panics(func() {
+			a.RequireRoles()
+		})
+		assert.Panics(func() {
+			a.SetRolesClaim("")
+		})
+		app := gear.New()
+		app.Use(a.RequireRoles("admin", "owner"))
+		app.Use(func(ctx *gear.Context) error {
+			return ctx.End(204)
+		})
+		srv := app.Start()
+		defer srv.Close()
+
+		req := NewRequst()
+		host := "http://" + srv.Addr().String()
+
+		res, err := req.Get(host)
+		assert.Nil(err)
+		assert.Equal(401, res.StatusCode)
+		res.Body.Close()
+
+		token, _ := a.JWT().Sign(jwt.Claims{"roles": []string{"member"}})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(403, res.StatusCode)
+		body, _ := res.Text()
+		assert.Equal(`{"error":"Forbidden","message":"insufficient roles"}`, body)
+
+		token, _ = a.JWT().Sign(jwt.Claims{"roles": []string{"member", "owner"}})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(204, res.StatusCode)
+		res.Body.Close()
+
+		token, _ = a.JWT().Sign(jwt.Claims{"roles": "admin"})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(204, res.StatusCode)
+		res.Body.Close()
+
+		a.SetRolesClaim("groups")
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(403, res.StatusCode)
+		res.Body.Close()
+
+		token, _ = a.JWT().Sign(jwt.Claims{"groups": "admin"})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(204, res.StatusCode)
+		res.Body.Close()
+	})
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"errors"
+
+	"github.com/teambition/gear"
+	"github.com/teambition/gear-auth/jwt"
+)
+
+// Enforcer is the enforcement method of a Casbin enforcer, *casbin.Enforcer (v2) implements it.
+type Enforcer interface {
+	Enforce(rvals ...interface{}) (bool, error)
+}
+
+// RequireCasbin returns a gear middleware that authorizes the request by the Casbin enforcer with
+// the verified claims. The request is enforced as (subject, path, method), or as
+// (subject, tenant, path, method) with the domain model if tenantClaim is not empty. The subject is
+// the "sub" claim, and then each role in rolesClaim (if not empty), the request is allowed if any
+// of them is allowed. Requests without a valid token are rejected with 401, denied requests with 403,
+// and requests are rejected with 500 if the enforcer fails.
+//
+//  e, _ := casbin.NewEnforcer("model.conf", "policy.csv")
+//  app.Use(auther.RequireCasbin(e, "roles", "tenant"))
+//
+func (a *Auth) RequireCasbin(enforcer Enforcer, rolesClaim, tenantClaim string) gear.Middleware {
+	if enforcer == nil {
+		panic(errors.New("invalid casbin enforcer"))
+	}
+	return func(ctx *gear.Context) error {
+		claims, err := a.FromCtx(ctx)
+		if err != nil {
+			return a.challenge(ctx, err)
+		}
+
+		var subjects []string
+		if sub, _ := claims.Subject(); sub != "" {
+			subjects = append(subjects, sub)
+		}
+		if rolesClaim != "" {
+			subjects = append(subjects, jwt.Permissions(claims, rolesClaim)...)
+		}
+		var tenant string
+		if tenantClaim != "" {
+			if tenant, _ = claims.Get(tenantClaim).(string); tenant == "" {
+				return a.challenge(ctx, gear.ErrForbidden.WithMsg("denied by casbin"))
+			}
+		}
+
+		for _, subject := range subjects {
+			rvals := []interface{}{subject, ctx.Path, ctx.Method}
+			if tenantClaim != "" {
+				rvals = []interface{}{subject, tenant, ctx.Path, ctx.Method}
+			}
+			ok, err := enforcer.Enforce(rvals...)
+			if err != nil {
+				return gear.ErrInternalServerError.WithMsg("casbin enforcement failed")
+			}
+			if ok {
+				return nil
+			}
+		}
+		return a.challenge(ctx, gear.ErrForbidden.WithMsg("denied by casbin"))
+	}
+}
@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/teambition/gear"
+)
+
+type policyEnforcer struct {
+	policies map[string]bool
+	requests []string
+}
+
+func (e *policyEnforcer) Enforce(rvals ...interface{}) (bool, error) {
+	req := fmt.Sprintf("%v", rvals)
+	e.requests = append(e.requests, req)
+	if rvals[0] == "broken" {
+		return false, errors.New("invalid request size")
+	}
+	return e.policies[req], nil
+}
+
+func TestCasbin(t *testing.T) {
+	t.Run("RequireCasbin", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := New([]byte("my key"))
+		assert.Panics(func() {
+			a.RequireCasbin(nil, "roles", "")
+		})
+		enforcer := &policyEnforcer{policies: map[string]bool{
+			"[editor acme /articles GET]": true,
+		}}
+		app := gear.New()
+		app.Use(a.RequireCasbin(enforcer, "roles", "tenant"))
+		app.Use(func(ctx *gear.Context) error {
+			return ctx.End(204)
+		})
+		srv := app.Start()
+		defer srv.Close()
+
+		req := NewRequst()
+		host := "http://" + srv.Addr().String() + "/articles"
+
+		res, err := req.Get(host)
+		assert.Nil(err)
+		assert.Equal(401, res.StatusCode)
+		assert.Equal("Bearer", res.Header.Get("WWW-Authenticate"))
+		res.Body.Close()
+
+		token, _ := a.JWT().Sign(jwt.Claims{"sub": "alice", "roles": []string{"viewer", "editor"}, "tenant": "acme"})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(204, res.StatusCode)
+		res.Body.Close()
+		assert.Equal([]string{
+			"[alice acme /articles GET]",
+			"[viewer acme /articles GET]",
+			"[editor acme /articles GET]",
+		}, enforcer.requests)
+
+		token, _ = a.JWT().Sign(jwt.Claims{"sub": "alice", "roles": "editor", "tenant": "other"})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(403, res.StatusCode)
+		assert.Equal(`Bearer error="insufficient_scope", error_description="denied by casbin"`,
+			res.Header.Get("WWW-Authenticate"))
+		body, _ := res.Text()
+		assert.Equal(`{"error":"Forbidden","message":"denied by casbin"}`, body)
+
+		token, _ = a.JWT().Sign(jwt.Claims{"sub": "alice", "roles": "editor"})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(403, res.StatusCode)
+		res.Body.Close()
+
+		token, _ = a.JWT().Sign(jwt.Claims{"sub": "broken", "tenant": "acme"})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(500, res.StatusCode)
+		res.Body.Close()
+	})
+
+	t.Run("RequireCasbin without tenant", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := New([]byte("my key"))
+		enforcer := &policyEnforcer{policies: map[string]bool{
+			"[alice / GET]": true,
+		}}
+		app := gear.New()
+		app.Use(a.RequireCasbin(enforcer, "", ""))
+		app.Use(func(ctx *gear.Context) error {
+			return ctx.End(204)
+		})
+		srv := app.Start()
+		defer srv.Close()
+
+		req := NewRequst()
+		host := "http://" + srv.Addr().String()
+
+		token, _ := a.JWT().Sign(jwt.Claims{"sub": "alice", "roles": "admin"})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err := req.Get(host)
+		assert.Nil(err)
+		assert.Equal(204, res.StatusCode)
+		res.Body.Close()
+
+		token, _ = a.JWT().Sign(jwt.Claims{"sub": "bob", "roles": "admin"})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(403, res.StatusCode)
+		res.Body.Close()
+	})
+}
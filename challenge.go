@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/teambition/gear"
+)
+
+// SetRealm set the realm of the WWW-Authenticate header, which is responded when the request is
+// rejected with 401 or 403 (RFC 6750, Section 3):
+//
+//  WWW-Authenticate: Bearer realm="example", error="invalid_token", error_description="token is expired"
+//
+// Requests without token have no error code, malformed Authorization headers have "invalid_request",
+// invalid tokens have "invalid_token", and forbidden requests have "insufficient_scope".
+// It panics if the realm contains '"' or '\'.
+func (a *Auth) SetRealm(realm string) *Auth {
+	if strings.ContainsAny(realm, "\"\\") {
+		panic(errors.New("invalid realm"))
+	}
+	a.realm = realm
+	return a
+}
+
+// SetErrorHandler set a handler to respond the errors of auth, such as rendering a custom JSON body,
+// localizing messages or redirecting to the login page. The error can be checked by errors.Is and
+// errors.As, see ErrNoToken and jwt.Error. The WWW-Authenticate header is set before calling it for
+// 401 and 403 errors. A 401 or 403 *gear.Error returned by it is responded as the default responses,
+// with the WWW-Authenticate header, other errors go on to gear. Set nil to restore the default responses.
+//
+//  auther.SetErrorHandler(func(ctx *gear.Context, err error) error {
+//  	if errors.Is(err, auth.ErrNoToken) {
+//  		return ctx.Redirect("/login?next=" + url.QueryEscape(ctx.Req.URL.String()))
+//  	}
+//  	return ctx.JSON(401, map[string]string{"code": "unauthorized", "reason": err.Error()})
+//  })
+//
+func (a *Auth) SetErrorHandler(fn func(ctx *gear.Context, err error) error) *Auth {
+	a.errorHandler = fn
+	return a
+}
+
+// challenge responds the error of auth by the error handler, or as gear does. The WWW-Authenticate header
+// is set for 401 and 403 errors, scopes are the scopes required by the insufficient_scope error.
+func (a *Auth) challenge(ctx *gear.Context, err error, scopes ...string) error {
+	var e *gear.Error
+	if a.errorHandler != nil {
+		if errors.As(err, &e) && (e.Code == 401 || e.Code == 403) {
+			ctx.SetHeader(gear.HeaderWWWAuthenticate, a.authenticate(e, scopes))
+		}
+		if err = a.errorHandler(ctx, err); err == nil {
+			return nil
+		}
+	}
+	if !errors.As(err, &e) || (e.Code != 401 && e.Code != 403) {
+		return err
+	}
+	// gear resets the headers when responding errors, so the error is responded here as gear does.
+	ctx.SetHeader(gear.HeaderWWWAuthenticate, a.authenticate(e, scopes))
+	ctx.SetHeader(gear.HeaderXContentTypeOptions, "nosniff")
+	return ctx.JSON(e.Code, e)
+}
+
+// authenticate returns the WWW-Authenticate header value of the 401 or 403 error.
+func (a *Auth) authenticate(e *gear.Error, scopes []string) string {
+	var code, scope string
+	switch {
+	case e.Code == 403:
+		code, scope = "insufficient_scope", strings.Join(scopes, " ")
+	case e.Msg == ErrInvalidBearer.Error():
+		code = "invalid_request"
+	case e.Msg != ErrNoToken.Error():
+		code = "invalid_token"
+	}
+
+	var params []string
+	if a.realm != "" {
+		params = append(params, `realm="`+a.realm+`"`)
+	}
+	if code != "" {
+		params = append(params, `error="`+code+`"`, `error_description="`+challengeValue(e.Msg)+`"`)
+	}
+	if scope != "" {
+		params = append(params, `scope="`+challengeValue(scope)+`"`)
+	}
+	if len(params) == 0 {
+		return "Bearer"
+	}
+	return "Bearer " + strings.Join(params, ", ")
+}
+
+// challengeValue removes characters not allowed in the quoted values of WWW-Authenticate.
+func challengeValue(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			return -1
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/teambition/gear"
+)
+
+func TestChallenge(t *testing.T) {
+	t.Run("should respond WWW-Authenticate header", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := New([]byte("my key"))
+		assert.Panics(func() {
+			a.SetRealm(`my "realm"`)
+		})
+		a.SetRealm("example")
+		a.SetAuthorizer(func(ctx *gear.Context, claims jwt.Claims) error {
+			if claims.Get("sub") == "mallory" {
+				return gear.ErrForbidden.WithMsg("mallory is banned")
+			}
+			return nil
+		})
+		app := gear.New()
+		app.UseHandler(a)
+		app.Use(a.RequireScopes("orders:read", "orders:write"))
+		app.Use(func(ctx *gear.Context) error {
+			return ctx.End(204)
+		})
+		srv := app.Start()
+		defer srv.Close()
+
+		req := NewRequst()
+		host := "http://" + srv.Addr().String()
+
+		res, err := req.Get(host)
+		assert.Nil(err)
+		assert.Equal(401, res.StatusCode)
+		assert.Equal(`Bearer realm="example"`, res.Header.Get("WWW-Authenticate"))
+		res.Body.Close()
+
+		req.Headers["Authorization"] = "Bearer a b"
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(`Bearer realm="example", error="invalid_request", error_description="invalid bearer authorization header"`,
+			res.Header.Get("WWW-Authenticate"))
+		res.Body.Close()
+
+		token, _ := New([]byte("wrong key")).JWT().Sign(jwt.Claims{"sub": "alice"})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(`Bearer realm="example", error="invalid_token", error_description="signature is invalid"`,
+			res.Header.Get("WWW-Authenticate"))
+		res.Body.Close()
+
+		token, _ = a.JWT().Sign(jwt.Claims{"sub": "mallory"})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(403, res.StatusCode)
+		assert.Equal(`Bearer realm="example", error="insufficient_scope", error_description="mallory is banned"`,
+			res.Header.Get("WWW-Authenticate"))
+		res.Body.Close()
+
+		token, _ = a.JWT().Sign(jwt.Claims{"sub": "alice", "scope": "orders:read"})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(403, res.StatusCode)
+		assert.Equal(`Bearer realm="example", error="insufficient_scope", error_description="insufficient scopes", scope="orders:read orders:write"`,
+			res.Header.Get("WWW-Authenticate"))
+		res.Body.Close()
+
+		token, _ = a.JWT().Sign(jwt.Claims{"sub": "alice", "scope": "orders:read orders:write"})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(204, res.StatusCode)
+		assert.Equal("", res.Header.Get("WWW-Authenticate"))
+		res.Body.Close()
+	})
+
+	t.Run("should respond WWW-Authenticate header without realm", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := New([]byte("my key"))
+		app := gear.New()
+		app.UseHandler(a)
+		srv := app.Start()
+		defer srv.Close()
+
+		req := NewRequst()
+		host := "http://" + srv.Addr().String()
+
+		res, err := req.Get(host)
+		assert.Nil(err)
+		assert.Equal("Bearer", res.Header.Get("WWW-Authenticate"))
+		res.Body.Close()
+
+		req.Headers["Authorization"] = "Bearer invalid"
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Contains(res.Header.Get("WWW-Authenticate"), `Bearer error="invalid_token", error_description="`)
+		res.Body.Close()
+	})
+	t.Run("should respond errors by the error handler", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := New([]byte("my key"))
+		a.SetErrorHandler(func(ctx *gear.Context, err error) error {
+			if errors.Is(err, ErrNoToken) {
+				return ctx.Redirect("/login")
+			}
+			var e *gear.Error
+			if errors.As(err, &e) && e.Code == 403 {
+				return err
+			}
+			return ctx.JSON(401, map[string]string{"code": "unauthorized", "reason": err.Error()})
+		})
+		app := gear.New()
+		app.UseHandler(a)
+		app.Use(a.RequireRoles("admin"))
+		app.Use(func(ctx *gear.Context) error {
+			return ctx.End(204)
+		})
+		srv := app.Start()
+		defer srv.Close()
+
+		req := NewRequst()
+		req.Client.CheckRedirect = func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+		host := "http://" + srv.Addr().String()
+
+		res, err := req.Get(host)
+		assert.Nil(err)
+		assert.Equal(302, res.StatusCode)
+		assert.Equal("/login", res.Header.Get("Location"))
+		res.Body.Close()
+
+		req.Headers["Authorization"] = "Bearer invalid"
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(401, res.StatusCode)
+		assert.Contains(res.Header.Get("WWW-Authenticate"), `error="invalid_token"`)
+		body, _ := res.Text()
+		assert.Contains(body, `"code":"unauthorized"`)
+
+		token, _ := a.JWT().Sign(jwt.Claims{"roles": "member"})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(403, res.StatusCode)
+		assert.Equal(`Bearer error="insufficient_scope", error_description="insufficient roles"`,
+			res.Header.Get("WWW-Authenticate"))
+		body, _ = res.Text()
+		assert.Equal(`{"error":"Forbidden","message":"insufficient roles"}`, body)
+
+		a.SetErrorHandler(nil)
+		req.Headers["Authorization"] = "Bearer invalid"
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(401, res.StatusCode)
+		body, _ = res.Text()
+		assert.Contains(body, `"error":"Unauthorized"`)
+	})
+}
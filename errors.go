@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/teambition/gear"
+)
+
+// ErrNoToken is the cause of the 401 error when no token found in the request.
+var ErrNoToken = errors.New("no token found")
+
+// authFailure is the error of auth, it is responded as the *gear.Error,
+// and keeps the cause for errors.Is and errors.As:
+//
+//  _, err := auther.FromCtx(ctx)
+//  if errors.Is(err, jwt.ErrTokenExpired) {
+//  	// trigger the refresh flow
+//  }
+//
+type authFailure struct {
+	err   *gear.Error
+	cause error
+}
+
+// failure returns an authFailure of the cause, the cause is responded as base unless it is a *gear.Error.
+func failure(base *gear.Error, cause error) error {
+	switch v := cause.(type) {
+	case *authFailure:
+		return v
+	case *gear.Error:
+		return &authFailure{err: v, cause: v}
+	}
+	return &authFailure{err: base.From(cause), cause: cause}
+}
+
+// Error implements the error interface.
+func (e *authFailure) Error() string {
+	return e.err.Error()
+}
+
+// Status implements the gear.HTTPError interface.
+func (e *authFailure) Status() int {
+	return e.err.Code
+}
+
+// MarshalJSON implements the json.Marshaler interface, gear responds errors as JSON.
+func (e *authFailure) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.err)
+}
+
+// Unwrap returns the cause.
+func (e *authFailure) Unwrap() error {
+	return e.cause
+}
+
+// As sets target to the responded *gear.Error, so that errors.As works with *gear.Error.
+func (e *authFailure) As(target interface{}) bool {
+	if t, ok := target.(**gear.Error); ok {
+		*t = e.err
+		return true
+	}
+	return false
+}
@@ -14,7 +14,7 @@ func NewRequst() *request.Request {
 	return request.NewRequest(c)
 }
 
-func ExampleGearAuth() {
+func Example() {
 	auther := auth.New([]byte("key_new"), []byte("key_old"))
 	auther.JWT().SetIssuer("Gear")
 	// auther.JWT().SetExpiration(time.Hour * 24)
@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/teambition/gear"
+)
+
+// FromCookie returns a TokenExtractor that extracts the token from the cookie, such as
+// an HttpOnly cookie set by browser SPAs' login.
+//
+//  auther.SetTokenParser(auth.FromCookie("session"))
+//
+func FromCookie(name string) TokenExtractor {
+	return func(ctx *gear.Context) string {
+		token, _ := ctx.Cookies.Get(name)
+		return token
+	}
+}
+
+// FromQuery returns a TokenExtractor that extracts the token from the query parameter.
+//
+//  auther.SetTokenParser(auth.FromQuery("token"))
+//
+func FromQuery(name string) TokenExtractor {
+	return func(ctx *gear.Context) string {
+		return ctx.Query(name)
+	}
+}
+
+// FromHeader returns a TokenExtractor that extracts the token from the header without any scheme,
+// such as "X-Access-Token".
+//
+//  auther.SetTokenParser(auth.FromHeader("X-Access-Token"))
+//
+func FromHeader(name string) TokenExtractor {
+	return func(ctx *gear.Context) string {
+		return ctx.GetHeader(name)
+	}
+}
+
+// ExtractorChain returns a TokenExtractor that tries the extractors in order,
+// and returns the first non-empty token.
+//
+//  ex := auth.ExtractorChain(auth.FromHeader("X-Access-Token"), auth.FromCookie("session"), auth.FromQuery("token"))
+//
+func ExtractorChain(extractors ...TokenExtractor) TokenExtractor {
+	if len(extractors) == 0 {
+		panic(errors.New("invalid token extractors"))
+	}
+	return func(ctx *gear.Context) string {
+		for _, ex := range extractors {
+			if token := ex(ctx); token != "" {
+				return token
+			}
+		}
+		return ""
+	}
+}
+
+// SetTokenExtractors set the extractors to auth, the token is extracted from them in order as ExtractorChain.
+// The security schemes of the default extractor are removed as SetTokenParser.
+//
+//  auther.SetTokenExtractors(auth.FromCookie("session"), auth.FromQuery("token"))
+//
+func (a *Auth) SetTokenExtractors(extractors ...TokenExtractor) *Auth {
+	a.SetTokenParser(ExtractorChain(extractors...))
+	return a
+}
+
+// ErrInvalidBearer is returned by ParseBearer when the Authorization header is a malformed Bearer credential.
+var ErrInvalidBearer = errors.New("invalid bearer authorization header")
+
+// b64token is the b64token syntax of RFC 6750, Section 2.1.
+var b64token = regexp.MustCompile(`^[A-Za-z0-9\-._~+/]+=*$`)
+
+// ParseBearer parses the token from the Authorization header value with the Bearer scheme (RFC 6750).
+// The scheme is case-insensitive and may be followed by any whitespace. An empty string and nil
+// are returned if the header is empty or with another scheme, such as "Basic",
+// and ErrInvalidBearer is returned if the Bearer credential is malformed.
+//
+//  token, err := auth.ParseBearer("bearer   eyJhbGciOiJIUzI1NiIs...")
+//
+func ParseBearer(header string) (string, error) {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return "", nil
+	}
+	scheme, token := header, ""
+	if i := strings.IndexAny(header, " \t"); i >= 0 {
+		scheme, token = header[:i], strings.TrimSpace(header[i:])
+	}
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", nil
+	}
+	if !b64token.MatchString(token) {
+		return "", ErrInvalidBearer
+	}
+	return token, nil
+}
+
+// bearerError is the key to cache the ParseBearer error on gear.Context,
+// Auth reports it instead of "no token found".
+type bearerError struct{}
+
+// BearerExtractor is a TokenExtractor that extracts the token from the Authorization header by ParseBearer.
+// If the header is malformed, requests are rejected with 401 and ErrInvalidBearer as the message,
+// unless other extractors in the chain find a token.
+//
+//  auther.SetTokenExtractors(auth.BearerExtractor, auth.FromCookie("session"))
+//
+func BearerExtractor(ctx *gear.Context) string {
+	token, err := ParseBearer(ctx.GetHeader("Authorization"))
+	if err != nil {
+		ctx.SetAny(bearerError{}, err)
+	}
+	return token
+}
@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/teambition/gear"
+)
+
+func TestExtractors(t *testing.T) {
+	t.Run("should extract token from cookie, query and header", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := New([]byte("my key"))
+		token, _ := a.JWT().Sign(jwt.Claims{"sub": "alice"})
+		app := gear.New()
+		app.Use(func(ctx *gear.Context) error {
+			var ex TokenExtractor
+			switch ctx.Path {
+			case "/cookie":
+				ex = FromCookie("session")
+			case "/query":
+				ex = FromQuery("token")
+			default:
+				ex = FromHeader("X-Access-Token")
+			}
+			return ctx.End(200, []byte(ex(ctx)))
+		})
+		srv := app.Start()
+		defer srv.Close()
+
+		req := NewRequst()
+		host := "http://" + srv.Addr().String()
+
+		res, err := req.Get(host + "/cookie")
+		assert.Nil(err)
+		body, _ := res.Text()
+		assert.Equal("", body)
+
+		req.Cookies = map[string]string{"session": token}
+		res, err = req.Get(host + "/cookie")
+		assert.Nil(err)
+		body, _ = res.Text()
+		assert.Equal(token, body)
+
+		res, err = req.Get(host + "/query?token=" + token)
+		assert.Nil(err)
+		body, _ = res.Text()
+		assert.Equal(token, body)
+
+		req.Headers = map[string]string{"X-Access-Token": token}
+		res, err = req.Get(host + "/header")
+		assert.Nil(err)
+		body, _ = res.Text()
+		assert.Equal(token, body)
+	})
+
+	t.Run("should authenticate with cookie", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := New([]byte("my key"))
+		a.SetTokenParser(FromCookie("session"))
+		app := gear.New()
+		app.UseHandler(a)
+		app.Use(func(ctx *gear.Context) error {
+			claims, _ := a.FromCtx(ctx)
+			return ctx.End(200, []byte(claims.Get("sub").(string)))
+		})
+		srv := app.Start()
+		defer srv.Close()
+
+		req := NewRequst()
+		host := "http://" + srv.Addr().String()
+
+		token, _ := a.JWT().Sign(jwt.Claims{"sub": "alice"})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err := req.Get(host)
+		assert.Nil(err)
+		assert.Equal(http.StatusUnauthorized, res.StatusCode)
+		res.Body.Close()
+
+		req.Cookies = map[string]string{"session": token}
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(200, res.StatusCode)
+		body, _ := res.Text()
+		assert.Equal("alice", body)
+	})
+	t.Run("should try extractors in order", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.Panics(func() {
+			ExtractorChain()
+		})
+
+		a := New([]byte("my key"))
+		a.SetTokenExtractors(FromHeader("X-Access-Token"), FromCookie("session"), FromQuery("token"))
+		app := gear.New()
+		app.UseHandler(a)
+		app.Use(func(ctx *gear.Context) error {
+			claims, _ := a.FromCtx(ctx)
+			return ctx.End(200, []byte(claims.Get("sub").(string)))
+		})
+		srv := app.Start()
+		defer srv.Close()
+
+		req := NewRequst()
+		host := "http://" + srv.Addr().String()
+
+		alice, _ := a.JWT().Sign(jwt.Claims{"sub": "alice"})
+		bob, _ := a.JWT().Sign(jwt.Claims{"sub": "bob"})
+		res, err := req.Get(host)
+		assert.Nil(err)
+		assert.Equal(http.StatusUnauthorized, res.StatusCode)
+		res.Body.Close()
+
+		res, err = req.Get(host + "?token=" + alice)
+		assert.Nil(err)
+		body, _ := res.Text()
+		assert.Equal("alice", body)
+
+		req.Cookies = map[string]string{"session": bob}
+		res, err = req.Get(host + "?token=" + alice)
+		assert.Nil(err)
+		body, _ = res.Text()
+		assert.Equal("bob", body)
+
+		req.Headers["X-Access-Token"] = alice
+		res, err = req.Get(host)
+		assert.Nil(err)
+		body, _ = res.Text()
+		assert.Equal("alice", body)
+	})
+	t.Run("ParseBearer", func(t *testing.T) {
+		assert := assert.New(t)
+
+		for header, token := range map[string]string{
+			"":                       "",
+			"Basic YWxpY2U6c2VjcmV0": "",
+			"Bearer abc.DEF-_~+/==":  "abc.DEF-_~+/==",
+			"bearer abc":             "abc",
+			"BEARER abc":             "abc",
+			"  Bearer \t  abc  ":     "abc",
+		} {
+			res, err := ParseBearer(header)
+			assert.Nil(err, header)
+			assert.Equal(token, res, header)
+		}
+		for _, header := range []string{"Bearer", "Bearer ", "Bearer abc def", "Bearer ab=c", "Bearer =abc", "Bearer a,b"} {
+			_, err := ParseBearer(header)
+			assert.Equal(ErrInvalidBearer, err, header)
+		}
+	})
+
+	t.Run("should reject malformed bearer header", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := New([]byte("my key"))
+		app := gear.New()
+		app.UseHandler(a)
+		app.Use(func(ctx *gear.Context) error {
+			claims, _ := a.FromCtx(ctx)
+			return ctx.End(200, []byte(claims.Get("sub").(string)))
+		})
+		srv := app.Start()
+		defer srv.Close()
+
+		req := NewRequst()
+		host := "http://" + srv.Addr().String()
+
+		token, _ := a.JWT().Sign(jwt.Claims{"sub": "alice"})
+		req.Headers["Authorization"] = "bearer  " + token
+		res, err := req.Get(host)
+		assert.Nil(err)
+		body, _ := res.Text()
+		assert.Equal("alice", body)
+
+		req.Headers["Authorization"] = "Bearer " + token + " extra"
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(http.StatusUnauthorized, res.StatusCode)
+		body, _ = res.Text()
+		assert.Equal(`{"error":"Unauthorized","message":"invalid bearer authorization header"}`, body)
+
+		// the query parameter is still accepted
+		res, err = req.Get(host + "?access_token=" + token)
+		assert.Nil(err)
+		body, _ = res.Text()
+		assert.Equal("alice", body)
+
+		req.Headers["Authorization"] = "Basic YWxpY2U6c2VjcmV0"
+		res, err = req.Get(host)
+		assert.Nil(err)
+		body, _ = res.Text()
+		assert.Equal(`{"error":"Unauthorized","message":"no token found"}`, body)
+	})
+}
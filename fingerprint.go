@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/go-http-utils/cookie"
+	"github.com/teambition/gear"
+	"github.com/teambition/gear-auth/jwt"
+)
+
+// FingerprintClaim is the claim name holding the SHA-256 hash of the fingerprint.
+const FingerprintClaim = "fgp"
+
+// SetFingerprint enables token binding to a client fingerprint. Tokens signed by SignWithFingerprint
+// carry the hash of a random fingerprint, the fingerprint itself is set as a HttpOnly cookie with the
+// cookieName. The middleware will recompute the hash from the cookie and compare it with the claim,
+// so stolen tokens without the cookie are useless.
+// If options omit, the cookie will be set with Path "/", HttpOnly and Secure.
+//
+//  auther.SetFingerprint("__Host-fgp")
+//  // in login handler
+//  token, err := auther.SignWithFingerprint(ctx, claims)
+//
+func (a *Auth) SetFingerprint(cookieName string, options ...*cookie.Options) *Auth {
+	if cookieName == "" {
+		panic(errors.New("invalid fingerprint cookie name"))
+	}
+	a.fingerprint = cookieName
+	a.fingerprintOpts = &cookie.Options{Path: "/", HTTPOnly: true, Secure: true}
+	if len(options) > 0 && options[0] != nil {
+		a.fingerprintOpts = options[0]
+	}
+	return a
+}
+
+// SignWithFingerprint creates a token as JWT().Sign, with a new random fingerprint bound to it.
+// The fingerprint will be set to response as cookie. It should be used with SetFingerprint.
+func (a *Auth) SignWithFingerprint(ctx *gear.Context, content interface{}, expiresIn ...time.Duration) (string, error) {
+	if a.fingerprint == "" {
+		return "", errors.New("fingerprint is not enabled")
+	}
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	fingerprint := base64.RawURLEncoding.EncodeToString(buf)
+
+	claims, err := jwt.ToClaims(content)
+	if err != nil {
+		return "", err
+	}
+	claims = copyClaims(claims)
+	claims.Set(FingerprintClaim, hashFingerprint(fingerprint))
+	token, err := a.j.Sign(claims, expiresIn...)
+	if err != nil {
+		return "", err
+	}
+	ctx.Cookies.Set(a.fingerprint, fingerprint, a.fingerprintOpts)
+	return token, nil
+}
+
+func (a *Auth) checkFingerprint(ctx *gear.Context, claims josejwt.Claims) error {
+	hash, _ := claims.Get(FingerprintClaim).(string)
+	fingerprint, _ := ctx.Cookies.Get(a.fingerprint)
+	if hash == "" || fingerprint == "" ||
+		subtle.ConstantTimeCompare([]byte(hash), []byte(hashFingerprint(fingerprint))) != 1 {
+		return gear.ErrUnauthorized.WithMsg("invalid token fingerprint")
+	}
+	return nil
+}
+
+func hashFingerprint(fingerprint string) string {
+	sum := sha256.Sum256([]byte(fingerprint))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/teambition/gear"
+)
+
+func TestFingerprint(t *testing.T) {
+	t.Run("should bind token to fingerprint cookie", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := New([]byte("my key"))
+		assert.Panics(func() {
+			a.SetFingerprint("")
+		})
+		a.SetFingerprint("fgp")
+
+		app := gear.New()
+		app.Use(func(ctx *gear.Context) error {
+			if ctx.Path == "/login" {
+				token, err := a.SignWithFingerprint(ctx, jwt.Claims{"hello": "world"})
+				if err != nil {
+					return err
+				}
+				return ctx.End(200, []byte(token))
+			}
+			return nil
+		})
+		app.UseHandler(a)
+		app.Use(func(ctx *gear.Context) error {
+			claims, _ := a.FromCtx(ctx)
+			return ctx.JSON(200, claims)
+		})
+		srv := app.Start()
+		defer srv.Close()
+
+		host := "http://" + srv.Addr().String()
+		req := NewRequst()
+		res, err := req.Get(host + "/login")
+		assert.Nil(err)
+		assert.Equal(200, res.StatusCode)
+		token, _ := res.Text()
+		setCookie := res.Header.Get("Set-Cookie")
+		assert.True(strings.Contains(setCookie, "HttpOnly"))
+		assert.True(strings.Contains(setCookie, "Secure"))
+		cookie := strings.Split(setCookie, ";")[0]
+
+		claims, _ := a.JWT().Verify(token)
+		assert.Equal(64, len(claims.Get(FingerprintClaim).(string)))
+
+		req = NewRequst()
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(401, res.StatusCode)
+		body, _ := res.Text()
+		assert.Equal(`{"error":"Unauthorized","message":"invalid token fingerprint"}`, body)
+
+		req.Headers["Cookie"] = "fgp=xxx"
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(401, res.StatusCode)
+
+		req.Headers["Cookie"] = cookie
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(200, res.StatusCode)
+		body, _ = res.Text()
+		assert.True(strings.Contains(body, `"hello":"world"`))
+
+		_, err = New([]byte("my key")).SignWithFingerprint(nil, jwt.Claims{})
+		assert.NotNil(err)
+	})
+}
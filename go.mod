@@ -2,12 +2,17 @@ module github.com/teambition/gear-auth
 
 require (
 	github.com/SermoDigital/jose v0.0.0-20180104203859-803625baeddc
+	github.com/alicebob/gopher-json v0.0.0-20230218143504-906a9b012302 // indirect
+	github.com/alicebob/miniredis v2.5.0+incompatible
 	github.com/bitly/go-simplejson v0.5.0 // indirect
 	github.com/bmizerany/assert v0.0.0-20160611221934-b7ed37b82869 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/dimfeld/httptreemux v5.0.1+incompatible // indirect
-	github.com/go-http-utils/cookie v1.3.1 // indirect
+	github.com/go-http-utils/cookie v1.3.1
 	github.com/go-http-utils/negotiator v1.0.0 // indirect
+	github.com/go-redis/redis v6.15.0+incompatible
+	github.com/golang/protobuf v1.2.0
+	github.com/gomodule/redigo v2.0.0+incompatible // indirect
 	github.com/julienschmidt/httprouter v1.2.0 // indirect
 	github.com/kr/pretty v0.1.0 // indirect
 	github.com/mozillazg/request v0.8.0
@@ -15,9 +20,14 @@ require (
 	github.com/stretchr/testify v1.2.2
 	github.com/teambition/gear v1.12.2
 	github.com/teambition/trie-mux v1.4.2 // indirect
+	github.com/yuin/gopher-lua v0.1.0 // indirect
+	go.etcd.io/bbolt v1.3.4
 	golang.org/x/crypto v0.0.0-20181127143415-eb0de9b17e85
 	golang.org/x/net v0.0.0-20181201002055-351d144fa1fc // indirect
+	golang.org/x/oauth2 v0.0.0-20181203162652-d668ce993890
 	golang.org/x/text v0.3.0 // indirect
+	google.golang.org/genproto v0.0.0-20180817151627-c66870c02cf8 // indirect
+	google.golang.org/grpc v1.17.0
 	gopkg.in/check.v1 v1.0.0-20180628173108-788fd7840127 // indirect
 	gopkg.in/mgo.v2 v2.0.0-20180705113604-9856a29383ce // indirect
 	gopkg.in/yaml.v2 v2.2.2 // indirect
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/teambition/gear"
+)
+
+// AddHealthCheck adds a named health check to the HealthHandler, such as
+// a remote JWKS, introspection endpoint or revocation store that auth depends on.
+func (a *Auth) AddHealthCheck(name string, check func() error) *Auth {
+	if name == "" || check == nil {
+		panic(errors.New("invalid health check"))
+	}
+	a.checks = append(a.checks, healthCheck{name, check})
+	return a
+}
+
+// HealthHandler returns a gear middleware reporting the health of auth, suitable for readiness probes.
+// It checks the key material of JWT (and remember-me JWT if enabled), and all checks added by AddHealthCheck.
+// It responds 200 if all checks passed, otherwise 503, with a JSON body:
+//
+//  {"status":"ok","checks":{"jwt":"ok"}}
+//
+//  app.Use(func(ctx *gear.Context) error {
+//  	if ctx.Path == "/health" {
+//  		return auther.HealthHandler()(ctx)
+//  	}
+//  	return nil
+//  })
+//
+func (a *Auth) HealthHandler() gear.Middleware {
+	return func(ctx *gear.Context) error {
+		checks := []healthCheck{{"jwt", a.j.Check}}
+		if a.remember != nil {
+			checks = append(checks, healthCheck{"remember_me", a.remember.Check})
+		}
+		checks = append(checks, a.checks...)
+
+		status := http.StatusOK
+		report := map[string]string{}
+		for _, c := range checks {
+			if err := c.check(); err != nil {
+				status = http.StatusServiceUnavailable
+				report[c.name] = err.Error()
+			} else {
+				report[c.name] = "ok"
+			}
+		}
+		res := map[string]interface{}{"status": "ok", "checks": report}
+		if status != http.StatusOK {
+			res["status"] = "error"
+		}
+		return ctx.JSON(status, res)
+	}
+}
+
+type healthCheck struct {
+	name  string
+	check func() error
+}
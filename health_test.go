@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/teambition/gear"
+)
+
+func TestHealth(t *testing.T) {
+	t.Run("HealthHandler", func(t *testing.T) {
+		assert := assert.New(t)
+
+		healthy := true
+		a := New([]byte("my key"))
+		assert.Panics(func() {
+			a.AddHealthCheck("", nil)
+		})
+		a.AddHealthCheck("revocation", func() error {
+			if healthy {
+				return nil
+			}
+			return errors.New("connection refused")
+		})
+		app := gear.New()
+		app.Use(a.HealthHandler())
+		srv := app.Start()
+		defer srv.Close()
+
+		host := "http://" + srv.Addr().String()
+		res, err := NewRequst().Get(host)
+		assert.Nil(err)
+		assert.Equal(200, res.StatusCode)
+		body, _ := res.Text()
+		assert.Equal(`{"checks":{"jwt":"ok","revocation":"ok"},"status":"ok"}`, body)
+
+		healthy = false
+		res, err = NewRequst().Get(host)
+		assert.Nil(err)
+		assert.Equal(503, res.StatusCode)
+		body, _ = res.Text()
+		assert.Equal(`{"checks":{"jwt":"ok","revocation":"connection refused"},"status":"error"}`, body)
+	})
+}
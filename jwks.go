@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"encoding/json"
+
+	"github.com/teambition/gear"
+	"github.com/teambition/gear-auth/jwt"
+)
+
+// JWKSHandler returns a gear middleware that responds the public keys of JWT() as a JWK Set (RFC 7517,
+// Section 5), so other services can verify tokens signed by auth with jwt.NewFromJWKS, without distributing
+// keys manually. The keys are read on every request, so rotated keys are published at once.
+//
+//  router.Get("/.well-known/jwks.json", auther.JWKSHandler())
+//
+func (a *Auth) JWKSHandler() gear.Middleware {
+	return func(ctx *gear.Context) error {
+		keys, err := a.j.PublicKeys()
+		if err != nil {
+			return err
+		}
+		set := struct {
+			Keys []json.RawMessage `json:"keys"`
+		}{Keys: make([]json.RawMessage, 0, len(keys))}
+		for _, key := range keys {
+			data, err := jwt.ExportJWK(key)
+			if err != nil {
+				return err
+			}
+			set.Keys = append(set.Keys, data)
+		}
+		ctx.SetHeader(gear.HeaderCacheControl, "public, max-age=300")
+		return ctx.JSON(200, set)
+	}
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	josecrypto "github.com/SermoDigital/jose/crypto"
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/teambition/gear"
+	"github.com/teambition/gear-auth/jwt"
+)
+
+func TestJWKSHandler(t *testing.T) {
+	oldKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	newKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	t.Run("should serve JWKS for NewFromJWKS", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := New()
+		a.JWT().SetSigningWithKID(josecrypto.SigningMethodES256, map[string]interface{}{
+			"2018-01": jwt.KeyPair{PublicKey: &oldKey.PublicKey},
+			"2018-02": jwt.KeyPair{PrivateKey: newKey, PublicKey: &newKey.PublicKey},
+		}, "2018-02")
+
+		app := gear.New()
+		app.Use(a.JWKSHandler())
+		srv := httptest.NewServer(app)
+		defer srv.Close()
+
+		res, err := http.Get(srv.URL)
+		assert.Nil(err)
+		defer res.Body.Close()
+		assert.Equal(200, res.StatusCode)
+		assert.Equal("public, max-age=300", res.Header.Get(gear.HeaderCacheControl))
+		var set struct {
+			Keys []map[string]string `json:"keys"`
+		}
+		assert.Nil(json.NewDecoder(res.Body).Decode(&set))
+		assert.Equal(2, len(set.Keys))
+		assert.Equal("2018-02", set.Keys[0]["kid"])
+		assert.Equal("EC", set.Keys[0]["kty"])
+		assert.Equal("", set.Keys[0]["k"])
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		verifier := jwt.NewFromJWKS(srv.URL, jwt.WithJWKSMethod(josecrypto.SigningMethodES256),
+			jwt.WithRefreshInterval(time.Minute), jwt.WithJWKSContext(ctx))
+		token, _ := a.JWT().Sign(josejwt.Claims{"sub": "alice"})
+		claims, err := verifier.Verify(token)
+		assert.Nil(err)
+		assert.Equal("alice", claims.Get("sub"))
+	})
+
+	t.Run("should respond errors of keys", func(t *testing.T) {
+		assert := assert.New(t)
+
+		notFound := httptest.NewServer(http.NotFoundHandler())
+		defer notFound.Close()
+		a := New()
+		a.JWT().SetKeySource(josecrypto.SigningMethodES256, jwt.NewJWKS(notFound.URL, time.Hour))
+		app := gear.New()
+		app.Use(a.JWKSHandler())
+		srv := httptest.NewServer(app)
+		defer srv.Close()
+
+		res, err := http.Get(srv.URL)
+		assert.Nil(err)
+		res.Body.Close()
+		assert.Equal(500, res.StatusCode)
+	})
+}
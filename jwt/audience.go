@@ -0,0 +1,84 @@
+package jwt
+
+import (
+	"strings"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+)
+
+// SetExpectedAudience requires claim "aud" matching one of the audiences at verification, so tokens issued
+// for other services are rejected (RFC 7519, Section 4.1.3). A string "aud" must equal one of them, and an
+// array "aud" must contain at least one of them. Audiences are compared exactly, use VerifyForAudience
+// for patterns. Tokens without "aud" are rejected. Set no audiences to disable it.
+//
+//  jwter.SetExpectedAudience("billing-api")
+//  jwter.SetExpectedAudience("billing-api", "https://billing.example.com")
+//
+func (j *JWT) SetExpectedAudience(audience ...string) {
+	j.expectedAudience = normalizeAudience(audience)
+}
+
+// containsAudience reports whether claim "aud" contains any of the audiences exactly.
+func containsAudience(claims josejwt.Claims, audience []string) bool {
+	aud, _ := claims.Audience()
+	for _, a := range aud {
+		if containsString(audience, a) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeAudience trims audiences (or scopes), and drops empty and duplicate ones, the order is kept.
+func normalizeAudience(aud []string) []string {
+	res := make([]string, 0, len(aud))
+	for _, a := range aud {
+		if a = strings.TrimSpace(a); a != "" && !containsString(res, a) {
+			res = append(res, a)
+		}
+	}
+	return res
+}
+
+// canonicalizeAudience normalizes claim "aud" before signing, a single audience is written as string,
+// multiple audiences as array, and "aud" is removed if no audience is left.
+func canonicalizeAudience(claims josejwt.Claims) error {
+	if !claims.Has("aud") {
+		return nil
+	}
+	aud, ok := claims.Audience()
+	if !ok {
+		return josejwt.ErrInvalidAUDClaim
+	}
+	if aud = normalizeAudience(aud); len(aud) == 0 {
+		claims.RemoveAudience()
+	} else {
+		claims.SetAudience(aud...)
+	}
+	return nil
+}
+
+// checkAudienceClaim rejects tokens with malformed claim "aud" at verification, and converts
+// an array "aud" decoded from JSON to []string, so it is either string or []string after Verify.
+func checkAudienceClaim(claims josejwt.Claims) error {
+	if !claims.Has("aud") {
+		return nil
+	}
+	aud, ok := claims.Audience()
+	if !ok {
+		return josejwt.ErrInvalidAUDClaim
+	}
+	if _, ok = claims.Get("aud").(string); !ok {
+		claims.Set("aud", aud)
+	}
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
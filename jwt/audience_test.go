@@ -0,0 +1,103 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+
+	josecrypto "github.com/SermoDigital/jose/crypto"
+	josejws "github.com/SermoDigital/jose/jws"
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAudience(t *testing.T) {
+	t.Run("should normalize audience when signing", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"))
+		token, err := jwter.Sign(josejwt.Claims{"aud": []string{" api ", "web", "api", ""}})
+		assert.Nil(err)
+		claims, _ := jwter.Verify(token)
+		assert.Equal([]string{"api", "web"}, claims.Get("aud"))
+
+		token, _ = jwter.Sign(josejwt.Claims{"aud": []interface{}{"api", "api"}})
+		claims, _ = jwter.Verify(token)
+		assert.Equal("api", claims.Get("aud"))
+
+		token, _ = jwter.Sign(josejwt.Claims{"aud": []string{" "}})
+		claims, _ = jwter.Verify(token)
+		assert.False(claims.Has("aud"))
+
+		_, err = jwter.Sign(josejwt.Claims{"aud": 1})
+		assert.Equal(josejwt.ErrInvalidAUDClaim, err)
+
+		jwter.SetAudience("api", "api")
+		token, _ = jwter.Sign(josejwt.Claims{"test": "OK"})
+		claims, _ = jwter.Verify(token)
+		assert.Equal("api", claims.Get("aud"))
+	})
+
+	t.Run("should handle string and array audience when verifying", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"))
+		sign := func(aud interface{}) string {
+			buf, _ := josejws.NewJWT(josejws.Claims{"aud": aud}, josecrypto.SigningMethodHS256).Serialize([]byte("key1"))
+			return string(buf)
+		}
+
+		claims, err := jwter.VerifyForAudience(sign("api"), "api")
+		assert.Nil(err)
+		assert.Equal("api", claims.Get("aud"))
+
+		claims, err = jwter.VerifyForAudience(sign([]string{"api"}), "api")
+		assert.Nil(err)
+		assert.Equal([]string{"api"}, claims.Get("aud"))
+
+		_, err = jwter.Verify(sign(123))
+		assert.Contains(err.Error(), josejwt.ErrInvalidAUDClaim.Error())
+		_, err = jwter.Verify(sign([]interface{}{"api", 1}))
+		assert.Contains(err.Error(), josejwt.ErrInvalidAUDClaim.Error())
+		_, err = jwter.Verify(sign([]string{}))
+		assert.Contains(err.Error(), josejwt.ErrInvalidAUDClaim.Error())
+	})
+
+	t.Run("should check expected audience when verifying", func(t *testing.T) {
+		assert := assert.New(t)
+
+		signer := New([]byte("key1"))
+		sign := func(aud ...string) string {
+			claims := josejwt.Claims{"sub": "alice"}
+			if len(aud) > 0 {
+				claims.SetAudience(aud...)
+			}
+			token, _ := signer.Sign(claims)
+			return token
+		}
+
+		jwter := New([]byte("key1"))
+		jwter.SetExpectedAudience("billing-api", " ", "https://billing.example.com")
+		_, err := jwter.Verify(sign("billing-api"))
+		assert.Nil(err)
+		_, err = jwter.Verify(sign("web", "https://billing.example.com"))
+		assert.Nil(err)
+
+		_, err = jwter.Verify(sign("billing-api-v2"))
+		assert.True(errors.Is(err, ErrBadAudience))
+		_, err = jwter.Verify(sign("web", "admin"))
+		assert.True(errors.Is(err, ErrBadAudience))
+		_, err = jwter.Verify(sign())
+		assert.True(errors.Is(err, ErrBadAudience))
+
+		// audiences are not patterns
+		jwter.SetExpectedAudience("https://*.example.com")
+		_, err = jwter.Verify(sign("https://billing.example.com"))
+		assert.True(errors.Is(err, ErrBadAudience))
+		_, err = jwter.Verify(sign("https://*.example.com"))
+		assert.Nil(err)
+
+		jwter.SetExpectedAudience()
+		_, err = jwter.Verify(sign())
+		assert.Nil(err)
+	})
+}
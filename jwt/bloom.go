@@ -0,0 +1,207 @@
+package jwt
+
+import (
+	"errors"
+	"hash/fnv"
+	"math"
+	"sync"
+	"time"
+)
+
+// RevocationSource is the source of truth of revoked tokens for BloomRevoker, such as a database table.
+type RevocationSource interface {
+	Revoker
+	// Revoked returns the "jti" of all revoked tokens that haven't expired.
+	Revoked() ([]string, error)
+}
+
+// BloomRevoker is a Revoker for very large revocation sets. It keeps a bloom filter of revoked "jti"
+// in memory, so most lookups are answered in O(1) without the source, only the filter hits
+// (revoked tokens and a small rate of false positives) are re-checked with the source.
+// The filter is rebuilt from the source periodically, to drop expired revocations.
+// It is a SubjectRevoker, subject revocations are passed through to the source if it is a SubjectRevoker.
+type BloomRevoker struct {
+	source RevocationSource
+	rate   float64
+
+	mu         sync.RWMutex
+	filter     *bloomFilter
+	rebuilding int      // the number of running rebuilds
+	pending    []string // revoked during rebuilds, they may be missing in the source's snapshot
+	err        error
+	stop       chan struct{}
+	once       sync.Once
+}
+
+var _ SubjectRevoker = (*BloomRevoker)(nil)
+
+// NewBloomRevoker builds the filter from the source immediately and returns a BloomRevoker instance
+// that rebuilds it every rebuildInterval. falsePositiveRate is the expected rate of lookups re-checked
+// with the source for tokens not revoked, such as 0.01. Rebuild failures keep the current filter,
+// the last failure is reported by Check.
+//
+//  revoker, err := jwt.NewBloomRevoker(dbRevocations, 0.01, time.Hour)
+//  jwter.SetRevoker(revoker)
+//
+func NewBloomRevoker(source RevocationSource, falsePositiveRate float64, rebuildInterval time.Duration) (*BloomRevoker, error) {
+	if source == nil {
+		panic(errors.New("invalid revocation source"))
+	}
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		panic(errors.New("invalid false positive rate"))
+	}
+	if rebuildInterval <= 0 {
+		panic(errors.New("invalid rebuild interval"))
+	}
+	b := &BloomRevoker{source: source, rate: falsePositiveRate, stop: make(chan struct{})}
+	if err := b.Rebuild(); err != nil {
+		return nil, err
+	}
+	go b.run(rebuildInterval)
+	return b, nil
+}
+
+func (b *BloomRevoker) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+			b.Rebuild()
+		}
+	}
+}
+
+// Rebuild rebuilds the filter from the source. The filter is sized for twice the current revocations,
+// to keep the false positive rate for revocations added until the next rebuild.
+// Revocations made while rebuilding are added to the new filter as well.
+func (b *BloomRevoker) Rebuild() error {
+	b.mu.Lock()
+	b.rebuilding++
+	b.mu.Unlock()
+
+	ids, err := b.source.Revoked()
+	var filter *bloomFilter
+	if err == nil {
+		filter = newBloomFilter(2*len(ids), b.rate)
+		for _, id := range ids {
+			filter.add(id)
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.rebuilding--; err != nil {
+		b.err = err
+	} else {
+		for _, id := range b.pending {
+			filter.add(id)
+		}
+		b.filter = filter
+		b.err = nil
+	}
+	if b.rebuilding == 0 {
+		b.pending = nil
+	}
+	return err
+}
+
+// Revoke implements the Revoker interface, the jti is saved to the source and added to the filter.
+func (b *BloomRevoker) Revoke(jti string, exp time.Time) error {
+	if err := b.source.Revoke(jti, exp); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	b.filter.add(jti)
+	if b.rebuilding > 0 {
+		b.pending = append(b.pending, jti)
+	}
+	b.mu.Unlock()
+	return nil
+}
+
+// RevokeSubject implements the SubjectRevoker interface, it is passed through to the source.
+// It returns an error if the source is not a SubjectRevoker.
+func (b *BloomRevoker) RevokeSubject(sub string, before time.Time) error {
+	sr, ok := b.source.(SubjectRevoker)
+	if !ok {
+		return errors.New("revocation source doesn't support subject revocation")
+	}
+	return sr.RevokeSubject(sub, before)
+}
+
+// RevokedBefore implements the SubjectRevoker interface, it is passed through to the source.
+// It returns zero time if the source is not a SubjectRevoker.
+func (b *BloomRevoker) RevokedBefore(sub string) time.Time {
+	if sr, ok := b.source.(SubjectRevoker); ok {
+		return sr.RevokedBefore(sub)
+	}
+	return time.Time{}
+}
+
+// IsRevoked implements the Revoker interface, filter hits are re-checked with the source.
+func (b *BloomRevoker) IsRevoked(jti string) bool {
+	b.mu.RLock()
+	hit := b.filter.has(jti)
+	b.mu.RUnlock()
+	return hit && b.source.IsRevoked(jti)
+}
+
+// Check implements the HealthChecker interface, it reports the last rebuild failure.
+func (b *BloomRevoker) Check() error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.err
+}
+
+// Stop stops rebuilding, the current filter is still available.
+func (b *BloomRevoker) Stop() {
+	b.once.Do(func() {
+		close(b.stop)
+	})
+}
+
+// bloomFilter is a bloom filter with double hashing.
+type bloomFilter struct {
+	bits []uint64
+	m    uint64
+	k    uint64
+}
+
+// newBloomFilter returns a filter sized for n items with the false positive rate.
+func newBloomFilter(n int, rate float64) *bloomFilter {
+	if n < 1024 {
+		n = 1024
+	}
+	m := uint64(math.Ceil(-float64(n) * math.Log(rate) / (math.Ln2 * math.Ln2)))
+	k := uint64(math.Ceil(math.Ln2 * float64(m) / float64(n)))
+	return &bloomFilter{bits: make([]uint64, (m+63)/64), m: m, k: k}
+}
+
+func (f *bloomFilter) hash(s string) (uint64, uint64) {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	sum := h.Sum64()
+	return sum, sum>>32 | 1
+}
+
+func (f *bloomFilter) add(s string) {
+	h1, h2 := f.hash(s)
+	for i := uint64(0); i < f.k; i++ {
+		n := (h1 + i*h2) % f.m
+		f.bits[n/64] |= 1 << (n % 64)
+	}
+}
+
+func (f *bloomFilter) has(s string) bool {
+	h1, h2 := f.hash(s)
+	for i := uint64(0); i < f.k; i++ {
+		n := (h1 + i*h2) % f.m
+		if f.bits[n/64]&(1<<(n%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,222 @@
+package jwt
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+// mapRevocations is a RevocationSource backed by a map, it counts IsRevoked calls.
+type mapRevocations struct {
+	mu     sync.Mutex
+	ids    map[string]time.Time
+	checks int
+	err    error
+}
+
+func newMapRevocations() *mapRevocations {
+	return &mapRevocations{ids: make(map[string]time.Time)}
+}
+
+func (m *mapRevocations) Revoke(jti string, exp time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ids[jti] = exp
+	return nil
+}
+
+func (m *mapRevocations) IsRevoked(jti string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checks++
+	_, ok := m.ids[jti]
+	return ok
+}
+
+func (m *mapRevocations) Revoked() ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return nil, m.err
+	}
+	ids := make([]string, 0, len(m.ids))
+	for id, exp := range m.ids {
+		if exp.After(time.Now()) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// pausedRevocations pauses Revoked after the snapshot is taken, until resume is closed.
+type pausedRevocations struct {
+	*mapRevocations
+	snapshot chan struct{}
+	resume   chan struct{}
+}
+
+func (p *pausedRevocations) Revoked() ([]string, error) {
+	ids, err := p.mapRevocations.Revoked()
+	if p.snapshot != nil {
+		p.snapshot <- struct{}{}
+		<-p.resume
+	}
+	return ids, err
+}
+
+// subjectRevocations is a RevocationSource and SubjectRevoker.
+type subjectRevocations struct {
+	*mapRevocations
+	subjects sync.Map
+}
+
+func (s *subjectRevocations) RevokeSubject(sub string, before time.Time) error {
+	s.subjects.Store(sub, before)
+	return nil
+}
+
+func (s *subjectRevocations) RevokedBefore(sub string) time.Time {
+	before, _ := s.subjects.Load(sub)
+	t, _ := before.(time.Time)
+	return t
+}
+
+func TestBloomRevoker(t *testing.T) {
+	t.Run("should check filter hits with source", func(t *testing.T) {
+		assert := assert.New(t)
+
+		source := newMapRevocations()
+		assert.Panics(func() {
+			NewBloomRevoker(nil, 0.01, time.Hour)
+		})
+		assert.Panics(func() {
+			NewBloomRevoker(source, 1, time.Hour)
+		})
+		assert.Panics(func() {
+			NewBloomRevoker(source, 0.01, 0)
+		})
+
+		exp := time.Now().Add(time.Hour)
+		for i := 0; i < 5000; i++ {
+			source.Revoke("revoked-"+strconv.Itoa(i), exp)
+		}
+		revoker, err := NewBloomRevoker(source, 0.01, time.Hour)
+		assert.Nil(err)
+		defer revoker.Stop()
+
+		for i := 0; i < 5000; i++ {
+			assert.True(revoker.IsRevoked("revoked-" + strconv.Itoa(i)))
+		}
+		source.checks = 0
+		for i := 0; i < 10000; i++ {
+			assert.False(revoker.IsRevoked("valid-" + strconv.Itoa(i)))
+		}
+		assert.True(source.checks < 200)
+
+		assert.False(revoker.IsRevoked("new"))
+		assert.Nil(revoker.Revoke("new", exp))
+		assert.True(revoker.IsRevoked("new"))
+	})
+
+	t.Run("should rebuild from source", func(t *testing.T) {
+		assert := assert.New(t)
+
+		source := newMapRevocations()
+		source.err = errors.New("database is unreachable")
+		_, err := NewBloomRevoker(source, 0.01, time.Hour)
+		assert.Equal("database is unreachable", err.Error())
+
+		source.err = nil
+		source.Revoke("expired", time.Now().Add(20*time.Millisecond))
+		revoker, err := NewBloomRevoker(source, 0.01, 30*time.Millisecond)
+		assert.Nil(err)
+		defer revoker.Stop()
+		assert.True(revoker.IsRevoked("expired"))
+
+		time.Sleep(50 * time.Millisecond)
+		source.checks = 0
+		assert.False(revoker.IsRevoked("expired"))
+		assert.Equal(0, source.checks)
+
+		source.mu.Lock()
+		source.err = errors.New("database is unreachable")
+		source.mu.Unlock()
+		time.Sleep(40 * time.Millisecond)
+		assert.Equal("database is unreachable", revoker.Check().Error())
+
+		jwter := New([]byte("key1"))
+		jwter.SetRevoker(revoker)
+		assert.Equal("revoker: database is unreachable", jwter.Check().Error())
+	})
+
+	t.Run("should reject revoked tokens", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"))
+		assert.Panics(func() {
+			jwter.SetRevoker(nil)
+		})
+		revoker, _ := NewBloomRevoker(newMapRevocations(), 0.01, time.Hour)
+		defer revoker.Stop()
+		jwter.SetRevoker(revoker)
+
+		token, _ := jwter.Sign(josejwt.Claims{"jti": "abc"})
+		_, err := jwter.Verify(token)
+		assert.Nil(err)
+		token2, _ := jwter.Sign(josejwt.Claims{"sub": "user"})
+
+		revoker.Revoke("abc", time.Now().Add(time.Hour))
+		_, err = jwter.Verify(token)
+		assert.Contains(err.Error(), ErrTokenRevoked.Error())
+		_, err = jwter.Verify(token2)
+		assert.Nil(err)
+	})
+
+	t.Run("should keep revocations made while rebuilding", func(t *testing.T) {
+		assert := assert.New(t)
+
+		source := &pausedRevocations{mapRevocations: newMapRevocations()}
+		revoker, _ := NewBloomRevoker(source, 0.01, time.Hour)
+		defer revoker.Stop()
+
+		source.snapshot, source.resume = make(chan struct{}), make(chan struct{})
+		done := make(chan error)
+		go func() {
+			done <- revoker.Rebuild()
+		}()
+		<-source.snapshot
+		assert.Nil(revoker.Revoke("abc", time.Now().Add(time.Hour)))
+		close(source.resume)
+		assert.Nil(<-done)
+		assert.True(revoker.IsRevoked("abc"))
+		assert.Nil(revoker.pending)
+	})
+
+	t.Run("should pass subject revocations through to the source", func(t *testing.T) {
+		assert := assert.New(t)
+
+		source := &subjectRevocations{mapRevocations: newMapRevocations()}
+		revoker, _ := NewBloomRevoker(source, 0.01, time.Hour)
+		defer revoker.Stop()
+		jwter := New([]byte("key1"))
+		jwter.SetRevoker(revoker)
+
+		token, _ := jwter.Sign(josejwt.Claims{"sub": "alice", "iat": time.Now().Add(-time.Minute).Unix()})
+		_, err := jwter.Verify(token)
+		assert.Nil(err)
+		assert.Nil(revoker.RevokeSubject("alice", time.Now()))
+		assert.False(revoker.RevokedBefore("alice").IsZero())
+		_, err = jwter.Verify(token)
+		assert.Contains(err.Error(), ErrTokenRevoked.Error())
+
+		other, _ := NewBloomRevoker(newMapRevocations(), 0.01, time.Hour)
+		defer other.Stop()
+		assert.NotNil(other.RevokeSubject("alice", time.Now()))
+		assert.True(other.RevokedBefore("alice").IsZero())
+	})
+}
@@ -0,0 +1,82 @@
+package jwt
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by CircuitBreaker when calls are rejected without being attempted.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitBreaker protects remote dependencies (JWKS, introspection, KMS, etc.) from being hammered
+// during outages. After threshold consecutive failures it opens and rejects calls with ErrCircuitOpen,
+// after cooldown it lets one trial call through: the circuit closes if it succeeds, or reopens.
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openedAt  time.Time
+	trial     bool
+}
+
+// NewCircuitBreaker returns a CircuitBreaker instance.
+//
+//  breaker := jwt.NewCircuitBreaker(5, 30*time.Second)
+//  jwks.SetCircuitBreaker(breaker)
+//  // or wrap any remote call
+//  err := breaker.Do(func() error {
+//  	secret, err = kms.Decrypt(ciphertext)
+//  	return err
+//  })
+//
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	if threshold <= 0 || cooldown <= 0 {
+		panic(errors.New("invalid circuit breaker arguments"))
+	}
+	return &CircuitBreaker{threshold: threshold, cooldown: cooldown}
+}
+
+// Do calls fn if the circuit is closed (or for the trial call), and records the result.
+func (b *CircuitBreaker) Do(fn func() error) error {
+	if !b.allow() {
+		return ErrCircuitOpen
+	}
+	err := fn()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+	if err == nil {
+		b.failures = 0
+		return nil
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openedAt = time.Now()
+	}
+	return err
+}
+
+func (b *CircuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return true
+	}
+	if b.trial || time.Since(b.openedAt) < b.cooldown {
+		return false
+	}
+	b.trial = true
+	return true
+}
+
+// Check implements the HealthChecker interface, it returns ErrCircuitOpen when the circuit is open.
+func (b *CircuitBreaker) Check() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures >= b.threshold {
+		return ErrCircuitOpen
+	}
+	return nil
+}
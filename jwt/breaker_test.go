@@ -0,0 +1,50 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Run("should open after threshold failures", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.Panics(func() {
+			NewCircuitBreaker(0, time.Second)
+		})
+
+		calls := 0
+		failed := true
+		fn := func() error {
+			calls++
+			if failed {
+				return errors.New("connection refused")
+			}
+			return nil
+		}
+		breaker := NewCircuitBreaker(2, 20*time.Millisecond)
+		assert.Equal("connection refused", breaker.Do(fn).Error())
+		assert.Nil(breaker.Check())
+		assert.Equal("connection refused", breaker.Do(fn).Error())
+		assert.Equal(ErrCircuitOpen, breaker.Check())
+		assert.Equal(ErrCircuitOpen, breaker.Do(fn))
+		assert.Equal(2, calls)
+
+		// trial call failed, reopen
+		time.Sleep(30 * time.Millisecond)
+		assert.Equal("connection refused", breaker.Do(fn).Error())
+		assert.Equal(ErrCircuitOpen, breaker.Do(fn))
+		assert.Equal(3, calls)
+
+		// trial call succeeded, close
+		time.Sleep(30 * time.Millisecond)
+		failed = false
+		assert.Nil(breaker.Do(fn))
+		assert.Nil(breaker.Check())
+		assert.Nil(breaker.Do(fn))
+		assert.Equal(5, calls)
+	})
+}
@@ -0,0 +1,100 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"time"
+
+	josecrypto "github.com/SermoDigital/jose/crypto"
+	josejws "github.com/SermoDigital/jose/jws"
+	josejwt "github.com/SermoDigital/jose/jwt"
+)
+
+// KeyBundle is a verify-only KeyIDSource of issuer public keys loaded from a signed offline bundle,
+// for air-gapped deployments where the JWKS URL is unreachable. The bundle is a token signed by a pinned
+// key, with the JWK Set in claim "jwks". Bundles can be produced from a live JWKS by JWKS.Bundle.
+type KeyBundle struct {
+	keys []interface{}
+	kids map[string]interface{}
+}
+
+// LoadKeyBundle reads the bundle file and verifies it with the pinned method and key, see ParseKeyBundle.
+//
+//  bundle, err := jwt.LoadKeyBundle("/etc/auth/jwks.bundle", ed25519.SigningMethodED25519, pinnedPublicKey)
+//  jwter.SetKeySource(josecrypto.SigningMethodRS256, bundle)
+//
+func LoadKeyBundle(filename string, method josecrypto.SigningMethod, pinnedKey interface{}) (*KeyBundle, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return ParseKeyBundle(data, method, pinnedKey)
+}
+
+// ParseKeyBundle verifies the bundle with the pinned method and key (the bundle's "exp" is validated
+// if present), and returns the keys in it.
+func ParseKeyBundle(data []byte, method josecrypto.SigningMethod, pinnedKey interface{}) (*KeyBundle, error) {
+	token, err := josejws.ParseJWT(data)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := Verify(token, method, []interface{}{pinnedKey})
+	if err != nil {
+		return nil, errors.New("invalid key bundle: " + err.Error())
+	}
+	buf, err := json.Marshal(claims.Get("jwks"))
+	if err != nil {
+		return nil, err
+	}
+	set := &jsonWebKeySet{}
+	if err = json.Unmarshal(buf, set); err != nil {
+		return nil, err
+	}
+	keys, kids, err := parseKeySet(set)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyBundle{keys: keys, kids: kids}, nil
+}
+
+// Keys implements the KeySource interface.
+func (b *KeyBundle) Keys() ([]interface{}, error) {
+	return b.keys, nil
+}
+
+// KeysByID implements the KeyIDSource interface.
+func (b *KeyBundle) KeysByID(kid string) ([]interface{}, error) {
+	if key, ok := b.kids[kid]; ok {
+		return []interface{}{key}, nil
+	}
+	return nil, ErrUnknownKeyID
+}
+
+// Bundle fetches the live key set and returns a bundle signed with the method and key, which can be
+// written to a file and loaded by LoadKeyBundle with the pinned public key.
+// If expiresIn > 0, the bundle will expire after it.
+//
+//  data, err := jwt.NewJWKS(jwksURL, time.Hour).Bundle(ed25519.SigningMethodED25519, keyPair, 0)
+//  err = ioutil.WriteFile("jwks.bundle", data, 0644)
+//
+func (k *JWKS) Bundle(method josecrypto.SigningMethod, key interface{}, expiresIn time.Duration) ([]byte, error) {
+	set, err := k.get(false)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err = parseKeySet(set); err != nil {
+		return nil, err
+	}
+	claims := josejwt.Claims{"jwks": set}
+	if expiresIn > 0 {
+		claims.SetExpiration(time.Now().Add(expiresIn))
+	}
+	token, err := Sign(claims, method, key)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(token), nil
+}
+
+var _ KeyIDSource = (*KeyBundle)(nil)
@@ -0,0 +1,58 @@
+package jwt
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	josecrypto "github.com/SermoDigital/jose/crypto"
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyBundle(t *testing.T) {
+	t.Run("should create and load key bundle", func(t *testing.T) {
+		assert := assert.New(t)
+
+		srv := newTestJWKSServer()
+		key1 := srv.addKey("key1")
+		data, err := NewJWKS(srv.URL, time.Hour).Bundle(josecrypto.SigningMethodHS256, []byte("pinned key"), time.Hour)
+		assert.Nil(err)
+		srv.Close()
+
+		dir, _ := ioutil.TempDir("", "bundle")
+		defer os.RemoveAll(dir)
+		filename := filepath.Join(dir, "jwks.bundle")
+		assert.Nil(ioutil.WriteFile(filename, data, 0644))
+
+		_, err = LoadKeyBundle(filepath.Join(dir, "none"), josecrypto.SigningMethodHS256, []byte("pinned key"))
+		assert.NotNil(err)
+		_, err = LoadKeyBundle(filename, josecrypto.SigningMethodHS256, []byte("other key"))
+		assert.Contains(err.Error(), "invalid key bundle")
+
+		bundle, err := LoadKeyBundle(filename, josecrypto.SigningMethodHS256, []byte("pinned key"))
+		assert.Nil(err)
+		jwter := New()
+		jwter.SetKeySource(josecrypto.SigningMethodES256, bundle)
+		claims, err := jwter.Verify(signWithKID(key1, "key1", josejwt.Claims{"sub": "alice"}))
+		assert.Nil(err)
+		assert.Equal("alice", claims.Get("sub"))
+		_, err = jwter.Verify(signWithKID(key1, "key2", josejwt.Claims{"sub": "alice"}))
+		assert.Contains(err.Error(), "unknown kid")
+	})
+
+	t.Run("should reject expired key bundle", func(t *testing.T) {
+		assert := assert.New(t)
+
+		srv := newTestJWKSServer()
+		defer srv.Close()
+		srv.addKey("key1")
+		data, err := NewJWKS(srv.URL, time.Hour).Bundle(josecrypto.SigningMethodHS256, []byte("pinned key"), time.Millisecond)
+		assert.Nil(err)
+		time.Sleep(1100 * time.Millisecond)
+		_, err = ParseKeyBundle(data, josecrypto.SigningMethodHS256, []byte("pinned key"))
+		assert.Contains(err.Error(), "token is expired")
+	})
+}
@@ -0,0 +1,132 @@
+package jwt
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCacheMiss is returned by Cache when the key doesn't exist or has expired.
+var ErrCacheMiss = errors.New("cache miss")
+
+// Cache is a key-value cache with TTL, shared by features that cache remote results: the JWKS key set
+// (JWKS.SetCache), verdicts of remote verification (RemoteVerifier.SetCache) and revocation lookups
+// (NewCachedRevoker). MemoryCache is the in-memory implementation, package cacheredis implements it
+// with Redis, so that the cache can be shared by all instances of a service.
+type Cache interface {
+	// Get returns the value of the key, or ErrCacheMiss.
+	Get(key string) ([]byte, error)
+	// Set sets the value of the key, ttl <= 0 means the value never expires.
+	Set(key string, val []byte, ttl time.Duration) error
+}
+
+// MemoryCache is a in-memory Cache implementation, expired values are swept every minute.
+type MemoryCache struct {
+	mu        sync.Mutex
+	entries   map[string]cacheEntry
+	lastSweep time.Time
+}
+
+type cacheEntry struct {
+	val       []byte
+	expiresAt time.Time
+}
+
+var _ Cache = (*MemoryCache)(nil)
+
+// NewMemoryCache returns a MemoryCache instance.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[string]cacheEntry), lastSweep: time.Now()}
+}
+
+// Get implements the Cache interface.
+func (c *MemoryCache) Get(key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, ErrCacheMiss
+	}
+	if entry.expired(time.Now()) {
+		delete(c.entries, key)
+		return nil, ErrCacheMiss
+	}
+	return entry.val, nil
+}
+
+// Set implements the Cache interface.
+func (c *MemoryCache) Set(key string, val []byte, ttl time.Duration) error {
+	entry := cacheEntry{val: val}
+	now := time.Now()
+	if ttl > 0 {
+		entry.expiresAt = now.Add(ttl)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = entry
+	if now.Sub(c.lastSweep) > time.Minute {
+		c.lastSweep = now
+		for k, e := range c.entries {
+			if e.expired(now) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	return nil
+}
+
+func (e cacheEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && now.After(e.expiresAt)
+}
+
+// NewCachedRevoker returns a Revoker that caches the lookups of revoker for ttl, for revokers backed by
+// a slow database. A revocation by its Revoke takes effect immediately, but revocations made by others
+// take up to ttl to be seen if the token was looked up before.
+//
+//  jwter.SetRevoker(jwt.NewCachedRevoker(dbRevoker, jwt.NewMemoryCache(), 10*time.Second))
+//
+func NewCachedRevoker(revoker Revoker, cache Cache, ttl time.Duration) Revoker {
+	if revoker == nil || cache == nil || ttl <= 0 {
+		panic(errors.New("invalid cached revoker arguments"))
+	}
+	return &cachedRevoker{revoker: revoker, cache: cache, ttl: ttl}
+}
+
+type cachedRevoker struct {
+	revoker Revoker
+	cache   Cache
+	ttl     time.Duration
+}
+
+var (
+	cacheRevoked    = []byte{1}
+	cacheNotRevoked = []byte{0}
+)
+
+func (r *cachedRevoker) Revoke(jti string, exp time.Time) error {
+	if err := r.revoker.Revoke(jti, exp); err != nil {
+		return err
+	}
+	ttl := time.Duration(0)
+	if !exp.IsZero() {
+		if ttl = time.Until(exp); ttl <= 0 {
+			return nil
+		}
+	}
+	r.cache.Set("revoked:"+jti, cacheRevoked, ttl)
+	return nil
+}
+
+func (r *cachedRevoker) IsRevoked(jti string) bool {
+	key := "revoked:" + jti
+	if val, err := r.cache.Get(key); err == nil && len(val) == 1 {
+		return val[0] == cacheRevoked[0]
+	}
+	if r.revoker.IsRevoked(jti) {
+		r.cache.Set(key, cacheRevoked, r.ttl)
+		return true
+	}
+	r.cache.Set(key, cacheNotRevoked, r.ttl)
+	return false
+}
@@ -0,0 +1,186 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// failingCache is a Cache that always fails.
+type failingCache struct{}
+
+func (failingCache) Get(key string) ([]byte, error) {
+	return nil, errors.New("cache is down")
+}
+
+func (failingCache) Set(key string, val []byte, ttl time.Duration) error {
+	return errors.New("cache is down")
+}
+
+func TestCache(t *testing.T) {
+	t.Run("MemoryCache", func(t *testing.T) {
+		assert := assert.New(t)
+
+		cache := NewMemoryCache()
+		_, err := cache.Get("a")
+		assert.Equal(ErrCacheMiss, err)
+
+		assert.Nil(cache.Set("a", []byte("hello"), 10*time.Millisecond))
+		assert.Nil(cache.Set("b", []byte("world"), 0))
+		val, err := cache.Get("a")
+		assert.Nil(err)
+		assert.Equal("hello", string(val))
+
+		time.Sleep(20 * time.Millisecond)
+		_, err = cache.Get("a")
+		assert.Equal(ErrCacheMiss, err)
+		val, err = cache.Get("b")
+		assert.Nil(err)
+		assert.Equal("world", string(val))
+
+		cache.Set("c", []byte("expired"), time.Millisecond)
+		time.Sleep(2 * time.Millisecond)
+		cache.lastSweep = time.Now().Add(-2 * time.Minute)
+		cache.Set("d", []byte("new"), 0)
+		assert.Equal(2, len(cache.entries))
+	})
+
+	t.Run("NewCachedRevoker", func(t *testing.T) {
+		assert := assert.New(t)
+
+		source := newMapRevocations()
+		assert.Panics(func() {
+			NewCachedRevoker(nil, NewMemoryCache(), time.Minute)
+		})
+		assert.Panics(func() {
+			NewCachedRevoker(source, nil, time.Minute)
+		})
+		assert.Panics(func() {
+			NewCachedRevoker(source, NewMemoryCache(), 0)
+		})
+
+		revoker := NewCachedRevoker(source, NewMemoryCache(), time.Minute)
+		assert.False(revoker.IsRevoked("a"))
+		assert.False(revoker.IsRevoked("a"))
+		assert.Equal(1, source.checks)
+
+		assert.Nil(revoker.Revoke("a", time.Now().Add(time.Hour)))
+		assert.True(revoker.IsRevoked("a"))
+		assert.Equal(1, source.checks)
+
+		// revoked by others, not seen until the cached lookup expires
+		source.Revoke("b", time.Now().Add(time.Hour))
+		revoker = NewCachedRevoker(source, NewMemoryCache(), 10*time.Millisecond)
+		assert.True(revoker.IsRevoked("b"))
+		assert.True(revoker.IsRevoked("b"))
+		assert.False(revoker.IsRevoked("c"))
+		source.Revoke("c", time.Now().Add(time.Hour))
+		assert.False(revoker.IsRevoked("c"))
+		time.Sleep(20 * time.Millisecond)
+		assert.True(revoker.IsRevoked("c"))
+
+		assert.Nil(revoker.Revoke("expired", time.Now().Add(-time.Second)))
+		assert.Nil(revoker.Revoke("forever", time.Time{}))
+		assert.True(revoker.IsRevoked("forever"))
+
+		jwter := New([]byte("key1"))
+		jwter.SetRevoker(NewCachedRevoker(source, failingCache{}, time.Minute))
+		token, _ := jwter.Sign(map[string]interface{}{"jti": "a"})
+		_, err := jwter.Verify(token)
+		assert.Contains(err.Error(), ErrTokenRevoked.Error())
+	})
+
+	t.Run("JWKS with shared cache", func(t *testing.T) {
+		assert := assert.New(t)
+
+		srv := newTestJWKSServer()
+		defer srv.Close()
+		srv.addKey("key1")
+
+		assert.Panics(func() {
+			NewJWKS(srv.URL, time.Minute).SetCache(nil)
+		})
+
+		cache := NewMemoryCache()
+		jwks1 := NewJWKS(srv.URL, time.Minute).SetCache(cache)
+		jwks2 := NewJWKS(srv.URL, time.Minute).SetCache(cache)
+		keys, err := jwks1.Keys()
+		assert.Nil(err)
+		assert.Equal(1, len(keys))
+		keys, err = jwks2.Keys()
+		assert.Nil(err)
+		assert.Equal(1, len(keys))
+		assert.Equal(1, srv.fetchCount())
+
+		// unknown kid bypasses the shared cache
+		srv.addKey("key2")
+		jwks2.SetMinRefreshInterval(0)
+		keys, err = jwks2.KeysByID("key2")
+		assert.Nil(err)
+		assert.Equal(1, len(keys))
+		assert.Equal(2, srv.fetchCount())
+
+		jwks3 := NewJWKS(srv.URL, time.Minute).SetCache(failingCache{})
+		keys, err = jwks3.Keys()
+		assert.Nil(err)
+		assert.Equal(2, len(keys))
+
+		// refreshing in background bypasses the shared cache, and updates it
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		jwks4 := NewJWKS(srv.URL, time.Minute).SetCache(cache).StartRefresh(ctx, time.Hour)
+		deadline := time.Now().Add(time.Second)
+		for time.Now().Before(deadline) {
+			jwks4.mu.RLock()
+			fetched := !jwks4.fetchedAt.IsZero()
+			jwks4.mu.RUnlock()
+			if fetched {
+				break
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		keys, err = jwks4.Keys()
+		assert.Nil(err)
+		assert.Equal(2, len(keys))
+		assert.Equal(4, srv.fetchCount())
+		srv.addKey("key3")
+		jwks4.refresh()
+		assert.Equal(5, srv.fetchCount())
+		keys, err = NewJWKS(srv.URL, time.Minute).SetCache(cache).Keys()
+		assert.Nil(err)
+		assert.Equal(3, len(keys))
+		assert.Equal(5, srv.fetchCount())
+	})
+
+	t.Run("RemoteVerifier with shared cache", func(t *testing.T) {
+		assert := assert.New(t)
+
+		calls := 0
+		call := func(ctx context.Context, token string) (*RemoteVerdict, error) {
+			calls++
+			return &RemoteVerdict{Valid: true, Claims: map[string]interface{}{"sub": token}}, nil
+		}
+		assert.Panics(func() {
+			NewRemoteVerifierFunc(call, time.Minute).SetCache(nil)
+		})
+
+		cache := NewMemoryCache()
+		verifier1 := NewRemoteVerifierFunc(call, time.Minute).SetCache(cache)
+		verifier2 := NewRemoteVerifierFunc(call, time.Minute).SetCache(cache)
+		claims, err := verifier1.Verify("alice")
+		assert.Nil(err)
+		assert.Equal("alice", claims.Get("sub"))
+		claims, err = verifier2.Verify("alice")
+		assert.Nil(err)
+		assert.Equal("alice", claims.Get("sub"))
+		assert.Equal(1, calls)
+
+		verifier3 := NewRemoteVerifierFunc(call, time.Minute).SetCache(failingCache{})
+		_, err = verifier3.Verify("alice")
+		assert.Nil(err)
+		assert.Equal(2, calls)
+	})
+}
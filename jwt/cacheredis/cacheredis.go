@@ -0,0 +1,51 @@
+// Package cacheredis implements jwt.Cache with Redis, so that the JWKS key set, remote verification
+// verdicts and revocation lookups are cached once for all instances of a service.
+//
+//  client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+//  cache := cacheredis.New(client, "myapp:jwt:")
+//  jwks.SetCache(cache)
+//  verifier.SetCache(cache)
+//
+package cacheredis
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/teambition/gear-auth/jwt"
+)
+
+// Cache is a jwt.Cache backed by Redis, values are stored as strings with Redis TTLs.
+type Cache struct {
+	client redis.Cmdable
+	prefix string
+}
+
+var _ jwt.Cache = (*Cache)(nil)
+
+// New returns a Cache with the Redis client, such as *redis.Client or *redis.ClusterClient.
+// Keys are prefixed with prefix, so that the cache can share a Redis database with others.
+func New(client redis.Cmdable, prefix string) *Cache {
+	if client == nil {
+		panic(errors.New("invalid redis client"))
+	}
+	return &Cache{client: client, prefix: prefix}
+}
+
+// Get implements the jwt.Cache interface.
+func (c *Cache) Get(key string) ([]byte, error) {
+	val, err := c.client.Get(c.prefix + key).Bytes()
+	if err == redis.Nil {
+		return nil, jwt.ErrCacheMiss
+	}
+	return val, err
+}
+
+// Set implements the jwt.Cache interface.
+func (c *Cache) Set(key string, val []byte, ttl time.Duration) error {
+	if ttl < 0 {
+		ttl = 0
+	}
+	return c.client.Set(c.prefix+key, val, ttl).Err()
+}
@@ -0,0 +1,63 @@
+package cacheredis_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/go-redis/redis"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/teambition/gear-auth/jwt"
+	"github.com/teambition/gear-auth/jwt/cacheredis"
+)
+
+func TestCache(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mr.Close()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	t.Run("should get and set with TTL", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.Panics(func() {
+			cacheredis.New(nil, "")
+		})
+
+		cache := cacheredis.New(client, "test:")
+		_, err := cache.Get("a")
+		assert.Equal(jwt.ErrCacheMiss, err)
+
+		assert.Nil(cache.Set("a", []byte("hello"), time.Second))
+		assert.Nil(cache.Set("b", []byte{0, 1}, 0))
+		val, err := cache.Get("a")
+		assert.Nil(err)
+		assert.Equal("hello", string(val))
+		val, err = cache.Get("b")
+		assert.Nil(err)
+		assert.Equal([]byte{0, 1}, val)
+		assert.True(mr.Exists("test:a"))
+		assert.Equal(time.Second, mr.TTL("test:a"))
+		assert.Equal(time.Duration(0), mr.TTL("test:b"))
+
+		mr.FastForward(2 * time.Second)
+		_, err = cache.Get("a")
+		assert.Equal(jwt.ErrCacheMiss, err)
+		_, err = cache.Get("b")
+		assert.Nil(err)
+	})
+
+	t.Run("should return redis errors", func(t *testing.T) {
+		assert := assert.New(t)
+
+		mr.Lpush("test:list", "a")
+		cache := cacheredis.New(client, "test:")
+		_, err := cache.Get("list")
+		assert.NotNil(err)
+		assert.NotEqual(jwt.ErrCacheMiss, err)
+	})
+}
@@ -0,0 +1,69 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+)
+
+// ClaimsValidator can be implemented by custom claims types used with VerifyInto,
+// so that domain validation (e.g. tenant is active) travels with the claims type.
+//
+//  type UserClaims struct {
+//  	UserID   string `json:"sub"`
+//  	TenantID string `json:"tenant"`
+//  }
+//
+//  func (c *UserClaims) Valid(ctx context.Context) error {
+//  	if !tenantActive(ctx, c.TenantID) {
+//  		return errors.New("tenant is inactive")
+//  	}
+//  	return nil
+//  }
+//
+type ClaimsValidator interface {
+	Valid(ctx context.Context) error
+}
+
+// VerifyInto verifies the token as Verify, and unmarshals the claims into v by json tags.
+// v should be a pointer. If v implements ClaimsValidator, its Valid method will be called
+// with ctx after unmarshaling, and the error will be returned as is.
+//
+//  claims := &UserClaims{}
+//  err := jwter.VerifyInto(ctx, token, claims)
+//
+func (j *JWT) VerifyInto(ctx context.Context, token string, v interface{}) error {
+	claims, err := j.Verify(token)
+	if err != nil {
+		return err
+	}
+	if err = ClaimsInto(claims, v); err != nil {
+		return newError(err)
+	}
+	if cv, ok := v.(ClaimsValidator); ok {
+		return cv.Valid(ctx)
+	}
+	return nil
+}
+
+// ClaimsInto unmarshals the claims into v by json tags, it is the reverse of ToClaims.
+// v should be a pointer, it is useful for claims from Verify, Decode or the gear context:
+//
+//  claims, _ := a.FromCtx(ctx)
+//  user := &UserClaims{}
+//  if err := jwt.ClaimsInto(claims, user); err != nil {
+//  	return err
+//  }
+//
+func ClaimsInto(claims josejwt.Claims, v interface{}) error {
+	buf, err := json.Marshal(map[string]interface{}(claims))
+	if err == nil {
+		err = json.Unmarshal(buf, v)
+	}
+	if err != nil {
+		return errors.New("claims can't be converted: " + err.Error())
+	}
+	return nil
+}
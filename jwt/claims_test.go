@@ -0,0 +1,66 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+type testClaims struct {
+	UserID string `json:"sub"`
+	Tenant string `json:"tenant"`
+}
+
+func (c *testClaims) Valid(ctx context.Context) error {
+	if c.Tenant == ctx.Value("inactive") {
+		return errors.New("tenant is inactive")
+	}
+	return nil
+}
+
+func TestClaims(t *testing.T) {
+	t.Run("VerifyInto", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"))
+		token, _ := jwter.Sign(josejwt.Claims{"sub": "123", "tenant": "abc"})
+
+		claims := &testClaims{}
+		assert.Nil(jwter.VerifyInto(context.Background(), token, claims))
+		assert.Equal("123", claims.UserID)
+		assert.Equal("abc", claims.Tenant)
+
+		ctx := context.WithValue(context.Background(), "inactive", "abc")
+		err := jwter.VerifyInto(ctx, token, &testClaims{})
+		assert.Equal("tenant is inactive", err.Error())
+
+		m := map[string]interface{}{}
+		assert.Nil(jwter.VerifyInto(ctx, token, &m))
+		assert.Equal("abc", m["tenant"])
+
+		assert.NotNil(jwter.VerifyInto(ctx, token[1:], claims))
+		assert.NotNil(jwter.VerifyInto(ctx, token, &[]string{}))
+	})
+	t.Run("ClaimsInto", func(t *testing.T) {
+		assert := assert.New(t)
+
+		claims := &testClaims{}
+		assert.Nil(ClaimsInto(josejwt.Claims{"sub": "123", "tenant": "abc", "exp": 1.5e9}, claims))
+		assert.Equal("123", claims.UserID)
+		assert.Equal("abc", claims.Tenant)
+
+		var exp struct {
+			Expiration int64 `json:"exp"`
+		}
+		assert.Nil(ClaimsInto(josejwt.Claims{"exp": 1.5e9}, &exp))
+		assert.Equal(int64(1500000000), exp.Expiration)
+
+		assert.Nil(ClaimsInto(nil, claims))
+		err := ClaimsInto(josejwt.Claims{"sub": 123}, &testClaims{})
+		assert.Equal("claims can't be converted: json: cannot unmarshal number into Go struct field testClaims.sub of type string", err.Error())
+		assert.NotNil(ClaimsInto(josejwt.Claims{"sub": "123"}, testClaims{}))
+	})
+}
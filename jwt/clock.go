@@ -0,0 +1,55 @@
+package jwt
+
+import (
+	"time"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+)
+
+// Clock provides the current time to Sign and Verify.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc is an adapter to allow the use of ordinary functions as Clock.
+type ClockFunc func() time.Time
+
+// Now implements the Clock interface.
+func (fn ClockFunc) Now() time.Time {
+	return fn()
+}
+
+// SetClock set a Clock to jwt, it is used instead of the system clock to set "iat" and "exp" in Sign,
+// and to check "exp", "nbf" and "iat" in Verify. It lets tests freeze time, and lets production inject
+// an NTP-adjusted clock source. Set nil to restore the system clock.
+//
+//  now := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+//  jwter.SetClock(jwt.ClockFunc(func() time.Time { return now }))
+//
+func (j *JWT) SetClock(clock Clock) {
+	j.clock = clock
+}
+
+// now returns the current time of the clock.
+func (j *JWT) now() time.Time {
+	if j.clock != nil {
+		return j.clock.Now()
+	}
+	return time.Now()
+}
+
+// validators returns the validators for the jose library. It checks "exp" and "nbf" with the system clock,
+// so the leeway is shifted by the offset of the clock to get the same result as checking with the clock.
+func (j *JWT) validators() []*josejwt.Validator {
+	if j.clock == nil {
+		return j.validator
+	}
+	offset := j.clock.Now().Sub(time.Now())
+	v := &josejwt.Validator{}
+	if len(j.validator) > 0 && j.validator[0] != nil {
+		*v = *j.validator[0]
+	}
+	v.EXP -= offset
+	v.NBF += offset
+	return []*josejwt.Validator{v}
+}
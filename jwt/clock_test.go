@@ -0,0 +1,80 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClock(t *testing.T) {
+	t.Run("should sign and verify with the clock", func(t *testing.T) {
+		assert := assert.New(t)
+
+		now := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+		jwter := New([]byte("key"))
+		jwter.SetClock(ClockFunc(func() time.Time { return now }))
+		token, err := jwter.Sign(josejwt.Claims{"sub": "alice"}, time.Hour)
+		assert.Nil(err)
+		claims, err := jwter.Verify(token)
+		assert.Nil(err)
+		iat, _ := claims.IssuedAt()
+		assert.Equal(now.Unix(), iat.Unix())
+		exp, _ := claims.Expiration()
+		assert.Equal(now.Add(time.Hour).Unix(), exp.Unix())
+
+		now = now.Add(2 * time.Hour)
+		_, err = jwter.Verify(token)
+		assert.Equal("token is expired", err.Error())
+
+		now = now.Add(-3 * time.Hour)
+		_, err = jwter.Verify(token)
+		assert.Nil(err)
+		jwter.SetValidator(&josejwt.Validator{NBF: time.Minute})
+		_, err = jwter.Verify(token)
+		assert.Equal("token used before issued", err.Error())
+
+		jwter.SetClock(nil)
+		_, err = jwter.Verify(token)
+		assert.Equal("token is expired", err.Error())
+	})
+
+	t.Run("should check nbf with the clock and leeway", func(t *testing.T) {
+		assert := assert.New(t)
+
+		now := time.Now().Add(-time.Hour)
+		jwter := New([]byte("key"))
+		jwter.SetClock(ClockFunc(func() time.Time { return now }))
+		token, _ := jwter.Sign(josejwt.Claims{"nbf": now.Add(time.Minute).Unix()}, time.Hour)
+		_, err := jwter.Verify(token)
+		assert.Equal("token is not yet valid", err.Error())
+
+		jwter.SetValidator(&josejwt.Validator{NBF: 2 * time.Minute})
+		_, err = jwter.Verify(token)
+		assert.Nil(err)
+
+		now = now.Add(time.Hour + time.Minute)
+		_, err = jwter.Verify(token)
+		assert.Equal("token is expired", err.Error())
+		jwter.SetValidator(&josejwt.Validator{EXP: 2 * time.Minute})
+		_, err = jwter.Verify(token)
+		assert.Nil(err)
+	})
+
+	t.Run("should check millisecond precision with the clock", func(t *testing.T) {
+		assert := assert.New(t)
+
+		now := time.Date(2018, 1, 1, 0, 0, 0, int(100*time.Millisecond), time.UTC)
+		jwter := New([]byte("key"))
+		jwter.SetMillisecondPrecision(true)
+		jwter.SetClock(ClockFunc(func() time.Time { return now }))
+		token, _ := jwter.Sign(josejwt.Claims{"sub": "alice"}, 500*time.Millisecond)
+		_, err := jwter.Verify(token)
+		assert.Nil(err)
+
+		now = now.Add(600 * time.Millisecond)
+		_, err = jwter.Verify(token)
+		assert.Equal("token is expired", err.Error())
+	})
+}
@@ -0,0 +1,81 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/SermoDigital/jose"
+	josecrypto "github.com/SermoDigital/jose/crypto"
+	josejws "github.com/SermoDigital/jose/jws"
+	josejwt "github.com/SermoDigital/jose/jwt"
+)
+
+// Codec marshals and unmarshals claims, such as jsoniter or segmentio/encoding,
+// they are API compatible with encoding/json:
+//
+//  var json = jsoniter.ConfigCompatibleWithStandardLibrary
+//  jwter.SetCodec(json)
+//
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// SetCodec set a Codec to marshal the claims payload in Sign, and to convert struct content to claims.
+// Tokens are still parsed by the jose library in Verify and Decode. Set nil to restore encoding/json.
+func (j *JWT) SetCodec(codec Codec) {
+	j.codec = codec
+}
+
+// toClaims converts content to claims as ToClaims, with the codec if set.
+// Claims content is copied, so that Sign never writes defaults into the caller's map.
+func (j *JWT) toClaims(content interface{}) (josejwt.Claims, error) {
+	switch content.(type) {
+	case josejwt.Claims, josejws.Claims, map[string]interface{}, nil:
+		claims, err := ToClaims(content)
+		if err != nil {
+			return nil, err
+		}
+		return copyClaims(claims), nil
+	}
+	if j.codec == nil {
+		return ToClaims(content)
+	}
+	buf, err := j.codec.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+	claims := josejwt.Claims{}
+	if err = j.codec.Unmarshal(buf, (*map[string]interface{})(&claims)); err != nil {
+		return nil, errors.New("content can't be converted to claims: " + err.Error())
+	}
+	if claims == nil {
+		claims = josejwt.Claims{}
+	}
+	return claims, nil
+}
+
+// signWithCodec creates a compact JWS as Sign, but the payload is marshaled by the codec.
+func signWithCodec(codec Codec, claims josejwt.Claims, method josecrypto.SigningMethod, key interface{}, kid string) (string, error) {
+	if k, ok := key.(KeyPair); ok {
+		key = k.PrivateKey
+	}
+	protected := map[string]string{"alg": method.Alg(), "typ": "JWT"}
+	if kid != "" {
+		protected["kid"] = kid
+	}
+	header, err := json.Marshal(protected)
+	if err != nil {
+		return "", err
+	}
+	payload, err := codec.Marshal(map[string]interface{}(claims))
+	if err != nil {
+		return "", err
+	}
+	raw := string(jose.Base64Encode(header)) + "." + string(jose.Base64Encode(payload))
+	sig, err := method.Sign([]byte(raw), key)
+	if err != nil {
+		return "", err
+	}
+	return raw + "." + string(jose.Base64Encode(sig)), nil
+}
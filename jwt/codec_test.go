@@ -0,0 +1,96 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	josecrypto "github.com/SermoDigital/jose/crypto"
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+type countingCodec struct {
+	marshal   int
+	unmarshal int
+	err       error
+}
+
+func (c *countingCodec) Marshal(v interface{}) ([]byte, error) {
+	c.marshal++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return json.Marshal(v)
+}
+
+func (c *countingCodec) Unmarshal(data []byte, v interface{}) error {
+	c.unmarshal++
+	return json.Unmarshal(data, v)
+}
+
+func TestCodec(t *testing.T) {
+	at := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("should sign with the codec", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key"))
+		jwter.SetJTIGenerator(nil)
+		expected, err := jwter.SignAt(at, josejwt.Claims{"sub": "user", "n": 1}, time.Hour)
+		assert.Nil(err)
+
+		codec := &countingCodec{}
+		jwter.SetCodec(codec)
+		token, err := jwter.SignAt(at, josejwt.Claims{"sub": "user", "n": 1}, time.Hour)
+		assert.Nil(err)
+		assert.Equal(expected, token)
+		assert.Equal(1, codec.marshal)
+		assert.Equal(0, codec.unmarshal)
+
+		token, err = jwter.Sign(&struct {
+			Sub string `json:"sub"`
+		}{"user"})
+		assert.Nil(err)
+		assert.Equal(3, codec.marshal)
+		assert.Equal(1, codec.unmarshal)
+		claims, err := jwter.Verify(token)
+		assert.Nil(err)
+		assert.Equal("user", claims.Get("sub"))
+
+		jwter.SetCodec(nil)
+		token, err = jwter.SignAt(at, josejwt.Claims{"sub": "user", "n": 1}, time.Hour)
+		assert.Nil(err)
+		assert.Equal(expected, token)
+		assert.Equal(3, codec.marshal)
+	})
+
+	t.Run("should return codec errors", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key"))
+		jwter.SetCodec(&countingCodec{err: errors.New("codec error")})
+		_, err := jwter.Sign(josejwt.Claims{"sub": "user"})
+		assert.Equal("codec error", err.Error())
+		_, err = jwter.Sign(&struct{}{})
+		assert.Equal("codec error", err.Error())
+	})
+
+	t.Run("should work with asymmetric signing", func(t *testing.T) {
+		assert := assert.New(t)
+
+		key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		jwter := New()
+		jwter.SetSigning(josecrypto.SigningMethodES256, KeyPair{PrivateKey: key, PublicKey: &key.PublicKey})
+		jwter.SetCodec(&countingCodec{})
+		token, err := jwter.Sign(josejwt.Claims{"sub": "user"})
+		assert.Nil(err)
+		claims, err := jwter.Verify(token)
+		assert.Nil(err)
+		assert.Equal("user", claims.Get("sub"))
+	})
+}
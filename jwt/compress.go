@@ -0,0 +1,95 @@
+package jwt
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+)
+
+// CompressedClaim is the claim name holding the DEFLATE compressed private claims.
+const CompressedClaim = "zip"
+
+// registered claims are never compressed, so they can be validated without decompression.
+var registeredClaims = map[string]bool{
+	"iss": true, "sub": true, "aud": true, "exp": true, "nbf": true, "iat": true, "jti": true,
+}
+
+// SetCompression enables DEFLATE compression of claims. When the JSON encoded claims exceed threshold
+// bytes, Sign compresses all private claims into claim "zip", registered claims are kept as is.
+// Verify decompresses claim "zip" transparently, so the verifying side should enable it too.
+// threshold <= 0 disables compression.
+//
+//  jwter.SetCompression(2048)
+//
+func (j *JWT) SetCompression(threshold int) {
+	j.compressThreshold = threshold
+}
+
+// compressClaims returns compressed claims if claims exceed the threshold, otherwise claims itself.
+func (j *JWT) compressClaims(claims josejwt.Claims) (josejwt.Claims, error) {
+	buf, err := json.Marshal(map[string]interface{}(claims))
+	if err != nil || len(buf) <= j.compressThreshold {
+		return claims, err
+	}
+
+	res := josejwt.Claims{}
+	private := map[string]interface{}{}
+	for key, val := range claims {
+		if registeredClaims[key] {
+			res[key] = val
+		} else {
+			private[key] = val
+		}
+	}
+	if buf, err = json.Marshal(private); err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	w, _ := flate.NewWriter(&b, flate.BestCompression)
+	if _, err = w.Write(buf); err == nil {
+		err = w.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	res.Set(CompressedClaim, base64.RawURLEncoding.EncodeToString(b.Bytes()))
+	return res, nil
+}
+
+// decompressClaims returns a copy of claims with claim "zip" expanded, the claims may be shared,
+// such as the claims of a reference token.
+func decompressClaims(claims josejwt.Claims) (josejwt.Claims, error) {
+	val, ok := claims.Get(CompressedClaim).(string)
+	if !ok {
+		return claims, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(val)
+	if err != nil {
+		return nil, errors.New(`claim "zip" is invalid: ` + err.Error())
+	}
+	buf, err := ioutil.ReadAll(flate.NewReader(bytes.NewReader(data)))
+	if err != nil {
+		return nil, errors.New(`claim "zip" is invalid: ` + err.Error())
+	}
+	private := map[string]interface{}{}
+	if err = json.Unmarshal(buf, &private); err != nil {
+		return nil, errors.New(`claim "zip" is invalid: ` + err.Error())
+	}
+	res := make(josejwt.Claims, len(claims)+len(private))
+	for key, val := range claims {
+		if key != CompressedClaim {
+			res[key] = val
+		}
+	}
+	for key, val := range private {
+		if !registeredClaims[key] {
+			res.Set(key, val)
+		}
+	}
+	return res, nil
+}
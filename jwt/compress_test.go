@@ -0,0 +1,97 @@
+package jwt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompress(t *testing.T) {
+	t.Run("SetCompression", func(t *testing.T) {
+		assert := assert.New(t)
+
+		permissions := make([]string, 100)
+		for i := range permissions {
+			permissions[i] = "orders:read"
+		}
+
+		jwter := New([]byte("key1"))
+		jwter.SetExpiresIn(time.Minute)
+		token1, _ := jwter.Sign(josejwt.Claims{"sub": "123", "permissions": permissions})
+
+		jwter.SetCompression(512)
+		token2, err := jwter.Sign(josejwt.Claims{"sub": "123", "permissions": permissions})
+		assert.Nil(err)
+		assert.True(len(token2) < len(token1)/2)
+
+		claims, _ := Decode(token2)
+		assert.Equal("123", claims.Get("sub"))
+		assert.True(claims.Has("exp"))
+		assert.False(claims.Has("permissions"))
+		assert.True(claims.Has(CompressedClaim))
+
+		claims, err = jwter.Verify(token2)
+		assert.Nil(err)
+		assert.Equal("123", claims.Get("sub"))
+		assert.Equal(100, len(claims.Get("permissions").([]interface{})))
+		assert.False(claims.Has(CompressedClaim))
+
+		token3, _ := jwter.Sign(josejwt.Claims{"sub": "123", "test": "OK"})
+		claims, _ = Decode(token3)
+		assert.Equal("OK", claims.Get("test"))
+		assert.False(claims.Has(CompressedClaim))
+
+		token4, _ := New([]byte("key1")).Sign(josejwt.Claims{CompressedClaim: "xxx"})
+		_, err = jwter.Verify(token4)
+		assert.NotNil(err)
+		assert.True(strings.Contains(err.Error(), "zip"))
+	})
+
+	t.Run("should not decompress claims in place", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"))
+		jwter.SetCompression(1)
+		token, _ := jwter.Sign(josejwt.Claims{"sub": "123", "test": "OK"})
+		claims, _ := Decode(token)
+		for i := 0; i < 2; i++ {
+			res, err := decompressClaims(claims)
+			assert.Nil(err)
+			assert.Equal("OK", res.Get("test"))
+			assert.True(claims.Has(CompressedClaim))
+			assert.False(claims.Has("test"))
+		}
+	})
+
+	t.Run("should validate decompressed claims", func(t *testing.T) {
+		assert := assert.New(t)
+
+		validator := &josejwt.Validator{Fn: func(claims josejwt.Claims) error {
+			if claims.Get("tenant") != "acme" {
+				return errors.New("invalid tenant")
+			}
+			return nil
+		}}
+		validator.SetClaim("test", "OK")
+		jwter := New([]byte("key1"))
+		jwter.SetCompression(1)
+		jwter.SetValidator(validator)
+		token, _ := jwter.Sign(josejwt.Claims{"test": "OK", "tenant": "acme"})
+		claims, err := jwter.Verify(token)
+		assert.Nil(err)
+		assert.Equal("acme", claims.Get("tenant"))
+
+		token, _ = jwter.Sign(josejwt.Claims{"test": "OK", "tenant": "other"})
+		_, err = jwter.Verify(token)
+		assert.Equal("invalid tenant", errors.Unwrap(err).Error())
+
+		jwter.SetStore(NewMemoryStore())
+		token, _ = jwter.Sign(josejwt.Claims{"test": "OK", "tenant": "acme"})
+		_, err = jwter.Verify(token)
+		assert.Nil(err)
+	})
+}
@@ -0,0 +1,128 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+)
+
+var errSessionInvalidated = errors.New("session has been invalidated")
+
+// ContextValidator validates verified claims against external state, such as the user's current
+// session epoch in a database. Unlike josejwt.Validator, it is called with a context for cancellation
+// and per-request caching, see AddContextValidator and WithLookupCache.
+type ContextValidator interface {
+	Validate(ctx context.Context, claims josejwt.Claims) error
+}
+
+// ContextValidatorFunc is an adapter to use a function as ContextValidator.
+type ContextValidatorFunc func(ctx context.Context, claims josejwt.Claims) error
+
+// Validate implements the ContextValidator interface.
+func (fn ContextValidatorFunc) Validate(ctx context.Context, claims josejwt.Claims) error {
+	return fn(ctx, claims)
+}
+
+// ContextVerifier is a TokenVerifier that verifies tokens with a context, *JWT implements it.
+type ContextVerifier interface {
+	TokenVerifier
+	VerifyContext(ctx context.Context, token string) (*Token, error)
+}
+
+var _ ContextVerifier = (*JWT)(nil)
+
+// AddContextValidator adds a ContextValidator to jwt, it will be called after the token and
+// its claims are verified. Verify and VerifyToken call it with context.Background(),
+// use VerifyContext to pass the request's context.
+func (j *JWT) AddContextValidator(v ContextValidator) {
+	if v == nil {
+		panic(errors.New("invalid context validator"))
+	}
+	j.ctxValidators = append(j.ctxValidators, v)
+}
+
+// lookupCacheKey is the context key of the per-request lookup cache.
+type lookupCacheKey struct{}
+
+type lookupCache struct {
+	mu      sync.Mutex
+	results map[interface{}]lookupResult
+}
+
+type lookupResult struct {
+	val interface{}
+	err error
+}
+
+// WithLookupCache returns a context with a lookup cache, so lookups by CachedLookup with the context
+// are done only once, such as when several tokens of the same user are verified in one request.
+// It should be called per request, auth middleware does it for every request.
+func WithLookupCache(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(lookupCacheKey{}).(*lookupCache); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, lookupCacheKey{}, &lookupCache{results: make(map[interface{}]lookupResult)})
+}
+
+// CachedLookup returns the cached result of key from the context's lookup cache, or calls fn
+// and caches its result. fn is called every time if the context has no lookup cache.
+func CachedLookup(ctx context.Context, key interface{}, fn func() (interface{}, error)) (interface{}, error) {
+	cache, ok := ctx.Value(lookupCacheKey{}).(*lookupCache)
+	if !ok {
+		return fn()
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if res, ok := cache.results[key]; ok {
+		return res.val, res.err
+	}
+	val, err := fn()
+	cache.results[key] = lookupResult{val, err}
+	return val, err
+}
+
+// SessionEpochValidator returns a ContextValidator that compares the numeric claim (such as "epoch")
+// with the subject's current session epoch by lookup, tokens with a lower epoch or without the claim are
+// rejected. Bump the epoch in the store to invalidate all sessions of a user without storing sessions.
+// Lookups are cached per request by CachedLookup.
+//
+//  jwter.AddContextValidator(jwt.SessionEpochValidator("epoch", func(ctx context.Context, sub string) (int64, error) {
+//  	return db.SessionEpoch(ctx, sub)
+//  }))
+//
+func SessionEpochValidator(claim string, lookup func(ctx context.Context, sub string) (int64, error)) ContextValidator {
+	if claim == "" || lookup == nil {
+		panic(errors.New("invalid session epoch validator arguments"))
+	}
+	return &sessionEpochValidator{claim: claim, lookup: lookup}
+}
+
+type sessionEpochValidator struct {
+	claim  string
+	lookup func(ctx context.Context, sub string) (int64, error)
+}
+
+type sessionEpochKey struct {
+	v   *sessionEpochValidator
+	sub string
+}
+
+func (v *sessionEpochValidator) Validate(ctx context.Context, claims josejwt.Claims) error {
+	sub, _ := claims.Subject()
+	epoch, ok := numericClaim(claims, v.claim)
+	if sub == "" || !ok {
+		return errSessionInvalidated
+	}
+	current, err := CachedLookup(ctx, sessionEpochKey{v, sub}, func() (interface{}, error) {
+		return v.lookup(ctx, sub)
+	})
+	if err != nil {
+		return err
+	}
+	if int64(epoch) < current.(int64) {
+		return errSessionInvalidated
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	josecrypto "github.com/SermoDigital/jose/crypto"
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextValidator(t *testing.T) {
+	t.Run("should validate with context", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"))
+		assert.Panics(func() {
+			jwter.AddContextValidator(nil)
+		})
+
+		type ctxKey struct{}
+		var seen interface{}
+		jwter.AddContextValidator(ContextValidatorFunc(func(ctx context.Context, claims josejwt.Claims) error {
+			seen = ctx.Value(ctxKey{})
+			if claims.Get("blocked") == true {
+				return errors.New("blocked")
+			}
+			return nil
+		}))
+
+		token, _ := jwter.Sign(josejwt.Claims{"sub": "alice"})
+		ctx := context.WithValue(context.Background(), ctxKey{}, "request")
+		tk, err := jwter.VerifyContext(ctx, token)
+		assert.Nil(err)
+		assert.Equal("alice", tk.Claims.Get("sub"))
+		assert.Equal("request", seen)
+
+		_, err = jwter.Verify(token)
+		assert.Nil(err)
+		assert.Nil(seen)
+
+		token, _ = jwter.Sign(josejwt.Claims{"sub": "alice", "blocked": true})
+		_, err = jwter.VerifyContext(ctx, token)
+		assert.Equal("blocked", err.Error())
+	})
+
+	t.Run("should cache lookups per request", func(t *testing.T) {
+		assert := assert.New(t)
+
+		calls := 0
+		fn := func() (interface{}, error) {
+			calls++
+			return calls, nil
+		}
+		val, _ := CachedLookup(context.Background(), "key", fn)
+		assert.Equal(1, val)
+		val, _ = CachedLookup(context.Background(), "key", fn)
+		assert.Equal(2, val)
+
+		ctx := WithLookupCache(context.Background())
+		assert.Equal(ctx, WithLookupCache(ctx))
+		val, _ = CachedLookup(ctx, "key", fn)
+		assert.Equal(3, val)
+		val, _ = CachedLookup(ctx, "key", fn)
+		assert.Equal(3, val)
+		val, _ = CachedLookup(ctx, "other", fn)
+		assert.Equal(4, val)
+	})
+
+	t.Run("SessionEpochValidator", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.Panics(func() {
+			SessionEpochValidator("", nil)
+		})
+
+		epochs := map[string]int64{"alice": 2}
+		lookups := 0
+		jwter := New([]byte("key1"))
+		jwter.AddContextValidator(SessionEpochValidator("epoch", func(ctx context.Context, sub string) (int64, error) {
+			lookups++
+			if sub == "bob" {
+				return 0, errors.New("database is unreachable")
+			}
+			return epochs[sub], nil
+		}))
+
+		current, _ := jwter.Sign(josejwt.Claims{"sub": "alice", "epoch": 2})
+		old, _ := jwter.Sign(josejwt.Claims{"sub": "alice", "epoch": 1})
+		noEpoch, _ := jwter.Sign(josejwt.Claims{"sub": "alice"})
+		bob, _ := jwter.Sign(josejwt.Claims{"sub": "bob", "epoch": 1})
+
+		ctx := WithLookupCache(context.Background())
+		_, err := jwter.VerifyContext(ctx, current)
+		assert.Nil(err)
+		_, err = jwter.VerifyContext(ctx, old)
+		assert.Contains(err.Error(), errSessionInvalidated.Error())
+		assert.Equal(1, lookups)
+		_, err = jwter.VerifyContext(ctx, noEpoch)
+		assert.Contains(err.Error(), errSessionInvalidated.Error())
+		_, err = jwter.VerifyContext(ctx, bob)
+		assert.Contains(err.Error(), "database is unreachable")
+
+		epochs["alice"] = 3
+		_, err = jwter.Verify(current)
+		assert.Contains(err.Error(), errSessionInvalidated.Error())
+		assert.Equal(3, lookups)
+	})
+
+	t.Run("should abandon verification when the context is done", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"))
+		token, _ := jwter.Sign(josejwt.Claims{"sub": "alice"})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := jwter.VerifyContext(ctx, token)
+		assert.Equal("context canceled", err.Error())
+		_, err = verifyWithKeys(ctx, nil, jwter.method, jwter.keys)
+		assert.Equal(context.Canceled, err)
+
+		release := make(chan struct{})
+		defer close(release)
+		source := NewLazyKeys(func() ([]interface{}, error) {
+			<-release
+			return []interface{}{[]byte("key1")}, nil
+		}, time.Second)
+		jwter.SetKeySource(josecrypto.SigningMethodHS256, source)
+		ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		_, err = jwter.VerifyContext(ctx, token)
+		assert.Equal("context deadline exceeded", err.Error())
+		assert.True(time.Since(start) < time.Second)
+	})
+}
@@ -0,0 +1,87 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/hkdf"
+)
+
+// DeriveKey derives a keyLen bytes HMAC key from a secret with HKDF-SHA256 (RFC 5869).
+// It is suitable for high-entropy secrets, the info can be used to derive different keys from one secret.
+// Use 32, 48 and 64 bytes for HS256, HS384 and HS512 respectively.
+//
+//  jwter := jwt.New(jwt.DeriveKey([]byte(os.Getenv("JWT_SECRET")), nil, "access-token", 32))
+//
+func DeriveKey(secret, salt []byte, info string, keyLen int) []byte {
+	key := make([]byte, keyLen)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, []byte(info)), key); err != nil {
+		panic(err) // only if keyLen is too large
+	}
+	return key
+}
+
+// Argon2Params represents the Argon2id parameters and salt for deriving a key from a passphrase.
+// They should be stored (see String method) so that the same key can be derived again.
+type Argon2Params struct {
+	Salt    []byte
+	Time    uint32 // number of passes
+	Memory  uint32 // memory in KiB
+	Threads uint8
+	KeyLen  uint32
+}
+
+// NewArgon2Params returns Argon2Params with a random 16 bytes salt and the recommended
+// parameters of RFC 9106 for memory-constrained environments: t=3, m=64 MiB, p=4, and 32 bytes key.
+func NewArgon2Params() (*Argon2Params, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return &Argon2Params{Salt: salt, Time: 3, Memory: 64 * 1024, Threads: 4, KeyLen: 32}, nil
+}
+
+// ParseArgon2Params parses Argon2Params from the string encoded by String method.
+func ParseArgon2Params(s string) (*Argon2Params, error) {
+	var version int
+	var salt string
+	p := &Argon2Params{}
+	_, err := fmt.Sscanf(s, "$argon2id$v=%d$m=%d,t=%d,p=%d,l=%d$%s", &version, &p.Memory, &p.Time, &p.Threads, &p.KeyLen, &salt)
+	if err != nil {
+		return nil, errors.New("invalid argon2 params: " + err.Error())
+	}
+	if version != argon2.Version {
+		return nil, fmt.Errorf("unsupported argon2 version: %d", version)
+	}
+	if p.Salt, err = base64.RawStdEncoding.DecodeString(salt); err != nil {
+		return nil, errors.New("invalid argon2 salt: " + err.Error())
+	}
+	return p, nil
+}
+
+// String encodes Argon2Params as "$argon2id$v=19$m=65536,t=3,p=4,l=32$<base64 salt>",
+// it is the PHC string format without the hash.
+func (p *Argon2Params) String() string {
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d,l=%d$%s", argon2.Version, p.Memory, p.Time, p.Threads, p.KeyLen,
+		base64.RawStdEncoding.EncodeToString(p.Salt))
+}
+
+// DeriveKeyFromPassphrase derives a HMAC key from a human-managed passphrase with Argon2id,
+// instead of using a short ASCII passphrase directly as the key.
+//
+//  params, _ := jwt.ParseArgon2Params(os.Getenv("JWT_KEY_PARAMS"))
+//  key, err := jwt.DeriveKeyFromPassphrase(os.Getenv("JWT_PASSPHRASE"), params)
+//  jwter := jwt.New(key)
+//
+func DeriveKeyFromPassphrase(passphrase string, params *Argon2Params) ([]byte, error) {
+	if params == nil || len(params.Salt) == 0 || params.Time < 1 || params.Memory < 8*uint32(params.Threads) ||
+		params.Threads < 1 || params.KeyLen < 1 {
+		return nil, errors.New("invalid argon2 params")
+	}
+	return argon2.IDKey([]byte(passphrase), params.Salt, params.Time, params.Memory, params.Threads, params.KeyLen), nil
+}
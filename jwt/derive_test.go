@@ -0,0 +1,63 @@
+package jwt
+
+import (
+	"testing"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDerive(t *testing.T) {
+	t.Run("DeriveKey", func(t *testing.T) {
+		assert := assert.New(t)
+
+		key1 := DeriveKey([]byte("secret"), nil, "access-token", 32)
+		assert.Equal(32, len(key1))
+		assert.Equal(key1, DeriveKey([]byte("secret"), nil, "access-token", 32))
+		assert.NotEqual(key1, DeriveKey([]byte("secret"), nil, "refresh-token", 32))
+		assert.NotEqual(key1, DeriveKey([]byte("secret"), []byte("salt"), "access-token", 32))
+		assert.Equal(64, len(DeriveKey([]byte("secret"), nil, "", 64)))
+
+		token, _ := New(key1).Sign(josejwt.Claims{"test": "OK"})
+		_, err := New(DeriveKey([]byte("secret"), nil, "access-token", 32)).Verify(token)
+		assert.Nil(err)
+	})
+
+	t.Run("DeriveKeyFromPassphrase", func(t *testing.T) {
+		assert := assert.New(t)
+
+		params, err := NewArgon2Params()
+		assert.Nil(err)
+		params.Memory = 1024
+		str := params.String()
+		assert.Contains(str, "$argon2id$v=19$m=1024,t=3,p=4,l=32$")
+
+		params2, err := ParseArgon2Params(str)
+		assert.Nil(err)
+		assert.Equal(params, params2)
+
+		key, err := DeriveKeyFromPassphrase("my passphrase", params)
+		assert.Nil(err)
+		assert.Equal(32, len(key))
+		key2, _ := DeriveKeyFromPassphrase("my passphrase", params2)
+		assert.Equal(key, key2)
+		key2, _ = DeriveKeyFromPassphrase("my passphrase2", params2)
+		assert.NotEqual(key, key2)
+		params2.Salt = []byte("another salt")
+		key2, _ = DeriveKeyFromPassphrase("my passphrase", params2)
+		assert.NotEqual(key, key2)
+
+		params2.Threads = 0
+		_, err = DeriveKeyFromPassphrase("my passphrase", params2)
+		assert.NotNil(err)
+		_, err = DeriveKeyFromPassphrase("my passphrase", nil)
+		assert.NotNil(err)
+
+		_, err = ParseArgon2Params("$scrypt$n=1024,r=8,p=1,l=32$abc")
+		assert.NotNil(err)
+		_, err = ParseArgon2Params("$argon2id$v=16$m=1024,t=3,p=4,l=32$abc")
+		assert.NotNil(err)
+		_, err = ParseArgon2Params("$argon2id$v=19$m=1024,t=3,p=4,l=32$!!!")
+		assert.NotNil(err)
+	})
+}
@@ -0,0 +1,79 @@
+package jwt
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/SermoDigital/jose"
+)
+
+// SetAllowDuplicateClaims allows tokens whose header or payload JSON contains duplicate keys.
+// They are rejected by default, because different JSON parsers may see different values
+// for a duplicate key, which is a known smuggling vector. Only enable it for legacy interop.
+func (j *JWT) SetAllowDuplicateClaims(allow bool) {
+	j.allowDuplicates = allow
+}
+
+// duplicateKeyError is returned when a JSON object contains duplicate keys.
+type duplicateKeyError string
+
+func (e duplicateKeyError) Error() string {
+	return "duplicate key in token: " + string(e)
+}
+
+// checkDuplicateKeys checks the header and payload of a compact serialized token.
+// Malformed tokens are ignored here, the parser will report them.
+func checkDuplicateKeys(token string) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil
+	}
+	for _, part := range parts[:2] {
+		buf, err := jose.Base64Decode([]byte(part))
+		if err != nil {
+			return nil
+		}
+		if err = checkJSONValue(json.NewDecoder(bytes.NewReader(buf))); err != nil {
+			if _, ok := err.(duplicateKeyError); ok {
+				return err
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// checkJSONValue reads a JSON value from dec and checks objects in it recursively.
+func checkJSONValue(dec *json.Decoder) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok {
+	case json.Delim('{'):
+		keys := make(map[string]struct{})
+		for dec.More() {
+			if tok, err = dec.Token(); err != nil {
+				return err
+			}
+			key, _ := tok.(string)
+			if _, ok := keys[key]; ok {
+				return duplicateKeyError(key)
+			}
+			keys[key] = struct{}{}
+			if err = checkJSONValue(dec); err != nil {
+				return err
+			}
+		}
+		_, err = dec.Token()
+	case json.Delim('['):
+		for dec.More() {
+			if err = checkJSONValue(dec); err != nil {
+				return err
+			}
+		}
+		_, err = dec.Token()
+	}
+	return err
+}
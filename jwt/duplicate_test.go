@@ -0,0 +1,66 @@
+package jwt
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	josecrypto "github.com/SermoDigital/jose/crypto"
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+// signRaw signs a raw JSON payload with HS256, bypassing claims marshaling.
+func signRaw(key []byte, payload string) string {
+	return signRawWithHeader(key, `{"alg":"HS256","typ":"JWT"}`, payload)
+}
+
+// signRawWithHeader signs a raw JSON header and payload with HS256.
+func signRawWithHeader(key []byte, header, payload string) string {
+	enc := base64.RawURLEncoding
+	input := enc.EncodeToString([]byte(header)) + "." + enc.EncodeToString([]byte(payload))
+	sig, _ := josecrypto.SigningMethodHS256.Sign([]byte(input), key)
+	return input + "." + enc.EncodeToString(sig)
+}
+
+func TestDuplicateClaims(t *testing.T) {
+	t.Run("should reject duplicate keys", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"))
+		token := signRaw([]byte("key1"), `{"sub":"alice","sub":"admin"}`)
+		_, err := jwter.Verify(token)
+		assert.Contains(err.Error(), `duplicate key in token: sub`)
+		_, err = jwter.Decode(token)
+		assert.NotNil(err)
+
+		token = signRaw([]byte("key1"), `{"sub":"alice","ext":[{"role":"a","role":"b"}]}`)
+		_, err = jwter.Verify(token)
+		assert.Contains(err.Error(), `duplicate key in token: role`)
+
+		token = signRaw([]byte("key1"), `{"sub":"alice","ext":{"sub":"admin"}}`)
+		claims, err := jwter.Verify(token)
+		assert.Nil(err)
+		assert.Equal("alice", claims.Get("sub"))
+
+		_, err = jwter.Verify(strings.Repeat("x", 10))
+		assert.NotNil(err)
+	})
+
+	t.Run("should allow duplicate keys in lenient mode", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"))
+		jwter.SetAllowDuplicateClaims(true)
+		token := signRaw([]byte("key1"), `{"sub":"alice","sub":"admin"}`)
+		claims, err := jwter.Verify(token)
+		assert.Nil(err)
+		assert.Equal("admin", claims.Get("sub"))
+		_, err = jwter.Decode(token)
+		assert.Nil(err)
+
+		token, _ = jwter.Sign(josejwt.Claims{"sub": "alice"})
+		_, err = jwter.Verify(token)
+		assert.Nil(err)
+	})
+}
@@ -0,0 +1,94 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+
+	josecrypto "github.com/SermoDigital/jose/crypto"
+	josejws "github.com/SermoDigital/jose/jws"
+	xed25519 "golang.org/x/crypto/ed25519"
+)
+
+// ErrEdDSAVerification is returned when an EdDSA signature is invalid.
+var ErrEdDSAVerification = errors.New("eddsa: verification error")
+
+// SigningMethodEdDSA implements EdDSA with Ed25519 keys (RFC 8037), tokens are signed with "alg": "EdDSA".
+// It accepts ed25519.PrivateKey and ed25519.PublicKey of crypto/ed25519 or golang.org/x/crypto/ed25519,
+// or a KeyPair of them. A private key can be used to verify as well.
+//
+//  public, private, _ := ed25519.GenerateKey(rand.Reader)
+//  jwter.SetSigning(jwt.SigningMethodEdDSA, jwt.KeyPair{PrivateKey: private, PublicKey: public})
+//
+var SigningMethodEdDSA josecrypto.SigningMethod = &signingMethodEdDSA{}
+
+type signingMethodEdDSA struct{}
+
+func init() {
+	josejws.RegisterSigningMethod(SigningMethodEdDSA)
+}
+
+// Alg implements the SigningMethod interface.
+func (m *signingMethodEdDSA) Alg() string { return "EdDSA" }
+
+// Verify implements the SigningMethod interface.
+func (m *signingMethodEdDSA) Verify(data []byte, signature josecrypto.Signature, key interface{}) error {
+	public, ok := edPublicKey(key)
+	if !ok {
+		if private, ok := edPrivateKey(key); ok {
+			public, _ = private.Public().(ed25519.PublicKey)
+		}
+	}
+	if public == nil {
+		return josecrypto.ErrInvalidKey
+	}
+	if !ed25519.Verify(public, data, signature) {
+		return ErrEdDSAVerification
+	}
+	return nil
+}
+
+// Sign implements the SigningMethod interface.
+func (m *signingMethodEdDSA) Sign(data []byte, key interface{}) (josecrypto.Signature, error) {
+	private, ok := edPrivateKey(key)
+	if !ok {
+		return nil, josecrypto.ErrInvalidKey
+	}
+	return josecrypto.Signature(ed25519.Sign(private, data)), nil
+}
+
+// Hasher implements the SigningMethod interface, EdDSA doesn't pre-hash the data.
+func (m *signingMethodEdDSA) Hasher() crypto.Hash {
+	return crypto.Hash(0)
+}
+
+// MarshalJSON returns the JSON representation of the "alg".
+func (m *signingMethodEdDSA) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.Alg())
+}
+
+func edPrivateKey(key interface{}) (ed25519.PrivateKey, bool) {
+	var k []byte
+	switch v := key.(type) {
+	case ed25519.PrivateKey:
+		k = v
+	case xed25519.PrivateKey:
+		k = v
+	}
+	return ed25519.PrivateKey(k), len(k) == ed25519.PrivateKeySize
+}
+
+func edPublicKey(key interface{}) (ed25519.PublicKey, bool) {
+	var k []byte
+	switch v := key.(type) {
+	case ed25519.PublicKey:
+		k = v
+	case xed25519.PublicKey:
+		k = v
+	}
+	if len(k) != ed25519.PublicKeySize {
+		return nil, false
+	}
+	return ed25519.PublicKey(k), true
+}
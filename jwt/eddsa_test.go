@@ -0,0 +1,94 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/SermoDigital/jose"
+	josecrypto "github.com/SermoDigital/jose/crypto"
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+	xed25519 "golang.org/x/crypto/ed25519"
+)
+
+func TestEdDSA(t *testing.T) {
+	public, private, _ := ed25519.GenerateKey(rand.Reader)
+
+	t.Run("should sign and verify with EdDSA", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New()
+		jwter.SetSigning(SigningMethodEdDSA, KeyPair{PrivateKey: private, PublicKey: public})
+		token, err := jwter.Sign(josejwt.Claims{"sub": "alice"})
+		assert.Nil(err)
+		tk, err := jwter.VerifyToken(token)
+		assert.Nil(err)
+		assert.Equal("EdDSA", tk.Header.Get("alg"))
+		assert.Equal("alice", tk.Claims.Get("sub"))
+
+		verifier := New()
+		verifier.SetSigning(SigningMethodEdDSA, public)
+		_, err = verifier.Verify(token)
+		assert.Nil(err)
+		_, err = verifier.Sign(josejwt.Claims{"sub": "alice"})
+		assert.Equal(josecrypto.ErrInvalidKey, err)
+
+		other, _, _ := ed25519.GenerateKey(rand.Reader)
+		verifier.SetSigning(SigningMethodEdDSA, other)
+		_, err = verifier.Verify(token)
+		assert.Equal("eddsa: verification error", err.Error())
+
+		// a private key can sign and verify
+		jwter.SetSigning(SigningMethodEdDSA, private)
+		token, err = jwter.Sign(josejwt.Claims{"sub": "alice"})
+		assert.Nil(err)
+		_, err = jwter.Verify(token)
+		assert.Nil(err)
+	})
+
+	t.Run("should accept golang.org/x/crypto/ed25519 keys", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New()
+		jwter.SetSigning(SigningMethodEdDSA, KeyPair{
+			PrivateKey: xed25519.PrivateKey(private),
+			PublicKey:  xed25519.PublicKey(public),
+		})
+		token, err := jwter.Sign(josejwt.Claims{"sub": "alice"})
+		assert.Nil(err)
+		verifier := New()
+		verifier.SetSigning(SigningMethodEdDSA, public)
+		_, err = verifier.Verify(token)
+		assert.Nil(err)
+	})
+
+	t.Run("should check keys", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.Nil(CheckKey(SigningMethodEdDSA, public))
+		assert.Nil(CheckKey(SigningMethodEdDSA, private))
+		assert.Nil(CheckKey(SigningMethodEdDSA, KeyPair{PublicKey: public}))
+		assert.Equal("EdDSA requires KeyPair or ed25519.PublicKey, got []uint8",
+			CheckKey(SigningMethodEdDSA, []byte("key")).Error())
+		assert.Equal("EdDSA requires ed25519.PrivateKey, got ed25519.PublicKey",
+			CheckKey(SigningMethodEdDSA, KeyPair{PrivateKey: public, PublicKey: public}).Error())
+		assert.NotNil(CheckKey(SigningMethodEdDSA, ed25519.PublicKey([]byte("short"))))
+	})
+
+	t.Run("should parse OKP JWK", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwk := &jsonWebKey{Kty: "OKP", Crv: "Ed25519", X: string(jose.Base64Encode(public))}
+		key, err := jwk.key()
+		assert.Nil(err)
+		assert.Equal(public, key)
+
+		jwk.Crv = "X25519"
+		_, err = jwk.key()
+		assert.Equal("unsupported OKP curve: X25519", err.Error())
+		jwk.Crv, jwk.X = "Ed25519", "AAAA"
+		_, err = jwk.key()
+		assert.Equal("invalid Ed25519 public key", err.Error())
+	})
+}
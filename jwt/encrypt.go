@@ -0,0 +1,120 @@
+package jwt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+)
+
+// EncryptClaim encrypts a claim value with AES-GCM, key should be 16, 24 or 32 bytes.
+// The value is marshaled to JSON before encryption, and the result is encoded by base64.RawURLEncoding.
+func EncryptClaim(key []byte, value interface{}) (string, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, plaintext, nil)), nil
+}
+
+// DecryptClaim decrypts a claim value encrypted by EncryptClaim.
+func DecryptClaim(key []byte, value string) (interface{}, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("encrypted claim is too short")
+	}
+	plaintext, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err = json.Unmarshal(plaintext, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// SetClaimsEncryption set a AES-GCM key and claim names to jwt. The values of these claims will be
+// encrypted by Sign and decrypted by Verify transparently, so they are not readable by the client.
+// It is lighter than JWE when only one or two claims (e.g. internal IDs) should be hidden.
+//
+//  jwter.SetClaimsEncryption([]byte("a 32 bytes long encryption key!!"), "internal_id")
+//
+func (j *JWT) SetClaimsEncryption(key []byte, names ...string) {
+	if _, err := newGCM(key); err != nil {
+		panic(err)
+	}
+	if len(names) == 0 {
+		panic(errors.New("invalid claim names"))
+	}
+	j.encryptKey = key
+	j.encryptNames = names
+}
+
+// encryptClaims returns a copy of claims with the configured claims encrypted.
+func (j *JWT) encryptClaims(claims josejwt.Claims) (josejwt.Claims, error) {
+	res := make(josejwt.Claims, len(claims))
+	for key, val := range claims {
+		res[key] = val
+	}
+	for _, name := range j.encryptNames {
+		if val, ok := res[name]; ok {
+			encrypted, err := EncryptClaim(j.encryptKey, val)
+			if err != nil {
+				return nil, err
+			}
+			res[name] = encrypted
+		}
+	}
+	return res, nil
+}
+
+// decryptClaims returns a copy of claims with the configured claims decrypted, the claims may be
+// shared, such as the claims of a reference token.
+func (j *JWT) decryptClaims(claims josejwt.Claims) (josejwt.Claims, error) {
+	res := make(josejwt.Claims, len(claims))
+	for key, val := range claims {
+		res[key] = val
+	}
+	for _, name := range j.encryptNames {
+		if val, ok := res[name]; ok {
+			str, ok := val.(string)
+			if !ok {
+				return nil, errors.New("claim \"" + name + "\" is not encrypted")
+			}
+			decrypted, err := DecryptClaim(j.encryptKey, str)
+			if err != nil {
+				return nil, errors.New("claim \"" + name + "\" can't be decrypted: " + err.Error())
+			}
+			res[name] = decrypted
+		}
+	}
+	return res, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
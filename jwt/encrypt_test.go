@@ -0,0 +1,106 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncrypt(t *testing.T) {
+	key := []byte("a 32 bytes long encryption key!!")
+
+	t.Run("EncryptClaim and DecryptClaim", func(t *testing.T) {
+		assert := assert.New(t)
+
+		encrypted, err := EncryptClaim(key, "123")
+		assert.Nil(err)
+		assert.NotEqual("123", encrypted)
+		val, err := DecryptClaim(key, encrypted)
+		assert.Nil(err)
+		assert.Equal("123", val)
+
+		encrypted, _ = EncryptClaim(key, map[string]interface{}{"id": 1})
+		val, _ = DecryptClaim(key, encrypted)
+		assert.Equal(map[string]interface{}{"id": float64(1)}, val)
+
+		_, err = EncryptClaim([]byte("short"), "123")
+		assert.NotNil(err)
+		_, err = DecryptClaim([]byte("another 32 bytes long secret key"), encrypted)
+		assert.NotNil(err)
+		_, err = DecryptClaim(key, "abc")
+		assert.NotNil(err)
+	})
+
+	t.Run("SetClaimsEncryption", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"))
+		assert.Panics(func() {
+			jwter.SetClaimsEncryption([]byte("short"), "internal_id")
+		})
+		assert.Panics(func() {
+			jwter.SetClaimsEncryption(key)
+		})
+		jwter.SetClaimsEncryption(key, "internal_id")
+
+		content := josejwt.Claims{"test": "OK", "internal_id": "123"}
+		token, err := jwter.Sign(content)
+		assert.Nil(err)
+		assert.Equal("123", content.Get("internal_id"))
+
+		claims, _ := Decode(token)
+		assert.NotEqual("123", claims.Get("internal_id"))
+		assert.Equal("OK", claims.Get("test"))
+
+		claims, err = jwter.Verify(token)
+		assert.Nil(err)
+		assert.Equal("123", claims.Get("internal_id"))
+
+		token, _ = New([]byte("key1")).Sign(josejwt.Claims{"internal_id": "123"})
+		_, err = jwter.Verify(token)
+		assert.NotNil(err)
+		token, _ = New([]byte("key1")).Sign(josejwt.Claims{"internal_id": 123})
+		_, err = jwter.Verify(token)
+		assert.NotNil(err)
+	})
+
+	t.Run("should not decrypt claims in place", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"))
+		jwter.SetClaimsEncryption(key, "internal_id")
+		encrypted, _ := EncryptClaim(key, "123")
+		claims := josejwt.Claims{"sub": "alice", "internal_id": encrypted}
+		for i := 0; i < 2; i++ {
+			res, err := jwter.decryptClaims(claims)
+			assert.Nil(err)
+			assert.Equal("123", res.Get("internal_id"))
+			assert.Equal(encrypted, claims.Get("internal_id"))
+		}
+	})
+
+	t.Run("should validate decrypted claims", func(t *testing.T) {
+		assert := assert.New(t)
+
+		validator := &josejwt.Validator{Fn: func(claims josejwt.Claims) error {
+			if claims.Get("internal_id") != "123" {
+				return errors.New("invalid internal_id")
+			}
+			return nil
+		}}
+		jwter := New([]byte("key1"))
+		jwter.SetClaimsEncryption(key, "internal_id")
+		jwter.SetCompression(1)
+		jwter.SetValidator(validator)
+		token, _ := jwter.Sign(josejwt.Claims{"internal_id": "123"})
+		claims, err := jwter.Verify(token)
+		assert.Nil(err)
+		assert.Equal("123", claims.Get("internal_id"))
+
+		token, _ = jwter.Sign(josejwt.Claims{"internal_id": "456"})
+		_, err = jwter.Verify(token)
+		assert.NotNil(err)
+	})
+}
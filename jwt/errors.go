@@ -0,0 +1,81 @@
+package jwt
+
+import (
+	"crypto/rsa"
+	"errors"
+
+	josecrypto "github.com/SermoDigital/jose/crypto"
+	josejwt "github.com/SermoDigital/jose/jwt"
+)
+
+// Errors of token verification, they can be checked by errors.Is with the errors returned by Verify.
+var (
+	// ErrTokenExpired is returned when the token's "exp" has passed, the client may refresh the token.
+	ErrTokenExpired = josejwt.ErrTokenIsExpired
+	// ErrTokenNotYetValid is returned when the token's "nbf" hasn't come.
+	ErrTokenNotYetValid = josejwt.ErrTokenNotYetValid
+	// ErrBadAudience is returned when the token's "aud" doesn't match.
+	ErrBadAudience = josejwt.ErrInvalidAUDClaim
+	// ErrBadIssuer is returned when the token's "iss" doesn't match.
+	ErrBadIssuer = josejwt.ErrInvalidISSClaim
+	// ErrBadSignature is returned when the token's signature is invalid with all the keys, it may be forged.
+	// The cause is the error of the signing method, such as "crypto/rsa: verification error".
+	ErrBadSignature = errors.New("bad signature")
+)
+
+// signatureErrors are the verification errors of signing methods.
+var signatureErrors = []error{
+	josecrypto.ErrSignatureInvalid,
+	josecrypto.ErrECDSAVerification,
+	rsa.ErrVerification,
+	ErrEdDSAVerification,
+}
+
+// Error is the error returned by Verify, it carries the HTTP status code (401) and the cause,
+// so that consumers can tell expired tokens from forged tokens:
+//
+//  _, err := jwter.Verify(token)
+//  if errors.Is(err, jwt.ErrTokenExpired) {
+//  	// trigger the refresh flow
+//  }
+//
+type Error struct {
+	Code int
+	Err  error
+}
+
+// newError returns a 401 Error with the cause.
+func newError(err error) *Error {
+	if e, ok := err.(*Error); ok {
+		return e
+	}
+	return &Error{Code: 401, Err: err}
+}
+
+// Error implements the error interface, it returns the message of the cause.
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Status returns the HTTP status code, it implements the gear.HTTPError interface.
+func (e *Error) Status() int {
+	return e.Code
+}
+
+// Unwrap returns the cause.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the error is ErrBadSignature for signature verification errors.
+func (e *Error) Is(target error) bool {
+	if target != ErrBadSignature {
+		return false
+	}
+	for _, err := range signatureErrors {
+		if e.Err == err {
+			return true
+		}
+	}
+	return false
+}
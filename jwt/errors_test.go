@@ -0,0 +1,69 @@
+package jwt
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+	"time"
+
+	josecrypto "github.com/SermoDigital/jose/crypto"
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrors(t *testing.T) {
+	t.Run("should support errors.Is and errors.As", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key"))
+		token, _ := jwter.SignAt(time.Now().Add(-2*time.Hour), josejwt.Claims{"sub": "alice"}, time.Hour)
+		_, err := jwter.Verify(token)
+		assert.True(errors.Is(err, ErrTokenExpired))
+		assert.False(errors.Is(err, ErrBadSignature))
+		assert.Equal("token is expired", err.Error())
+		var e *Error
+		assert.True(errors.As(err, &e))
+		assert.Equal(401, e.Status())
+
+		token, _ = jwter.Sign(josejwt.Claims{"nbf": time.Now().Add(time.Hour).Unix()})
+		_, err = jwter.Verify(token)
+		assert.True(errors.Is(err, ErrTokenNotYetValid))
+
+		token, _ = New([]byte("other key")).Sign(josejwt.Claims{"sub": "alice"})
+		_, err = jwter.Verify(token)
+		assert.True(errors.Is(err, ErrBadSignature))
+		assert.True(errors.Is(err, josecrypto.ErrSignatureInvalid))
+		assert.False(errors.Is(err, ErrTokenExpired))
+
+		token, _ = jwter.Sign(josejwt.Claims{"aud": "billing"})
+		_, err = jwter.VerifyForAudience(token, "orders")
+		assert.True(errors.Is(err, ErrBadAudience))
+
+		jwter.SetValidator(&josejwt.Validator{Expected: josejwt.Claims{"iss": "gear"}})
+		token, _ = jwter.Sign(josejwt.Claims{"iss": "other"})
+		_, err = jwter.Verify(token)
+		assert.True(errors.Is(err, ErrBadIssuer))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = jwter.VerifyContext(ctx, token)
+		assert.True(errors.Is(err, context.Canceled))
+	})
+
+	t.Run("should report bad signature of RSA", func(t *testing.T) {
+		assert := assert.New(t)
+
+		key1, _ := rsa.GenerateKey(rand.Reader, 2048)
+		key2, _ := rsa.GenerateKey(rand.Reader, 2048)
+		signer := New()
+		signer.SetSigning(josecrypto.SigningMethodRS256, KeyPair{PrivateKey: key1, PublicKey: &key1.PublicKey})
+		token, _ := signer.Sign(josejwt.Claims{"sub": "alice"})
+		verifier := New()
+		verifier.SetSigning(josecrypto.SigningMethodRS256, &key2.PublicKey)
+		_, err := verifier.Verify(token)
+		assert.True(errors.Is(err, ErrBadSignature))
+		assert.True(errors.Is(err, rsa.ErrVerification))
+	})
+}
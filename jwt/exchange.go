@@ -0,0 +1,172 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// ExchangeFunc requests a token for the audience and scopes from a remote issuer,
+// such as an OAuth2 client credentials or token exchange endpoint.
+type ExchangeFunc func(ctx context.Context, audience string, scopes []string) (*oauth2.Token, error)
+
+// ClientCredentialsExchange returns an ExchangeFunc that requests tokens with the OAuth2 client credentials
+// grant. The audience is sent as the "audience" parameter, scopes replace conf.Scopes.
+func ClientCredentialsExchange(conf *clientcredentials.Config) ExchangeFunc {
+	if conf == nil {
+		panic(errors.New("invalid client credentials config"))
+	}
+	return func(ctx context.Context, audience string, scopes []string) (*oauth2.Token, error) {
+		c := *conf
+		c.Scopes = scopes
+		c.EndpointParams = url.Values{}
+		for key, val := range conf.EndpointParams {
+			c.EndpointParams[key] = val
+		}
+		if audience != "" {
+			c.EndpointParams.Set("audience", audience)
+		}
+		return c.Token(ctx)
+	}
+}
+
+// ExchangeCache caches tokens issued by a remote issuer keyed by audience and scopes, and renews them
+// shortly before expiry, so high-fanout gateways don't request a fresh token per call.
+// Concurrent renewals of the same key are coalesced into one exchange.
+type ExchangeCache struct {
+	exchange    ExchangeFunc
+	renewBefore time.Duration
+	timeout     time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*exchangeEntry
+}
+
+type exchangeEntry struct {
+	token    *oauth2.Token
+	renewAt  time.Time
+	inflight *exchangeCall
+}
+
+type exchangeCall struct {
+	done  chan struct{}
+	token *oauth2.Token
+	err   error
+}
+
+// NewExchangeCache returns an ExchangeCache, tokens are renewed renewBefore their expiry.
+// Tokens without expiry are cached until the process exits.
+//
+//  cache := jwt.NewExchangeCache(jwt.ClientCredentialsExchange(&clientcredentials.Config{
+//  	ClientID:     "gateway",
+//  	ClientSecret: "secret",
+//  	TokenURL:     "https://auth.example.com/oauth/token",
+//  }), time.Minute)
+//  token, err := cache.Token(ctx, "https://billing.example.com", "invoices:read")
+//
+func NewExchangeCache(exchange ExchangeFunc, renewBefore time.Duration) *ExchangeCache {
+	if exchange == nil {
+		panic(errors.New("invalid exchange function"))
+	}
+	if renewBefore < 0 {
+		panic(errors.New("invalid exchange renewBefore"))
+	}
+	return &ExchangeCache{
+		exchange:    exchange,
+		renewBefore: renewBefore,
+		timeout:     10 * time.Second,
+		entries:     make(map[string]*exchangeEntry),
+	}
+}
+
+// SetTimeout set the timeout of exchanges, default to 10 seconds. It panics if timeout <= 0.
+func (c *ExchangeCache) SetTimeout(timeout time.Duration) *ExchangeCache {
+	if timeout <= 0 {
+		panic(errors.New("invalid exchange timeout"))
+	}
+	c.mu.Lock()
+	c.timeout = timeout
+	c.mu.Unlock()
+	return c
+}
+
+// Token returns the cached token for the audience and scopes, or exchanges a new one if it is about
+// to expire. While renewing, the current token is returned if it is still valid, otherwise it waits
+// for the exchange or ctx to be done. Renewals are not canceled by ctx, they time out instead, see SetTimeout.
+func (c *ExchangeCache) Token(ctx context.Context, audience string, scopes ...string) (*oauth2.Token, error) {
+	scopes = append([]string(nil), scopes...)
+	sort.Strings(scopes)
+	key := audience + " " + strings.Join(scopes, " ")
+
+	now := time.Now()
+	c.mu.Lock()
+	entry := c.entries[key]
+	if entry == nil {
+		entry = &exchangeEntry{}
+		c.entries[key] = entry
+	}
+	token := entry.token
+	if token != nil && (token.Expiry.IsZero() || now.Before(entry.renewAt)) {
+		c.mu.Unlock()
+		return token, nil
+	}
+	call := entry.inflight
+	if call == nil {
+		call = &exchangeCall{done: make(chan struct{})}
+		entry.inflight = call
+		go c.renew(entry, call, audience, scopes, c.timeout)
+	}
+	c.mu.Unlock()
+	if token != nil && now.Before(token.Expiry) {
+		return token, nil
+	}
+
+	select {
+	case <-call.done:
+		return call.token, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// TokenSource returns an oauth2.TokenSource for the audience and scopes backed by the cache.
+func (c *ExchangeCache) TokenSource(ctx context.Context, audience string, scopes ...string) oauth2.TokenSource {
+	return exchangeTokenSource{c, ctx, audience, scopes}
+}
+
+type exchangeTokenSource struct {
+	c        *ExchangeCache
+	ctx      context.Context
+	audience string
+	scopes   []string
+}
+
+func (s exchangeTokenSource) Token() (*oauth2.Token, error) {
+	return s.c.Token(s.ctx, s.audience, s.scopes...)
+}
+
+func (c *ExchangeCache) renew(entry *exchangeEntry, call *exchangeCall, audience string, scopes []string,
+	timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	call.token, call.err = c.exchange(ctx, audience, scopes)
+	cancel()
+	if call.err == nil && call.token == nil {
+		call.err = errors.New("no token exchanged")
+	}
+
+	c.mu.Lock()
+	if call.err == nil {
+		entry.token = call.token
+		entry.renewAt = call.token.Expiry.Add(-c.renewBefore)
+	}
+	entry.inflight = nil
+	c.mu.Unlock()
+	close(call.done)
+}
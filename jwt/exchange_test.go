@@ -0,0 +1,175 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+func TestExchangeCache(t *testing.T) {
+	t.Run("should cache tokens by audience and scopes", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.Panics(func() {
+			NewExchangeCache(nil, time.Minute)
+		})
+
+		var calls int32
+		cache := NewExchangeCache(func(ctx context.Context, audience string, scopes []string) (*oauth2.Token, error) {
+			n := atomic.AddInt32(&calls, 1)
+			time.Sleep(10 * time.Millisecond)
+			return &oauth2.Token{
+				AccessToken: audience + strconv.Itoa(int(n)),
+				Expiry:      time.Now().Add(time.Hour),
+			}, nil
+		}, time.Minute)
+
+		var wg sync.WaitGroup
+		tokens := make([]*oauth2.Token, 10)
+		for i := range tokens {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				if i%2 == 0 {
+					tokens[i], _ = cache.Token(context.Background(), "billing", "read", "write")
+				} else {
+					tokens[i], _ = cache.Token(context.Background(), "billing", "write", "read")
+				}
+			}(i)
+		}
+		wg.Wait()
+		assert.Equal(int32(1), atomic.LoadInt32(&calls))
+		for _, token := range tokens {
+			assert.Equal("billing1", token.AccessToken)
+		}
+
+		token, err := cache.Token(context.Background(), "billing", "read")
+		assert.Nil(err)
+		assert.Equal("billing2", token.AccessToken)
+		token, err = cache.TokenSource(context.Background(), "users").Token()
+		assert.Nil(err)
+		assert.Equal("users3", token.AccessToken)
+		assert.Equal(int32(3), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("should renew before expiry", func(t *testing.T) {
+		assert := assert.New(t)
+
+		var calls int32
+		cache := NewExchangeCache(func(ctx context.Context, audience string, scopes []string) (*oauth2.Token, error) {
+			n := atomic.AddInt32(&calls, 1)
+			return &oauth2.Token{
+				AccessToken: audience + strconv.Itoa(int(n)),
+				Expiry:      time.Now().Add(time.Second),
+			}, nil
+		}, 990*time.Millisecond)
+
+		token, err := cache.Token(context.Background(), "billing")
+		assert.Nil(err)
+		assert.Equal("billing1", token.AccessToken)
+
+		time.Sleep(20 * time.Millisecond)
+		token, err = cache.Token(context.Background(), "billing")
+		assert.Nil(err)
+		assert.Equal("billing1", token.AccessToken)
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(int32(2), atomic.LoadInt32(&calls))
+		token, err = cache.Token(context.Background(), "billing")
+		assert.Nil(err)
+		assert.Equal("billing2", token.AccessToken)
+	})
+
+	t.Run("should return error or ctx error", func(t *testing.T) {
+		assert := assert.New(t)
+
+		var failed int32 = 1
+		cache := NewExchangeCache(func(ctx context.Context, audience string, scopes []string) (*oauth2.Token, error) {
+			time.Sleep(20 * time.Millisecond)
+			if atomic.LoadInt32(&failed) == 1 {
+				return nil, errors.New("issuer unavailable")
+			}
+			return &oauth2.Token{AccessToken: "token"}, nil
+		}, time.Minute)
+
+		_, err := cache.Token(context.Background(), "billing")
+		assert.Equal("issuer unavailable", err.Error())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+		defer cancel()
+		_, err = cache.Token(ctx, "billing")
+		assert.Equal(context.DeadlineExceeded, err)
+
+		atomic.StoreInt32(&failed, 0)
+		time.Sleep(30 * time.Millisecond)
+		token, err := cache.Token(context.Background(), "billing")
+		assert.Nil(err)
+		assert.Equal("token", token.AccessToken)
+	})
+
+	t.Run("should time out renewals", func(t *testing.T) {
+		assert := assert.New(t)
+
+		cache := NewExchangeCache(func(ctx context.Context, audience string, scopes []string) (*oauth2.Token, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}, time.Minute)
+		assert.Panics(func() {
+			cache.SetTimeout(0)
+		})
+		cache.SetTimeout(10 * time.Millisecond)
+		_, err := cache.Token(context.Background(), "billing")
+		assert.Equal(context.DeadlineExceeded, err)
+	})
+
+	t.Run("ClientCredentialsExchange", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.Panics(func() {
+			ClientCredentialsExchange(nil)
+		})
+
+		var calls int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			r.ParseForm()
+			if r.Form.Get("grant_type") != "client_credentials" {
+				w.WriteHeader(400)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"access_token":"` + r.Form.Get("audience") + "|" + r.Form.Get("scope") + "|" +
+				r.Form.Get("tenant") + `","token_type":"Bearer","expires_in":3600}`))
+		}))
+		defer ts.Close()
+
+		conf := &clientcredentials.Config{
+			ClientID:       "gateway",
+			ClientSecret:   "secret",
+			TokenURL:       ts.URL,
+			Scopes:         []string{"ignored"},
+			EndpointParams: map[string][]string{"tenant": {"acme"}},
+		}
+		cache := NewExchangeCache(ClientCredentialsExchange(conf), time.Minute)
+		token, err := cache.Token(context.Background(), "billing", "write", "read")
+		assert.Nil(err)
+		assert.Equal("billing|read write|acme", token.AccessToken)
+		assert.True(token.Expiry.After(time.Now().Add(59 * time.Minute)))
+		assert.Equal([]string{"ignored"}, conf.Scopes)
+		assert.Equal("", conf.EndpointParams.Get("audience"))
+
+		token, err = cache.Token(context.Background(), "billing", "read", "write")
+		assert.Nil(err)
+		assert.Equal("billing|read write|acme", token.AccessToken)
+		assert.Equal(int32(1), atomic.LoadInt32(&calls))
+	})
+}
@@ -0,0 +1,177 @@
+package jwt
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Errors returned by FamilyStore.
+var (
+	ErrFamilyRevoked       = errors.New("refresh token family revoked")
+	ErrRefreshTokenReused  = errors.New("refresh token reused")
+	ErrRefreshTokenUnknown = errors.New("refresh token unknown")
+)
+
+// FamilyStore tracks families of rotated refresh tokens by their "jti". A family starts with the
+// refresh token issued at login, every rotation issues a child of the presented token and uses up the
+// presented one. If a used token is presented again, it was stolen or replayed: Use revokes the whole
+// family, so both the attacker and the victim have to log in again.
+//
+//  // on login
+//  err = store.Issue(family, jti, "", exp)
+//  // on refresh with the presented token
+//  if err = store.Use(family, jti); err != nil {
+//  	return err // ErrRefreshTokenReused, ErrFamilyRevoked or ErrRefreshTokenUnknown
+//  }
+//  err = store.Issue(family, newJTI, jti, newExp)
+//
+type FamilyStore interface {
+	// Issue records a new refresh token of the family, parent is the "jti" of the token it was rotated
+	// from, or "" for the first token of the family. The token can be dropped after exp.
+	Issue(family, jti, parent string, exp time.Time) error
+	// Use marks the refresh token as used. It returns ErrRefreshTokenReused (and revokes the family)
+	// if the token was used before, ErrFamilyRevoked if the family is revoked,
+	// or ErrRefreshTokenUnknown if the token wasn't issued or has expired.
+	Use(family, jti string) error
+	// RevokeFamily revokes all refresh tokens of the family, such as on logout.
+	RevokeFamily(family string) error
+	// IsRevoked reports whether the family is revoked.
+	IsRevoked(family string) (bool, error)
+	// Ancestry returns the "jti"s from the first token of the family to the token.
+	Ancestry(family, jti string) ([]string, error)
+}
+
+// MemoryFamilyStore is a in-memory FamilyStore implementation, it is suitable for single process deployments.
+// Families are dropped after all their tokens expired.
+type MemoryFamilyStore struct {
+	mu        sync.Mutex
+	families  map[string]*memoryFamily
+	lastSweep time.Time
+}
+
+type memoryFamily struct {
+	revoked   bool
+	expiresAt time.Time
+	tokens    map[string]*familyToken
+}
+
+type familyToken struct {
+	parent    string
+	used      bool
+	expiresAt time.Time
+}
+
+var _ FamilyStore = (*MemoryFamilyStore)(nil)
+
+// NewMemoryFamilyStore returns a MemoryFamilyStore instance.
+func NewMemoryFamilyStore() *MemoryFamilyStore {
+	return &MemoryFamilyStore{families: make(map[string]*memoryFamily), lastSweep: time.Now()}
+}
+
+// Issue implements the FamilyStore interface.
+func (s *MemoryFamilyStore) Issue(family, jti, parent string, exp time.Time) error {
+	if family == "" || jti == "" {
+		return errors.New("invalid refresh token family or jti")
+	}
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f := s.family(family, now)
+	if f == nil {
+		f = &memoryFamily{tokens: make(map[string]*familyToken)}
+		s.families[family] = f
+	}
+	if f.revoked {
+		return ErrFamilyRevoked
+	}
+	f.tokens[jti] = &familyToken{parent: parent, expiresAt: exp}
+	if exp.After(f.expiresAt) {
+		f.expiresAt = exp
+	}
+
+	if now.Sub(s.lastSweep) > time.Minute {
+		s.lastSweep = now
+		for key, f := range s.families {
+			if now.After(f.expiresAt) {
+				delete(s.families, key)
+			}
+		}
+	}
+	return nil
+}
+
+// Use implements the FamilyStore interface.
+func (s *MemoryFamilyStore) Use(family, jti string) error {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f := s.family(family, now)
+	if f == nil {
+		return ErrRefreshTokenUnknown
+	}
+	if f.revoked {
+		return ErrFamilyRevoked
+	}
+	t, ok := f.tokens[jti]
+	if !ok || now.After(t.expiresAt) {
+		return ErrRefreshTokenUnknown
+	}
+	if t.used {
+		f.revoked = true
+		return ErrRefreshTokenReused
+	}
+	t.used = true
+	return nil
+}
+
+// RevokeFamily implements the FamilyStore interface.
+func (s *MemoryFamilyStore) RevokeFamily(family string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if f := s.family(family, time.Now()); f != nil {
+		f.revoked = true
+	}
+	return nil
+}
+
+// IsRevoked implements the FamilyStore interface.
+func (s *MemoryFamilyStore) IsRevoked(family string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f := s.family(family, time.Now())
+	return f != nil && f.revoked, nil
+}
+
+// Ancestry implements the FamilyStore interface.
+func (s *MemoryFamilyStore) Ancestry(family, jti string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f := s.family(family, time.Now())
+	if f == nil {
+		return nil, ErrRefreshTokenUnknown
+	}
+	var ancestry []string
+	for jti != "" {
+		t, ok := f.tokens[jti]
+		if !ok || len(ancestry) > len(f.tokens) {
+			return nil, ErrRefreshTokenUnknown
+		}
+		ancestry = append([]string{jti}, ancestry...)
+		jti = t.parent
+	}
+	return ancestry, nil
+}
+
+// family returns the family if it exists and not expired, it should be called with mu held.
+func (s *MemoryFamilyStore) family(family string, now time.Time) *memoryFamily {
+	f, ok := s.families[family]
+	if !ok {
+		return nil
+	}
+	if now.After(f.expiresAt) {
+		delete(s.families, family)
+		return nil
+	}
+	return f
+}
@@ -0,0 +1,82 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryFamilyStore(t *testing.T) {
+	t.Run("should rotate refresh tokens", func(t *testing.T) {
+		assert := assert.New(t)
+
+		store := NewMemoryFamilyStore()
+		exp := time.Now().Add(time.Hour)
+		assert.NotNil(store.Issue("", "a", "", exp))
+		assert.NotNil(store.Issue("f1", "", "", exp))
+
+		assert.Nil(store.Issue("f1", "a", "", exp))
+		assert.Nil(store.Use("f1", "a"))
+		assert.Nil(store.Issue("f1", "b", "a", exp))
+		assert.Nil(store.Use("f1", "b"))
+		assert.Nil(store.Issue("f1", "c", "b", exp))
+
+		ancestry, err := store.Ancestry("f1", "c")
+		assert.Nil(err)
+		assert.Equal([]string{"a", "b", "c"}, ancestry)
+		_, err = store.Ancestry("f1", "x")
+		assert.Equal(ErrRefreshTokenUnknown, err)
+		_, err = store.Ancestry("f2", "a")
+		assert.Equal(ErrRefreshTokenUnknown, err)
+
+		assert.Equal(ErrRefreshTokenUnknown, store.Use("f1", "x"))
+		assert.Equal(ErrRefreshTokenUnknown, store.Use("f2", "a"))
+		revoked, err := store.IsRevoked("f1")
+		assert.Nil(err)
+		assert.False(revoked)
+	})
+
+	t.Run("should revoke the family on reuse", func(t *testing.T) {
+		assert := assert.New(t)
+
+		store := NewMemoryFamilyStore()
+		exp := time.Now().Add(time.Hour)
+		assert.Nil(store.Issue("f1", "a", "", exp))
+		assert.Nil(store.Use("f1", "a"))
+		assert.Nil(store.Issue("f1", "b", "a", exp))
+
+		assert.Equal(ErrRefreshTokenReused, store.Use("f1", "a"))
+		revoked, _ := store.IsRevoked("f1")
+		assert.True(revoked)
+		assert.Equal(ErrFamilyRevoked, store.Use("f1", "b"))
+		assert.Equal(ErrFamilyRevoked, store.Issue("f1", "c", "b", exp))
+
+		assert.Nil(store.Issue("f2", "a", "", exp))
+		assert.Nil(store.RevokeFamily("f2"))
+		assert.Equal(ErrFamilyRevoked, store.Use("f2", "a"))
+		assert.Nil(store.RevokeFamily("unknown"))
+		revoked, _ = store.IsRevoked("unknown")
+		assert.False(revoked)
+	})
+
+	t.Run("should expire tokens and families", func(t *testing.T) {
+		assert := assert.New(t)
+
+		store := NewMemoryFamilyStore()
+		assert.Nil(store.Issue("f1", "a", "", time.Now().Add(10*time.Millisecond)))
+		assert.Nil(store.Issue("f1", "b", "a", time.Now().Add(time.Hour)))
+		assert.Nil(store.Issue("f2", "a", "", time.Now().Add(10*time.Millisecond)))
+		time.Sleep(20 * time.Millisecond)
+
+		assert.Equal(ErrRefreshTokenUnknown, store.Use("f1", "a"))
+		assert.Nil(store.Use("f1", "b"))
+		assert.Equal(ErrRefreshTokenUnknown, store.Use("f2", "a"))
+
+		store.Issue("f3", "a", "", time.Now().Add(time.Millisecond))
+		time.Sleep(2 * time.Millisecond)
+		store.lastSweep = time.Now().Add(-2 * time.Minute)
+		store.Issue("f4", "a", "", time.Now().Add(time.Hour))
+		assert.Equal(2, len(store.families))
+	})
+}
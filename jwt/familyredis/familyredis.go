@@ -0,0 +1,146 @@
+// Package familyredis implements jwt.FamilyStore with Redis, so that refresh token rotation works
+// across all instances of a service. Every family is stored as a hash, which expires with its
+// last token. Issue and Use are atomic Lua scripts, so a refresh token can be used only once even if
+// it is presented to several instances at the same time.
+//
+//  client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+//  store := familyredis.New(client, "myapp:family:")
+//
+package familyredis
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/teambition/gear-auth/jwt"
+)
+
+// Store is a jwt.FamilyStore backed by Redis.
+type Store struct {
+	client redis.Cmdable
+	prefix string
+}
+
+var _ jwt.FamilyStore = (*Store)(nil)
+
+// New returns a Store with the Redis client, such as *redis.Client or *redis.ClusterClient.
+// Family keys are prefixed with prefix.
+func New(client redis.Cmdable, prefix string) *Store {
+	if client == nil {
+		panic(errors.New("invalid redis client"))
+	}
+	return &Store{client: client, prefix: prefix}
+}
+
+// issueScript stores the parent and exp (unix milliseconds) of the token,
+// and extends the TTL (milliseconds) of the family.
+var issueScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], 'revoked') == '1' then
+	return 'revoked'
+end
+redis.call('HSET', KEYS[1], 'p:' .. ARGV[1], ARGV[2])
+redis.call('HSET', KEYS[1], 'e:' .. ARGV[1], ARGV[3])
+if redis.call('PTTL', KEYS[1]) < tonumber(ARGV[4]) then
+	redis.call('PEXPIRE', KEYS[1], ARGV[4])
+end
+return 'ok'
+`)
+
+// useScript marks the token used, or revokes the family if it was used.
+var useScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 'unknown'
+end
+if redis.call('HGET', KEYS[1], 'revoked') == '1' then
+	return 'revoked'
+end
+local exp = redis.call('HGET', KEYS[1], 'e:' .. ARGV[1])
+if not exp or tonumber(exp) < tonumber(ARGV[2]) then
+	return 'unknown'
+end
+if redis.call('HSETNX', KEYS[1], 'u:' .. ARGV[1], '1') == 0 then
+	redis.call('HSET', KEYS[1], 'revoked', '1')
+	return 'reused'
+end
+return 'ok'
+`)
+
+// revokeScript revokes the family if it exists, so that no key without TTL is created.
+var revokeScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	redis.call('HSET', KEYS[1], 'revoked', '1')
+end
+return 'ok'
+`)
+
+// Issue implements the jwt.FamilyStore interface.
+func (s *Store) Issue(family, jti, parent string, exp time.Time) error {
+	if family == "" || jti == "" {
+		return errors.New("invalid refresh token family or jti")
+	}
+	ttl := unixMilli(exp) - unixMilli(time.Now())
+	if ttl <= 0 {
+		return nil
+	}
+	return s.run(issueScript, family, jti, parent, unixMilli(exp), ttl)
+}
+
+// Use implements the jwt.FamilyStore interface.
+func (s *Store) Use(family, jti string) error {
+	return s.run(useScript, family, jti, unixMilli(time.Now()))
+}
+
+// RevokeFamily implements the jwt.FamilyStore interface.
+func (s *Store) RevokeFamily(family string) error {
+	return s.run(revokeScript, family)
+}
+
+// IsRevoked implements the jwt.FamilyStore interface.
+func (s *Store) IsRevoked(family string) (bool, error) {
+	val, err := s.client.HGet(s.prefix+family, "revoked").Result()
+	if err == redis.Nil {
+		return false, nil
+	}
+	return val == "1", err
+}
+
+// Ancestry implements the jwt.FamilyStore interface.
+func (s *Store) Ancestry(family, jti string) ([]string, error) {
+	fields, err := s.client.HGetAll(s.prefix + family).Result()
+	if err != nil {
+		return nil, err
+	}
+	var ancestry []string
+	for jti != "" {
+		parent, ok := fields["p:"+jti]
+		if !ok || len(ancestry) > len(fields) {
+			return nil, jwt.ErrRefreshTokenUnknown
+		}
+		ancestry = append([]string{jti}, ancestry...)
+		jti = parent
+	}
+	return ancestry, nil
+}
+
+func (s *Store) run(script *redis.Script, family string, args ...interface{}) error {
+	res, err := script.Run(s.client, []string{s.prefix + family}, args...).String()
+	if err != nil {
+		return err
+	}
+	switch res {
+	case "revoked":
+		return jwt.ErrFamilyRevoked
+	case "reused":
+		return jwt.ErrRefreshTokenReused
+	case "unknown":
+		return jwt.ErrRefreshTokenUnknown
+	case "ok":
+		return nil
+	}
+	return errors.New("unexpected script result: " + res)
+}
+
+func unixMilli(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
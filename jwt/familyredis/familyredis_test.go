@@ -0,0 +1,93 @@
+package familyredis_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/go-redis/redis"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/teambition/gear-auth/jwt"
+	"github.com/teambition/gear-auth/jwt/familyredis"
+)
+
+func TestStore(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mr.Close()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	t.Run("should rotate refresh tokens", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.Panics(func() {
+			familyredis.New(nil, "")
+		})
+
+		store := familyredis.New(client, "family:")
+		exp := time.Now().Add(time.Hour)
+		assert.NotNil(store.Issue("", "a", "", exp))
+
+		assert.Nil(store.Issue("f1", "a", "", exp))
+		assert.Nil(store.Use("f1", "a"))
+		assert.Nil(store.Issue("f1", "b", "a", exp))
+		assert.Nil(store.Use("f1", "b"))
+		assert.Nil(store.Issue("f1", "c", "b", exp.Add(time.Hour)))
+		assert.True(mr.TTL("family:f1") > time.Hour)
+
+		ancestry, err := store.Ancestry("f1", "c")
+		assert.Nil(err)
+		assert.Equal([]string{"a", "b", "c"}, ancestry)
+		_, err = store.Ancestry("f1", "x")
+		assert.Equal(jwt.ErrRefreshTokenUnknown, err)
+
+		assert.Equal(jwt.ErrRefreshTokenUnknown, store.Use("f1", "x"))
+		assert.Equal(jwt.ErrRefreshTokenUnknown, store.Use("f2", "a"))
+		revoked, err := store.IsRevoked("f1")
+		assert.Nil(err)
+		assert.False(revoked)
+	})
+
+	t.Run("should revoke the family on reuse", func(t *testing.T) {
+		assert := assert.New(t)
+
+		store := familyredis.New(client, "family:")
+		exp := time.Now().Add(time.Hour)
+		assert.Nil(store.Issue("f3", "a", "", exp))
+		assert.Nil(store.Use("f3", "a"))
+		assert.Nil(store.Issue("f3", "b", "a", exp))
+
+		assert.Equal(jwt.ErrRefreshTokenReused, store.Use("f3", "a"))
+		revoked, err := store.IsRevoked("f3")
+		assert.Nil(err)
+		assert.True(revoked)
+		assert.Equal(jwt.ErrFamilyRevoked, store.Use("f3", "b"))
+		assert.Equal(jwt.ErrFamilyRevoked, store.Issue("f3", "c", "b", exp))
+
+		assert.Nil(store.Issue("f4", "a", "", exp))
+		assert.Nil(store.RevokeFamily("f4"))
+		assert.Equal(jwt.ErrFamilyRevoked, store.Use("f4", "a"))
+		assert.Nil(store.RevokeFamily("unknown"))
+		assert.False(mr.Exists("family:unknown"))
+		revoked, err = store.IsRevoked("unknown")
+		assert.Nil(err)
+		assert.False(revoked)
+	})
+
+	t.Run("should expire tokens and families", func(t *testing.T) {
+		assert := assert.New(t)
+
+		store := familyredis.New(client, "family:")
+		assert.Nil(store.Issue("f5", "a", "", time.Now().Add(-time.Second)))
+		assert.False(mr.Exists("family:f5"))
+		assert.Equal(jwt.ErrRefreshTokenUnknown, store.Use("f5", "a"))
+
+		assert.Nil(store.Issue("f6", "a", "", time.Now().Add(time.Minute)))
+		mr.FastForward(2 * time.Minute)
+		assert.Equal(jwt.ErrRefreshTokenUnknown, store.Use("f6", "a"))
+	})
+}
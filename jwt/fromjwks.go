@@ -0,0 +1,121 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	josecrypto "github.com/SermoDigital/jose/crypto"
+)
+
+// JWKSOption configures the JWKS of NewFromJWKS.
+type JWKSOption func(*jwksOptions)
+
+type jwksOptions struct {
+	ctx        context.Context
+	interval   time.Duration
+	minRefresh time.Duration
+	method     josecrypto.SigningMethod
+	client     *http.Client
+}
+
+// WithRefreshInterval sets the interval to refresh the key set in background, default to 1 hour.
+func WithRefreshInterval(interval time.Duration) JWKSOption {
+	if interval <= 0 {
+		panic(errors.New("invalid JWKS refresh interval"))
+	}
+	return func(o *jwksOptions) { o.interval = interval }
+}
+
+// WithMinRefreshInterval sets the minimum interval of refetching triggered by unknown "kid",
+// see JWKS.SetMinRefreshInterval.
+func WithMinRefreshInterval(interval time.Duration) JWKSOption {
+	if interval < 0 {
+		panic(errors.New("invalid JWKS refresh interval"))
+	}
+	return func(o *jwksOptions) { o.minRefresh = interval }
+}
+
+// WithJWKSMethod sets the signing method of tokens, default to RS256.
+func WithJWKSMethod(method josecrypto.SigningMethod) JWKSOption {
+	if method == nil {
+		panic(errors.New("invalid signing method"))
+	}
+	return func(o *jwksOptions) { o.method = method }
+}
+
+// WithJWKSClient sets the http.Client to fetch the key set, see JWKS.SetHTTPClient.
+func WithJWKSClient(client *http.Client) JWKSOption {
+	if client == nil {
+		panic(errors.New("invalid http client"))
+	}
+	return func(o *jwksOptions) { o.client = client }
+}
+
+// WithJWKSContext sets a context to stop refreshing in background when it is done, such as on shutdown.
+// Default to context.Background(), refreshing goes on for the lifetime of the process.
+func WithJWKSContext(ctx context.Context) JWKSOption {
+	if ctx == nil {
+		panic(errors.New("invalid context"))
+	}
+	return func(o *jwksOptions) { o.ctx = ctx }
+}
+
+// NewFromJWKS returns a verify-only JWT instance with keys from the JWK Set url of an identity provider.
+// The key set is refreshed in background, so requests don't wait for fetching, and it is refetched at once
+// (rate-limited) when a token's "kid" is unknown, see JWKS.
+//
+//  ctx, cancel := context.WithCancel(context.Background())
+//  defer cancel()
+//  jwter := jwt.NewFromJWKS("https://login.example.com/.well-known/jwks.json",
+//  	jwt.WithRefreshInterval(15*time.Minute), jwt.WithJWKSContext(ctx))
+//
+func NewFromJWKS(url string, opts ...JWKSOption) *JWT {
+	o := &jwksOptions{
+		ctx:        context.Background(),
+		interval:   time.Hour,
+		minRefresh: 30 * time.Second,
+		method:     josecrypto.SigningMethodRS256,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	// the TTL is longer than the interval, so refreshing in background keeps the key set fresh.
+	jwks := NewJWKS(url, 2*o.interval).SetMinRefreshInterval(o.minRefresh)
+	if o.client != nil {
+		jwks.SetHTTPClient(o.client)
+	}
+	jwks.StartRefresh(o.ctx, o.interval)
+	j := New()
+	j.SetKeySource(o.method, jwks)
+	return j
+}
+
+// StartRefresh fetches the key set at once and then every interval in background until the ctx is done.
+// Failures are ignored, the current key set keeps being used. The shared Cache is bypassed so that keys are
+// never staler than interval, and it is updated with the fetched key set for other instances, see SetCache.
+func (k *JWKS) StartRefresh(ctx context.Context, interval time.Duration) *JWKS {
+	if interval <= 0 {
+		panic(errors.New("invalid JWKS refresh interval"))
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			k.refresh()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return k
+}
+
+func (k *JWKS) refresh() {
+	k.fetchMu.Lock()
+	defer k.fetchMu.Unlock()
+	k.fetch(false)
+}
@@ -0,0 +1,75 @@
+package jwt
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	josecrypto "github.com/SermoDigital/jose/crypto"
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFromJWKS(t *testing.T) {
+	t.Run("should panic with invalid options", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.Panics(func() {
+			NewFromJWKS("")
+		})
+		assert.Panics(func() {
+			WithRefreshInterval(0)
+		})
+		assert.Panics(func() {
+			WithMinRefreshInterval(-time.Second)
+		})
+		assert.Panics(func() {
+			WithJWKSMethod(nil)
+		})
+		assert.Panics(func() {
+			WithJWKSClient(nil)
+		})
+		assert.Panics(func() {
+			WithJWKSContext(nil)
+		})
+	})
+
+	t.Run("should verify tokens and refresh in background", func(t *testing.T) {
+		assert := assert.New(t)
+
+		srv := newTestJWKSServer()
+		defer srv.Close()
+		key1 := srv.addKey("key1")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		jwter := NewFromJWKS(srv.URL,
+			WithJWKSMethod(josecrypto.SigningMethodES256),
+			WithRefreshInterval(20*time.Millisecond),
+			WithMinRefreshInterval(0),
+			WithJWKSClient(&http.Client{Timeout: time.Second}),
+			WithJWKSContext(ctx))
+
+		claims, err := jwter.Verify(signWithKID(key1, "key1", josejwt.Claims{"sub": "alice"}))
+		assert.Nil(err)
+		assert.Equal("alice", claims.Get("sub"))
+
+		deadline := time.Now().Add(time.Second)
+		for srv.fetchCount() < 3 && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+		assert.True(srv.fetchCount() >= 3)
+
+		// unknown kid is refetched on demand
+		key2 := srv.addKey("key2")
+		_, err = jwter.Verify(signWithKID(key2, "key2", josejwt.Claims{"sub": "bob"}))
+		assert.Nil(err)
+
+		cancel()
+		time.Sleep(30 * time.Millisecond)
+		count := srv.fetchCount()
+		time.Sleep(60 * time.Millisecond)
+		assert.Equal(count, srv.fetchCount())
+	})
+}
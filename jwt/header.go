@@ -0,0 +1,119 @@
+package jwt
+
+import (
+	"errors"
+	"net/url"
+
+	"github.com/SermoDigital/jose"
+	josejws "github.com/SermoDigital/jose/jws"
+	josejwt "github.com/SermoDigital/jose/jwt"
+)
+
+// registeredHeaders are the header parameters defined by RFC 7515, they must not be in "crit".
+var registeredHeaders = map[string]bool{
+	"alg": true, "jku": true, "jwk": true, "kid": true, "x5u": true, "x5c": true,
+	"x5t": true, "x5t#S256": true, "typ": true, "cty": true, "crit": true,
+}
+
+// RegisterCritical registers a handler for a critical header parameter extension (RFC 7515, Section 4.1.11).
+// Tokens listing a parameter in "crit" that isn't registered are rejected, and the handler is called
+// with the parameter value of verified tokens, it should return error if the value isn't acceptable.
+//
+//  jwter.RegisterCritical("b64", func(val interface{}) error {
+//  	if val != true {
+//  		return errors.New("unencoded payload is not supported")
+//  	}
+//  	return nil
+//  })
+//
+func (j *JWT) RegisterCritical(name string, handler func(val interface{}) error) {
+	if name == "" || registeredHeaders[name] || handler == nil {
+		panic(errors.New("invalid critical header parameter"))
+	}
+	if j.critical == nil {
+		j.critical = make(map[string]func(interface{}) error)
+	}
+	j.critical[name] = handler
+}
+
+// HeaderKeyPolicy is an opt-in policy for tokens carrying the "jku" header. Keys are never
+// resolved from token headers, tokens are always verified with the configured keys, the policy
+// only decides whether such tokens are acceptable.
+// Tokens carrying "jwk" or "jku" headers are rejected by default, since embedded keys are a classic
+// self-signing bypass. An embedded "jwk" is always rejected.
+type HeaderKeyPolicy struct {
+	// TrustedJKUHosts are the pinned hosts (with optional port) of acceptable "jku" URLs,
+	// only "https" URLs are accepted.
+	TrustedJKUHosts []string
+}
+
+// SetHeaderKeyPolicy set a HeaderKeyPolicy to jwt, see HeaderKeyPolicy. Set nil to restore the default.
+//
+//  jwter.SetHeaderKeyPolicy(&jwt.HeaderKeyPolicy{TrustedJKUHosts: []string{"login.example.com"}})
+//
+func (j *JWT) SetHeaderKeyPolicy(policy *HeaderKeyPolicy) {
+	j.headerKeyPolicy = policy
+}
+
+func (p *HeaderKeyPolicy) check(header jose.Protected) error {
+	if header.Has("jwk") {
+		return errors.New(`header "jwk" is not allowed`)
+	}
+	if !header.Has("jku") {
+		return nil
+	}
+	if p != nil {
+		if jku, ok := header.Get("jku").(string); ok {
+			if u, err := url.Parse(jku); err == nil && u.Scheme == "https" {
+				for _, host := range p.TrustedJKUHosts {
+					if u.Host == host {
+						return nil
+					}
+				}
+			}
+		}
+	}
+	return errors.New(`header "jku" is not trusted`)
+}
+
+// headerOf returns the protected header of a parsed token.
+func headerOf(jwtToken josejwt.JWT) jose.Protected {
+	if token, ok := jwtToken.(josejws.JWS); ok {
+		return token.Protected()
+	}
+	return nil
+}
+
+// checkHeader runs the header checks on a verified token.
+func (j *JWT) checkHeader(header jose.Protected) error {
+	if err := j.headerKeyPolicy.check(header); err != nil {
+		return err
+	}
+	return j.checkCritical(header)
+}
+
+func (j *JWT) checkCritical(header jose.Protected) error {
+	if !header.Has("crit") {
+		return nil
+	}
+	crit, ok := header.Get("crit").([]interface{})
+	if !ok || len(crit) == 0 {
+		return errInvalidCritHeader
+	}
+	for _, v := range crit {
+		name, ok := v.(string)
+		if !ok || registeredHeaders[name] || !header.Has(name) {
+			return errInvalidCritHeader
+		}
+		handler, ok := j.critical[name]
+		if !ok {
+			return errors.New("unsupported critical header parameter: " + name)
+		}
+		if err := handler(header.Get(name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var errInvalidCritHeader = errors.New(`header "crit" is invalid`)
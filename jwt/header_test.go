@@ -0,0 +1,95 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCriticalHeader(t *testing.T) {
+	t.Run("should reject unrecognized critical header parameters", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"))
+		token := signRawWithHeader([]byte("key1"), `{"alg":"HS256","crit":["exp"],"exp":1}`, `{"sub":"alice"}`)
+		_, err := jwter.Verify(token)
+		assert.Contains(err.Error(), "unsupported critical header parameter: exp")
+
+		for _, header := range []string{
+			`{"alg":"HS256","crit":[]}`,
+			`{"alg":"HS256","crit":"exp"}`,
+			`{"alg":"HS256","crit":["alg"]}`,
+			`{"alg":"HS256","crit":["exp"]}`,
+		} {
+			token = signRawWithHeader([]byte("key1"), header, `{"sub":"alice"}`)
+			_, err = jwter.Verify(token)
+			assert.Contains(err.Error(), `header "crit" is invalid`, header)
+		}
+
+		token = signRawWithHeader([]byte("key1"), `{"alg":"HS256","exp":1}`, `{"sub":"alice"}`)
+		_, err = jwter.Verify(token)
+		assert.Nil(err)
+	})
+
+	t.Run("RegisterCritical", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"))
+		assert.Panics(func() {
+			jwter.RegisterCritical("alg", func(val interface{}) error { return nil })
+		})
+		assert.Panics(func() {
+			jwter.RegisterCritical("exp", nil)
+		})
+		jwter.RegisterCritical("exp", func(val interface{}) error {
+			if val != float64(1) {
+				return errors.New("invalid exp header")
+			}
+			return nil
+		})
+
+		token := signRawWithHeader([]byte("key1"), `{"alg":"HS256","crit":["exp"],"exp":1}`, `{"sub":"alice"}`)
+		claims, err := jwter.Verify(token)
+		assert.Nil(err)
+		assert.Equal("alice", claims.Get("sub"))
+
+		token = signRawWithHeader([]byte("key1"), `{"alg":"HS256","crit":["exp"],"exp":2}`, `{"sub":"alice"}`)
+		_, err = jwter.Verify(token)
+		assert.Contains(err.Error(), "invalid exp header")
+
+		token = signRawWithHeader([]byte("key2"), `{"alg":"HS256","crit":["exp"],"exp":1}`, `{"sub":"alice"}`)
+		_, err = jwter.Verify(token)
+		assert.NotNil(err)
+	})
+
+	t.Run("HeaderKeyPolicy", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"))
+		token := signRawWithHeader([]byte("key1"), `{"alg":"HS256","jwk":{"kty":"oct","k":"a2V5MQ"}}`, `{"sub":"alice"}`)
+		_, err := jwter.Verify(token)
+		assert.Contains(err.Error(), `header "jwk" is not allowed`)
+
+		jkuToken := signRawWithHeader([]byte("key1"), `{"alg":"HS256","jku":"https://login.example.com/jwks"}`, `{"sub":"alice"}`)
+		_, err = jwter.Verify(jkuToken)
+		assert.Contains(err.Error(), `header "jku" is not trusted`)
+
+		jwter.SetHeaderKeyPolicy(&HeaderKeyPolicy{TrustedJKUHosts: []string{"login.example.com"}})
+		_, err = jwter.Verify(token)
+		assert.NotNil(err)
+		claims, err := jwter.Verify(jkuToken)
+		assert.Nil(err)
+		assert.Equal("alice", claims.Get("sub"))
+
+		for _, jku := range []string{"http://login.example.com/jwks", "https://evil.com/jwks", "https://login.example.com.evil.com/jwks"} {
+			token = signRawWithHeader([]byte("key1"), `{"alg":"HS256","jku":"`+jku+`"}`, `{"sub":"alice"}`)
+			_, err = jwter.Verify(token)
+			assert.NotNil(err, jku)
+		}
+
+		jwter.SetHeaderKeyPolicy(nil)
+		_, err = jwter.Verify(jkuToken)
+		assert.NotNil(err)
+	})
+}
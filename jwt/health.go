@@ -0,0 +1,61 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+
+	josejws "github.com/SermoDigital/jose/jws"
+	josejwt "github.com/SermoDigital/jose/jwt"
+)
+
+// HealthChecker can be implemented by stores and other dependencies of JWT,
+// their health will be reported by JWT.Check.
+type HealthChecker interface {
+	Check() error
+}
+
+// Check reports whether jwt is able to work: the key material must sign and verify a token,
+// and the store, key source or revoker (if any) must respond when it implements HealthChecker.
+// It is suitable for readiness probes.
+func (j *JWT) Check() error {
+	if hc, ok := j.revoker.(HealthChecker); ok {
+		if err := hc.Check(); err != nil {
+			return errors.New("revoker: " + err.Error())
+		}
+	}
+	if j.store != nil {
+		if hc, ok := j.store.(HealthChecker); ok {
+			if err := hc.Check(); err != nil {
+				return errors.New("store: " + err.Error())
+			}
+		}
+		return nil
+	}
+
+	if hc, ok := j.keySource.(HealthChecker); ok {
+		if err := hc.Check(); err != nil {
+			return errors.New("keys: " + err.Error())
+		}
+		return nil
+	}
+
+	keys, err := j.getKeys()
+	if err == nil {
+		if kp, ok := keys[0].(KeyPair); ok && kp.PrivateKey == nil {
+			return nil // verify only
+		}
+		// only the signature is verified, the token doesn't satisfy configured validators.
+		var token string
+		var parsed josejwt.JWT
+		if token, err = Sign(josejwt.Claims{"health": true}, j.method, keys[0]); err == nil {
+			parsed, err = josejws.ParseJWT([]byte(token))
+		}
+		if err == nil {
+			_, err = verifySignature(context.Background(), parsed, j.method, keys)
+		}
+	}
+	if err != nil {
+		return errors.New("keys: " + err.Error())
+	}
+	return nil
+}
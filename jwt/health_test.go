@@ -0,0 +1,49 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+
+	josecrypto "github.com/SermoDigital/jose/crypto"
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+type unhealthyStore struct {
+	*MemoryStore
+}
+
+func (s unhealthyStore) Check() error {
+	return errors.New("connection refused")
+}
+
+func TestHealth(t *testing.T) {
+	t.Run("Check", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.Nil(New().Check())
+		assert.Nil(New([]byte("key1")).Check())
+
+		jwter := New([]byte("key1"))
+		jwter.SetMethods(josecrypto.SigningMethodRS256)
+		assert.NotNil(jwter.Check())
+
+		jwter = New(KeyPair{PublicKey: []byte("key1")})
+		assert.Nil(jwter.Check())
+
+		// configured validators don't apply to the health token
+		jwter = New([]byte("key1"))
+		jwter.SetExpectedIssuer("https://accounts.example.com")
+		jwter.SetExpectedAudience("billing-api")
+		jwter.SetValidator(&josejwt.Validator{Fn: func(claims josejwt.Claims) error {
+			return errors.New("invalid claims")
+		}})
+		assert.Nil(jwter.Check())
+
+		jwter = New([]byte("key1"))
+		jwter.SetStore(NewMemoryStore())
+		assert.Nil(jwter.Check())
+		jwter.SetStore(unhealthyStore{NewMemoryStore()})
+		assert.Equal("store: connection refused", jwter.Check().Error())
+	})
+}
@@ -0,0 +1,23 @@
+package jwt
+
+import (
+	"time"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+)
+
+// Signer creates tokens from content, *JWT implements it.
+type Signer interface {
+	Sign(content interface{}, expiresIn ...time.Duration) (string, error)
+}
+
+// Verifier parses and validates tokens, *JWT implements it.
+// See package jwttest for a static implementation for tests.
+type Verifier interface {
+	Verify(token string) (josejwt.Claims, error)
+}
+
+var (
+	_ Signer   = (*JWT)(nil)
+	_ Verifier = (*JWT)(nil)
+)
@@ -0,0 +1,28 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// SetJTIGenerator set a function to generate the "jti" claim of every token signed by Sign,
+// unless the content has "jti" already. A unique "jti" is required by revocation (see SetRevoker),
+// replay detection and audit logging. Default to NewJTI. Set nil to disable it.
+//
+//  jwter.SetJTIGenerator(nil)
+//
+func (j *JWT) SetJTIGenerator(fn func() string) {
+	j.jtiGenerator = fn
+}
+
+// NewJTI returns a random (version 4) UUID, such as "0b5e4a3c-9d1e-4f6a-8b2c-7d3e5f1a9c0b".
+// It panics if the system's secure random number generator fails.
+func NewJTI() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	buf[6] = buf[6]&0x0f | 0x40
+	buf[8] = buf[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", buf[0:4], buf[4:6], buf[6:8], buf[8:10], buf[10:])
+}
@@ -0,0 +1,92 @@
+package jwt
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJTIGenerator(t *testing.T) {
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	t.Run("NewJTI should return random UUIDs", func(t *testing.T) {
+		assert := assert.New(t)
+
+		seen := make(map[string]bool)
+		for i := 0; i < 100; i++ {
+			jti := NewJTI()
+			assert.True(uuid.MatchString(jti), jti)
+			assert.False(seen[jti])
+			seen[jti] = true
+		}
+	})
+
+	t.Run("should set jti on Sign", func(t *testing.T) {
+		assert := assert.New(t)
+
+		// tokens carry a random jti by default
+		jwter := New([]byte("key"))
+		token, _ := jwter.Sign(josejwt.Claims{"sub": "alice"})
+		claims, err := jwter.Verify(token)
+		assert.Nil(err)
+		jti, _ := claims.JWTID()
+		assert.True(uuid.MatchString(jti), jti)
+
+		jwter.SetJTIGenerator(func() string { return "abc" })
+		token, _ = jwter.Sign(josejwt.Claims{"sub": "alice"})
+		claims, err = jwter.Verify(token)
+		assert.Nil(err)
+		assert.Equal("abc", claims.Get("jti"))
+
+		token, _ = jwter.Sign(josejwt.Claims{"sub": "alice", "jti": "xyz"})
+		claims, err = jwter.Verify(token)
+		assert.Nil(err)
+		assert.Equal("xyz", claims.Get("jti"))
+
+		jwter.SetJTIGenerator(NewJTI)
+		token1, _ := jwter.Sign(josejwt.Claims{"sub": "alice"})
+		token2, _ := jwter.Sign(josejwt.Claims{"sub": "alice"})
+		claims1, _ := jwter.Verify(token1)
+		claims2, _ := jwter.Verify(token2)
+		assert.NotEqual(claims1.Get("jti"), claims2.Get("jti"))
+
+		jwter.SetJTIGenerator(nil)
+		token, _ = jwter.Sign(josejwt.Claims{"sub": "alice"})
+		claims, _ = jwter.Verify(token)
+		assert.False(claims.Has("jti"))
+	})
+
+	t.Run("should not set jti to the content of Sign", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key"))
+		content := map[string]interface{}{"sub": "alice"}
+		token1, _ := jwter.Sign(content, time.Hour)
+		token2, _ := jwter.SignAt(time.Now(), content, time.Hour)
+		assert.Equal(map[string]interface{}{"sub": "alice"}, content)
+		claims1, err := jwter.Verify(token1)
+		assert.Nil(err)
+		claims2, err := jwter.Verify(token2)
+		assert.Nil(err)
+		assert.NotEqual(claims1.Get("jti"), claims2.Get("jti"))
+	})
+
+	t.Run("should revoke tokens with generated jti", func(t *testing.T) {
+		assert := assert.New(t)
+
+		revoker := NewMemoryRevoker()
+		jwter := New([]byte("key"))
+		jwter.SetRevoker(revoker)
+		token, _ := jwter.Sign(josejwt.Claims{"sub": "alice"}, time.Hour)
+		claims, err := jwter.Verify(token)
+		assert.Nil(err)
+		jti, _ := claims.JWTID()
+		exp, _ := claims.Expiration()
+		assert.Nil(revoker.Revoke(jti, exp))
+		_, err = jwter.Verify(token)
+		assert.Equal("token revoked", err.Error())
+	})
+}
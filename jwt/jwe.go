@@ -0,0 +1,118 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/SermoDigital/jose"
+)
+
+var errInvalidJWE = errors.New("invalid encrypted token")
+
+// SetNestedEncryption set a content encryption key to jwt, Sign will produce nested JWTs (RFC 7519,
+// Section 5.2): the signed token is wrapped in a JWE with "alg": "dir", "enc": "A128GCM", "A192GCM"
+// or "A256GCM" (by the key size of 16, 24 or 32 bytes) and "cty": "JWT", so the claims are not readable
+// by the client. Verify and Decode decrypt nested JWTs transparently, then validate the inner signature
+// with the configured keys. Signed tokens without encryption are still accepted, so encryption can be
+// rolled out without invalidating issued tokens. Set nil to disable it.
+//
+//  jwter.SetNestedEncryption([]byte("a 32 bytes long encryption key!!"))
+//
+func (j *JWT) SetNestedEncryption(key []byte) {
+	if key != nil {
+		if _, err := jweEncryption(key); err != nil {
+			panic(err)
+		}
+	}
+	j.nestedKey = key
+}
+
+// jweEncryption returns the "enc" of the key.
+func jweEncryption(key []byte) (string, error) {
+	switch len(key) {
+	case 16, 24, 32:
+		return "A" + strconv.Itoa(len(key)*8) + "GCM", nil
+	}
+	return "", errors.New("invalid JWE key size: " + strconv.Itoa(len(key)))
+}
+
+// encryptNested wraps the signed token in a JWE compact serialization with direct encryption.
+func encryptNested(key []byte, token string) (string, error) {
+	enc, err := jweEncryption(key)
+	if err != nil {
+		return "", err
+	}
+	header, err := json.Marshal(map[string]string{"alg": "dir", "enc": enc, "cty": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	iv := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(iv); err != nil {
+		return "", err
+	}
+	protected := jose.Base64Encode(header)
+	sealed := aead.Seal(nil, iv, []byte(token), protected)
+	ciphertext, tag := sealed[:len(sealed)-aead.Overhead()], sealed[len(sealed)-aead.Overhead():]
+	return strings.Join([]string{
+		string(protected), "", string(jose.Base64Encode(iv)),
+		string(jose.Base64Encode(ciphertext)), string(jose.Base64Encode(tag)),
+	}, "."), nil
+}
+
+// decryptNested returns the inner token of a JWE compact serialization,
+// or the token itself if it is not a JWE.
+func decryptNested(key []byte, token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 5 {
+		return token, nil
+	}
+	if key == nil {
+		return "", errors.New("encrypted token is not supported")
+	}
+	buf, err := jose.Base64Decode([]byte(parts[0]))
+	if err != nil {
+		return "", errInvalidJWE
+	}
+	var header map[string]interface{}
+	if err = json.Unmarshal(buf, &header); err != nil {
+		return "", errInvalidJWE
+	}
+	enc, _ := jweEncryption(key)
+	if header["alg"] != "dir" || header["enc"] != enc {
+		return "", errors.New("unsupported JWE algorithm")
+	}
+	if cty, _ := header["cty"].(string); !strings.EqualFold(cty, "JWT") {
+		return "", errors.New("encrypted token is not a nested JWT")
+	}
+	if parts[1] != "" {
+		return "", errInvalidJWE
+	}
+	var iv, ciphertext, tag []byte
+	if iv, err = jose.Base64Decode([]byte(parts[2])); err == nil {
+		if ciphertext, err = jose.Base64Decode([]byte(parts[3])); err == nil {
+			tag, err = jose.Base64Decode([]byte(parts[4]))
+		}
+	}
+	if err != nil {
+		return "", errInvalidJWE
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(iv) != aead.NonceSize() || len(tag) != aead.Overhead() {
+		return "", errInvalidJWE
+	}
+	plaintext, err := aead.Open(nil, iv, append(ciphertext, tag...), []byte(parts[0]))
+	if err != nil {
+		return "", errInvalidJWE
+	}
+	return string(plaintext), nil
+}
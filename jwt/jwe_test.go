@@ -0,0 +1,113 @@
+package jwt
+
+import (
+	"strings"
+	"testing"
+
+	josecrypto "github.com/SermoDigital/jose/crypto"
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNestedEncryption(t *testing.T) {
+	key := []byte("a 32 bytes long encryption key!!")
+
+	t.Run("should panic with invalid key", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key"))
+		assert.Panics(func() {
+			jwter.SetNestedEncryption([]byte("short"))
+		})
+		assert.NotPanics(func() {
+			jwter.SetNestedEncryption(key[:16])
+			jwter.SetNestedEncryption(key[:24])
+			jwter.SetNestedEncryption(nil)
+		})
+	})
+
+	t.Run("should sign and verify nested JWT", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key"))
+		jwter.SetNestedEncryption(key)
+		token, err := jwter.Sign(josejwt.Claims{"sub": "alice"})
+		assert.Nil(err)
+		assert.Equal(5, len(strings.Split(token, ".")))
+		assert.NotContains(token, "eyJzdWIiOiJhbGljZSJ9")
+
+		tk, err := jwter.VerifyToken(token)
+		assert.Nil(err)
+		assert.Equal(token, tk.Raw)
+		assert.Equal("HS256", tk.Header.Get("alg"))
+		assert.Equal("alice", tk.Claims.Get("sub"))
+
+		claims, err := jwter.Decode(token)
+		assert.Nil(err)
+		assert.Equal("alice", claims.Get("sub"))
+
+		inner, err := decryptNested(key, token)
+		assert.Nil(err)
+		claims, err = New([]byte("key")).Verify(inner)
+		assert.Nil(err)
+		assert.Equal("alice", claims.Get("sub"))
+
+		// signed tokens without encryption are accepted
+		plain, _ := New([]byte("key")).Sign(josejwt.Claims{"sub": "bob"})
+		claims, err = jwter.Verify(plain)
+		assert.Nil(err)
+		assert.Equal("bob", claims.Get("sub"))
+	})
+
+	t.Run("should verify the inner signature", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("other key"))
+		jwter.SetNestedEncryption(key)
+		inner, _ := New([]byte("key")).Sign(josejwt.Claims{"sub": "alice"})
+		token, err := encryptNested(key, inner)
+		assert.Nil(err)
+		_, err = jwter.Verify(token)
+		assert.Equal("signature is invalid", err.Error())
+
+		jwter.SetSigning(josecrypto.SigningMethodHS256, []byte("other key"), []byte("key"))
+		_, err = jwter.Verify(token)
+		assert.Nil(err)
+	})
+
+	t.Run("should reject invalid encrypted tokens", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key"))
+		jwter.SetNestedEncryption(key)
+		token, _ := jwter.Sign(josejwt.Claims{"sub": "alice"})
+
+		_, err := New([]byte("key")).Verify(token)
+		assert.Equal("encrypted token is not supported", err.Error())
+
+		other := New([]byte("key"))
+		other.SetNestedEncryption([]byte("another 32 bytes encryption key!"))
+		_, err = other.Verify(token)
+		assert.Equal("invalid encrypted token", err.Error())
+		other.SetNestedEncryption(key[:16])
+		_, err = other.Verify(token)
+		assert.Equal("unsupported JWE algorithm", err.Error())
+
+		parts := strings.Split(token, ".")
+		parts[3] = parts[3][:len(parts[3])-2] + "AA"
+		_, err = jwter.Verify(strings.Join(parts, "."))
+		assert.Equal("invalid encrypted token", err.Error())
+
+		parts = strings.Split(token, ".")
+		parts[1] = "AAAA"
+		_, err = jwter.Verify(strings.Join(parts, "."))
+		assert.Equal("invalid encrypted token", err.Error())
+
+		parts = strings.Split(token, ".")
+		parts[0] = "eyJhbGciOiJkaXIiLCJlbmMiOiJBMjU2R0NNIn0"
+		_, err = jwter.Verify(strings.Join(parts, "."))
+		assert.Equal("encrypted token is not a nested JWT", err.Error())
+		_, err = jwter.Decode(strings.Join(parts, "."))
+		assert.Equal("encrypted token is not a nested JWT", err.Error())
+	})
+}
@@ -0,0 +1,218 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/SermoDigital/jose"
+)
+
+// JWK is a JSON Web Key (RFC 7517) for signature verification, see ParseJWK and ExportJWK.
+type JWK struct {
+	// Key is *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey or []byte (HMAC).
+	Key interface{}
+	// KeyID is the "kid", it matches the "kid" header of tokens, see SetSigningWithKID.
+	KeyID string
+	// Algorithm is the "alg", such as "RS256".
+	Algorithm string
+	// Use is the "use", such as "sig".
+	Use string
+}
+
+// ParseJWK parses a JSON Web Key of "kty" RSA, EC (P-256, P-384 or P-521), OKP (Ed25519) or oct.
+// Only the public parameters are read, so the key of a private JWK is its public key.
+//
+//  jwk, err := jwt.ParseJWK([]byte(`{"kty":"EC","crv":"P-256","kid":"2018-02","x":"...","y":"..."}`))
+//  verifier.SetSigningWithKID(josecrypto.SigningMethodES256, map[string]interface{}{jwk.KeyID: jwk.Key}, jwk.KeyID)
+//
+func ParseJWK(data []byte) (*JWK, error) {
+	k := &jsonWebKey{}
+	if err := json.Unmarshal(data, k); err != nil {
+		return nil, err
+	}
+	key, err := k.key()
+	if err != nil {
+		return nil, err
+	}
+	return &JWK{Key: key, KeyID: k.Kid, Algorithm: k.Alg, Use: k.Use}, nil
+}
+
+// ExportJWK serializes the key to a JSON Web Key. The key can also be a private key or a KeyPair
+// (including golang.org/x/crypto/ed25519 keys), only its public key is exported. []byte keys are
+// exported as "kty": "oct" with the secret, don't publish them.
+//
+//  data, err := jwt.ExportJWK(&jwt.JWK{Key: &privateKey.PublicKey, KeyID: "2018-02", Algorithm: "RS256", Use: "sig"})
+//
+func ExportJWK(jwk *JWK) ([]byte, error) {
+	k, err := toJSONWebKey(jwk)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(k)
+}
+
+// toJSONWebKey returns the jsonWebKey of the public key of jwk.Key.
+func toJSONWebKey(jwk *JWK) (*jsonWebKey, error) {
+	if jwk == nil {
+		return nil, errors.New("invalid JWK")
+	}
+	key, err := publicKeyOf(jwk.Key)
+	if err != nil {
+		return nil, err
+	}
+	k := &jsonWebKey{Kid: jwk.KeyID, Alg: jwk.Algorithm, Use: jwk.Use}
+	switch v := key.(type) {
+	case ed25519.PublicKey:
+		k.Kty, k.Crv, k.X = "OKP", "Ed25519", string(jose.Base64Encode(v))
+	case *rsa.PublicKey:
+		k.Kty = "RSA"
+		k.N = string(jose.Base64Encode(v.N.Bytes()))
+		k.E = string(jose.Base64Encode(big.NewInt(int64(v.E)).Bytes()))
+	case *ecdsa.PublicKey:
+		k.Kty, k.Crv = "EC", v.Curve.Params().Name
+		switch k.Crv {
+		case "P-256", "P-384", "P-521":
+		default:
+			return nil, errors.New("unsupported EC curve: " + k.Crv)
+		}
+		// coordinates are padded to the size of the curve (RFC 7518, Section 6.2.1.2).
+		size := (v.Curve.Params().BitSize + 7) / 8
+		k.X = string(jose.Base64Encode(v.X.FillBytes(make([]byte, size))))
+		k.Y = string(jose.Base64Encode(v.Y.FillBytes(make([]byte, size))))
+	case []byte:
+		if len(v) == 0 {
+			return nil, errors.New("empty oct key")
+		}
+		k.Kty, k.K = "oct", string(jose.Base64Encode(v))
+	}
+	return k, nil
+}
+
+// publicKeyOf returns the public key of a key or KeyPair: *rsa.PublicKey, *ecdsa.PublicKey,
+// ed25519.PublicKey, or []byte for HMAC keys.
+func publicKeyOf(key interface{}) (interface{}, error) {
+	if pair, ok := key.(KeyPair); ok {
+		if key = pair.PublicKey; key == nil {
+			key = pair.PrivateKey
+		}
+	}
+	if private, ok := edPrivateKey(key); ok {
+		key = private.Public()
+	}
+	if public, ok := edPublicKey(key); ok {
+		return public, nil
+	}
+	switch v := key.(type) {
+	case *rsa.PrivateKey:
+		return &v.PublicKey, nil
+	case *ecdsa.PrivateKey:
+		return &v.PublicKey, nil
+	case *rsa.PublicKey, *ecdsa.PublicKey, []byte:
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported key type: %T", key)
+}
+
+// jsonWebKey is a JSON Web Key (RFC 7517) with the parameters used for signature verification.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Use string `json:"use,omitempty"`
+	Kid string `json:"kid,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EC and OKP
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	// oct
+	K string `json:"k,omitempty"`
+}
+
+// jsonWebKeySet is a JWK Set (RFC 7517, Section 5).
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// key returns the Go key of the JWK: *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey or []byte.
+func (k *jsonWebKey) key() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.New("unsupported EC curve: " + k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("invalid EC point")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, errors.New("unsupported OKP curve: " + k.Crv)
+		}
+		buf, err := jose.Base64Decode([]byte(k.X))
+		if err == nil && len(buf) != ed25519.PublicKeySize {
+			err = errors.New("invalid Ed25519 public key")
+		}
+		if err != nil {
+			return nil, err
+		}
+		return ed25519.PublicKey(buf), nil
+	case "oct":
+		buf, err := jose.Base64Decode([]byte(k.K))
+		if err == nil && len(buf) == 0 {
+			err = errors.New("empty oct key")
+		}
+		if err != nil {
+			return nil, err
+		}
+		return buf, nil
+	}
+	return nil, errors.New("unsupported key type: " + k.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	buf, err := jose.Base64Decode([]byte(s))
+	if err == nil && len(buf) == 0 {
+		err = errors.New("empty key parameter")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(buf), nil
+}
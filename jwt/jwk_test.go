@@ -0,0 +1,118 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"testing"
+
+	josecrypto "github.com/SermoDigital/jose/crypto"
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+	xed25519 "golang.org/x/crypto/ed25519"
+)
+
+func TestJWK(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 1024)
+	edPublic, edPrivate, _ := ed25519.GenerateKey(rand.Reader)
+
+	t.Run("should export and parse keys", func(t *testing.T) {
+		assert := assert.New(t)
+
+		for _, curve := range []elliptic.Curve{elliptic.P256(), elliptic.P384(), elliptic.P521()} {
+			ecKey, _ := ecdsa.GenerateKey(curve, rand.Reader)
+			data, err := ExportJWK(&JWK{Key: ecKey, KeyID: "ec", Algorithm: "ES256", Use: "sig"})
+			assert.Nil(err)
+			jwk, err := ParseJWK(data)
+			assert.Nil(err)
+			assert.Equal(&JWK{Key: &ecKey.PublicKey, KeyID: "ec", Algorithm: "ES256", Use: "sig"}, jwk)
+		}
+
+		for _, key := range []interface{}{
+			rsaKey, &rsaKey.PublicKey, KeyPair{PrivateKey: rsaKey}, KeyPair{PrivateKey: rsaKey, PublicKey: &rsaKey.PublicKey},
+		} {
+			data, err := ExportJWK(&JWK{Key: key, KeyID: "rsa"})
+			assert.Nil(err)
+			jwk, err := ParseJWK(data)
+			assert.Nil(err)
+			assert.Equal(&rsaKey.PublicKey, jwk.Key)
+			assert.Equal("rsa", jwk.KeyID)
+			assert.Equal("", jwk.Use)
+		}
+
+		for _, key := range []interface{}{edPublic, edPrivate, xed25519.PublicKey(edPublic), xed25519.PrivateKey(edPrivate)} {
+			data, err := ExportJWK(&JWK{Key: key, Algorithm: "EdDSA"})
+			assert.Nil(err)
+			jwk, err := ParseJWK(data)
+			assert.Nil(err)
+			assert.Equal(edPublic, jwk.Key)
+		}
+
+		data, err := ExportJWK(&JWK{Key: []byte("secret")})
+		assert.Nil(err)
+		assert.Equal(`{"kty":"oct","k":"c2VjcmV0"}`, string(data))
+		jwk, err := ParseJWK(data)
+		assert.Nil(err)
+		assert.Equal([]byte("secret"), jwk.Key)
+	})
+
+	t.Run("should export RFC 7517 JSON", func(t *testing.T) {
+		assert := assert.New(t)
+
+		data, err := ExportJWK(&JWK{Key: &rsa.PublicKey{N: rsaKey.N, E: 65537}, KeyID: "2018-02", Algorithm: "RS256", Use: "sig"})
+		assert.Nil(err)
+		fields := map[string]string{}
+		assert.Nil(json.Unmarshal(data, &fields))
+		assert.Equal("RSA", fields["kty"])
+		assert.Equal("AQAB", fields["e"])
+		assert.Equal("2018-02", fields["kid"])
+		assert.Equal("RS256", fields["alg"])
+		assert.Equal("sig", fields["use"])
+
+		// EC coordinates are padded to the curve size
+		ecKey, _ := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+		data, _ = ExportJWK(&JWK{Key: ecKey})
+		fields = map[string]string{}
+		json.Unmarshal(data, &fields)
+		assert.Equal("P-521", fields["crv"])
+		assert.Equal(88, len(fields["x"]))
+		assert.Equal(88, len(fields["y"]))
+	})
+
+	t.Run("should verify tokens with parsed keys", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New()
+		jwter.SetSigning(josecrypto.SigningMethodRS256, KeyPair{PrivateKey: rsaKey, PublicKey: &rsaKey.PublicKey})
+		token, _ := jwter.Sign(josejwt.Claims{"sub": "alice"})
+
+		data, _ := ExportJWK(&JWK{Key: rsaKey})
+		jwk, _ := ParseJWK(data)
+		verifier := New()
+		verifier.SetSigning(josecrypto.SigningMethodRS256, jwk.Key)
+		_, err := verifier.Verify(token)
+		assert.Nil(err)
+	})
+
+	t.Run("should return errors of invalid keys", func(t *testing.T) {
+		assert := assert.New(t)
+
+		_, err := ExportJWK(nil)
+		assert.Equal("invalid JWK", err.Error())
+		_, err = ExportJWK(&JWK{Key: "key"})
+		assert.Equal("unsupported key type: string", err.Error())
+		_, err = ExportJWK(&JWK{Key: []byte{}})
+		assert.Equal("empty oct key", err.Error())
+		ecKey, _ := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+		_, err = ExportJWK(&JWK{Key: ecKey})
+		assert.Equal("unsupported EC curve: P-224", err.Error())
+
+		_, err = ParseJWK([]byte("invalid"))
+		assert.NotNil(err)
+		_, err = ParseJWK([]byte(`{"kty":"DSA"}`))
+		assert.Equal("unsupported key type: DSA", err.Error())
+	})
+}
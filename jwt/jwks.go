@@ -0,0 +1,284 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrUnknownKeyID is returned by JWKS when the "kid" of a token isn't in the key set, even after refetching.
+var ErrUnknownKeyID = errors.New("unknown kid")
+
+// JWKS is a verify-only KeySource that fetches public keys from a JWKS URL of an identity provider,
+// it implements KeyIDSource. The key set is cached for the TTL. When a token's "kid" isn't in the cached
+// key set (e.g. the IdP rolled its keys), it is refetched immediately and looked up again, at most once
+// per minimum refresh interval, so key rollovers don't cause an outage window equal to the TTL.
+// If refetching failed, the stale key set will be used by default, see SetStaleFallback.
+type JWKS struct {
+	url        string
+	ttl        time.Duration
+	minRefresh time.Duration
+	client     *http.Client
+	breaker    *CircuitBreaker
+	backoff    *Backoff
+	cache      Cache
+	failClosed bool
+
+	fetchMu   sync.Mutex // serializes fetching
+	mu        sync.RWMutex
+	keys      []interface{}
+	kids      map[string]interface{}
+	fetchedAt time.Time
+	lastFetch time.Time
+}
+
+// NewJWKS returns a JWKS instance for the url, the key set will be fetched on first use and cached for ttl.
+//
+//  jwks := jwt.NewJWKS("https://login.example.com/.well-known/jwks.json", time.Hour)
+//  jwter.SetKeySource(josecrypto.SigningMethodRS256, jwks)
+//
+func NewJWKS(url string, ttl time.Duration) *JWKS {
+	if url == "" {
+		panic(errors.New("invalid JWKS url"))
+	}
+	if ttl <= 0 {
+		panic(errors.New("invalid JWKS ttl"))
+	}
+	return &JWKS{
+		url:        url,
+		ttl:        ttl,
+		minRefresh: 30 * time.Second,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// SetMinRefreshInterval set the minimum interval of refetching triggered by unknown "kid", or of refetching
+// a stale key set after a failure when the stale key set is served, default to 30 seconds.
+func (k *JWKS) SetMinRefreshInterval(interval time.Duration) *JWKS {
+	if interval < 0 {
+		panic(errors.New("invalid JWKS refresh interval"))
+	}
+	k.minRefresh = interval
+	return k
+}
+
+// SetHTTPClient set a custom http.Client to fetch the key set, so that timeouts, proxies, TLS roots
+// and instrumentation can be configured. Default to a client with 10 seconds timeout.
+//
+//  jwks.SetHTTPClient(&http.Client{
+//  	Timeout:   5 * time.Second,
+//  	Transport: &http.Transport{Proxy: http.ProxyFromEnvironment, TLSClientConfig: tlsConfig},
+//  })
+//
+func (k *JWKS) SetHTTPClient(client *http.Client) *JWKS {
+	if client == nil {
+		panic(errors.New("invalid http client"))
+	}
+	k.client = client
+	return k
+}
+
+// SetCircuitBreaker set a CircuitBreaker for fetching, so that an IdP outage doesn't make every request
+// try to refetch the key set. While the circuit is open, the fallback behavior applies.
+func (k *JWKS) SetCircuitBreaker(breaker *CircuitBreaker) *JWKS {
+	if breaker == nil {
+		panic(errors.New("invalid circuit breaker"))
+	}
+	k.breaker = breaker
+	return k
+}
+
+// SetBackoff set a Backoff to retry failed fetching, retries happen within one fetch,
+// so a circuit breaker counts them as one failure.
+func (k *JWKS) SetBackoff(backoff *Backoff) *JWKS {
+	if backoff == nil {
+		panic(errors.New("invalid backoff"))
+	}
+	k.backoff = backoff
+	return k
+}
+
+// SetCache set a shared Cache for the fetched key set, so that instances of a service fetch the key set
+// once per TTL together. Refetching triggered by unknown "kid" and refreshing by StartRefresh bypass the cache.
+//
+//  jwks.SetCache(cacheredis.New(redisClient, "myapp:"))
+//
+func (k *JWKS) SetCache(cache Cache) *JWKS {
+	if cache == nil {
+		panic(errors.New("invalid cache"))
+	}
+	k.cache = cache
+	return k
+}
+
+// SetStaleFallback set the fallback behavior when the key set is expired but can't be refetched.
+// If stale is true (default), the stale key set is served. Otherwise it fails closed:
+// Keys returns the fetching error, so tokens are rejected until the key set is refetched.
+func (k *JWKS) SetStaleFallback(stale bool) *JWKS {
+	k.failClosed = !stale
+	return k
+}
+
+// Keys implements the KeySource interface, it returns all keys in the key set.
+func (k *JWKS) Keys() ([]interface{}, error) {
+	k.mu.RLock()
+	keys, fresh := k.keys, time.Since(k.fetchedAt) < k.ttl
+	k.mu.RUnlock()
+	if keys != nil && fresh {
+		return keys, nil
+	}
+
+	k.fetchMu.Lock()
+	defer k.fetchMu.Unlock()
+	k.mu.RLock()
+	keys, fresh = k.keys, time.Since(k.fetchedAt) < k.ttl
+	// fetchedAt is lastFetch unless the last fetch failed.
+	limited := k.lastFetch.After(k.fetchedAt) && time.Since(k.lastFetch) < k.minRefresh
+	k.mu.RUnlock()
+	// the stale key set is served without refetching if the last refetch failed recently.
+	if keys != nil && (fresh || (limited && !k.failClosed)) {
+		return keys, nil
+	}
+	if err := k.fetch(true); err != nil && (keys == nil || k.failClosed) {
+		return nil, err
+	}
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.keys, nil
+}
+
+// KeysByID implements the KeyIDSource interface, it returns the key with the kid.
+func (k *JWKS) KeysByID(kid string) ([]interface{}, error) {
+	if _, err := k.Keys(); err != nil {
+		return nil, err
+	}
+	if key, ok := k.lookup(kid); ok {
+		return []interface{}{key}, nil
+	}
+
+	k.fetchMu.Lock()
+	defer k.fetchMu.Unlock()
+	if key, ok := k.lookup(kid); ok { // refetched by others
+		return []interface{}{key}, nil
+	}
+	k.mu.RLock()
+	limited := time.Since(k.lastFetch) < k.minRefresh
+	k.mu.RUnlock()
+	if !limited {
+		k.fetch(false)
+		if key, ok := k.lookup(kid); ok {
+			return []interface{}{key}, nil
+		}
+	}
+	return nil, ErrUnknownKeyID
+}
+
+// Check implements the HealthChecker interface, it reports whether the key set is available.
+func (k *JWKS) Check() error {
+	_, err := k.Keys()
+	return err
+}
+
+func (k *JWKS) lookup(kid string) (interface{}, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	key, ok := k.kids[kid]
+	return key, ok
+}
+
+// fetch fetches the key set and replaces the cached one, it should be called with fetchMu held.
+// If cached is true, the key set in the shared Cache is used if any.
+func (k *JWKS) fetch(cached bool) error {
+	now := time.Now()
+	k.mu.Lock()
+	k.lastFetch = now
+	k.mu.Unlock()
+
+	var set *jsonWebKeySet
+	get := func() (err error) {
+		set, err = k.get(cached)
+		return
+	}
+	if k.backoff != nil {
+		get = retry(context.Background(), k.backoff, get)
+	}
+	var err error
+	if k.breaker != nil {
+		err = k.breaker.Do(get)
+	} else {
+		err = get()
+	}
+	if err != nil {
+		return err
+	}
+	keys, kids, err := parseKeySet(set)
+	if err != nil {
+		return err
+	}
+	k.mu.Lock()
+	k.keys = keys
+	k.kids = kids
+	k.fetchedAt = now
+	k.mu.Unlock()
+	return nil
+}
+
+func (k *JWKS) get(cached bool) (*jsonWebKeySet, error) {
+	key := "jwks:" + k.url
+	set := &jsonWebKeySet{}
+	if cached && k.cache != nil {
+		if data, err := k.cache.Get(key); err == nil && json.Unmarshal(data, set) == nil {
+			return set, nil
+		}
+	}
+
+	res, err := k.client.Get(k.url)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, &statusError{"JWKS", res.StatusCode}
+	}
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, set); err != nil {
+		return nil, err
+	}
+	if k.cache != nil {
+		k.cache.Set(key, data, k.ttl)
+	}
+	return set, nil
+}
+
+// parseKeySet returns the signature keys in the key set, keys with unsupported types are skipped.
+func parseKeySet(set *jsonWebKeySet) ([]interface{}, map[string]interface{}, error) {
+	keys := make([]interface{}, 0, len(set.Keys))
+	kids := make(map[string]interface{}, len(set.Keys))
+	for i := range set.Keys {
+		jwk := &set.Keys[i]
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.key()
+		if err != nil {
+			continue
+		}
+		keys = append(keys, key)
+		if jwk.Kid != "" {
+			kids[jwk.Kid] = key
+		}
+	}
+	if len(keys) == 0 {
+		return nil, nil, errors.New("no signature keys in JWKS")
+	}
+	return keys, kids, nil
+}
+
+var _ KeyIDSource = (*JWKS)(nil)
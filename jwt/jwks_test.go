@@ -0,0 +1,254 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SermoDigital/jose"
+	josecrypto "github.com/SermoDigital/jose/crypto"
+	josejws "github.com/SermoDigital/jose/jws"
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+// testJWKSServer serves the public keys of its private keys as a JWKS.
+type testJWKSServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	keys    map[string]*ecdsa.PrivateKey
+	fetches int
+}
+
+func newTestJWKSServer() *testJWKSServer {
+	s := &testJWKSServer{keys: make(map[string]*ecdsa.PrivateKey)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.fetches++
+		set := jsonWebKeySet{Keys: []jsonWebKey{}}
+		for kid, key := range s.keys {
+			set.Keys = append(set.Keys, jsonWebKey{
+				Kty: "EC",
+				Kid: kid,
+				Crv: "P-256",
+				X:   string(jose.Base64Encode(key.X.Bytes())),
+				Y:   string(jose.Base64Encode(key.Y.Bytes())),
+			})
+		}
+		json.NewEncoder(w).Encode(set)
+	}))
+	return s
+}
+
+func (s *testJWKSServer) addKey(kid string) *ecdsa.PrivateKey {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s.mu.Lock()
+	s.keys[kid] = key
+	s.mu.Unlock()
+	return key
+}
+
+func (s *testJWKSServer) fetchCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fetches
+}
+
+// signWithKID signs a ES256 token with the "kid" header.
+func signWithKID(key *ecdsa.PrivateKey, kid string, claims josejwt.Claims) string {
+	token := josejws.NewJWT(josejws.Claims(claims), josecrypto.SigningMethodES256)
+	token.(josejws.JWS).Protected().Set("kid", kid)
+	buf, _ := token.Serialize(key)
+	return string(buf)
+}
+
+func TestJWKS(t *testing.T) {
+	t.Run("should verify tokens with JWKS", func(t *testing.T) {
+		assert := assert.New(t)
+
+		srv := newTestJWKSServer()
+		defer srv.Close()
+		key1 := srv.addKey("key1")
+
+		assert.Panics(func() {
+			NewJWKS("", time.Hour)
+		})
+		jwks := NewJWKS(srv.URL, time.Hour)
+		jwter := New()
+		jwter.SetKeySource(josecrypto.SigningMethodES256, jwks)
+		assert.Equal(0, srv.fetchCount())
+
+		claims, err := jwter.Verify(signWithKID(key1, "key1", josejwt.Claims{"sub": "alice"}))
+		assert.Nil(err)
+		assert.Equal("alice", claims.Get("sub"))
+		assert.Equal(1, srv.fetchCount())
+
+		// without kid, all keys are tried
+		token, _ := Sign(josejwt.Claims{"sub": "alice"}, josecrypto.SigningMethodES256, key1)
+		_, err = jwter.Verify(token)
+		assert.Nil(err)
+		assert.Equal(1, srv.fetchCount())
+		assert.Nil(jwter.Check())
+
+		key2, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		_, err = jwter.Verify(signWithKID(key2, "key1", josejwt.Claims{"sub": "alice"}))
+		assert.NotNil(err)
+	})
+
+	t.Run("should refetch on unknown kid with rate limit", func(t *testing.T) {
+		assert := assert.New(t)
+
+		srv := newTestJWKSServer()
+		defer srv.Close()
+		srv.addKey("key1")
+
+		jwks := NewJWKS(srv.URL, time.Hour).SetMinRefreshInterval(50 * time.Millisecond)
+		jwter := New()
+		jwter.SetKeySource(josecrypto.SigningMethodES256, jwks)
+		_, err := jwks.Keys()
+		assert.Nil(err)
+		assert.Equal(1, srv.fetchCount())
+
+		// the IdP rolled its keys, but the refetch is rate limited
+		key2 := srv.addKey("key2")
+		token := signWithKID(key2, "key2", josejwt.Claims{"sub": "alice"})
+		_, err = jwter.Verify(token)
+		assert.Contains(err.Error(), "unknown kid")
+		assert.Equal(1, srv.fetchCount())
+
+		time.Sleep(60 * time.Millisecond)
+		claims, err := jwter.Verify(token)
+		assert.Nil(err)
+		assert.Equal("alice", claims.Get("sub"))
+		assert.Equal(2, srv.fetchCount())
+
+		time.Sleep(60 * time.Millisecond)
+		_, err = jwter.Verify(signWithKID(key2, "key3", josejwt.Claims{"sub": "alice"}))
+		assert.Equal(3, srv.fetchCount())
+		_, err = jwter.Verify(signWithKID(key2, "key3", josejwt.Claims{"sub": "alice"}))
+		assert.Contains(err.Error(), "unknown kid")
+		assert.Equal(3, srv.fetchCount())
+	})
+
+	t.Run("should use stale keys when refetch failed", func(t *testing.T) {
+		assert := assert.New(t)
+
+		srv := newTestJWKSServer()
+		key1 := srv.addKey("key1")
+		jwks := NewJWKS(srv.URL, 10*time.Millisecond)
+		jwter := New()
+		jwter.SetKeySource(josecrypto.SigningMethodES256, jwks)
+
+		token := signWithKID(key1, "key1", josejwt.Claims{"sub": "alice"})
+		_, err := jwter.Verify(token)
+		assert.Nil(err)
+
+		srv.Close()
+		time.Sleep(20 * time.Millisecond)
+		_, err = jwter.Verify(token)
+		assert.Nil(err)
+
+		// the failed refetch is not retried by every request
+		jwks.mu.RLock()
+		lastFetch := jwks.lastFetch
+		jwks.mu.RUnlock()
+		for i := 0; i < 3; i++ {
+			_, err = jwter.Verify(token)
+			assert.Nil(err)
+		}
+		jwks.mu.RLock()
+		assert.Equal(lastFetch, jwks.lastFetch)
+		jwks.mu.RUnlock()
+
+		jwter.SetKeySource(josecrypto.SigningMethodES256, NewJWKS(srv.URL, time.Hour))
+		_, err = jwter.Verify(token)
+		assert.NotNil(err)
+		assert.NotNil(jwter.Check())
+	})
+
+	t.Run("should fail closed with circuit breaker", func(t *testing.T) {
+		assert := assert.New(t)
+
+		srv := newTestJWKSServer()
+		key1 := srv.addKey("key1")
+		jwks := NewJWKS(srv.URL, 10*time.Millisecond).
+			SetCircuitBreaker(NewCircuitBreaker(1, time.Hour)).
+			SetStaleFallback(false)
+		assert.Panics(func() {
+			jwks.SetCircuitBreaker(nil)
+		})
+		jwter := New()
+		jwter.SetKeySource(josecrypto.SigningMethodES256, jwks)
+
+		token := signWithKID(key1, "key1", josejwt.Claims{"sub": "alice"})
+		_, err := jwter.Verify(token)
+		assert.Nil(err)
+
+		srv.Close()
+		time.Sleep(20 * time.Millisecond)
+		_, err = jwter.Verify(token)
+		assert.NotNil(err)
+		_, err = jwter.Verify(token)
+		assert.Contains(err.Error(), ErrCircuitOpen.Error())
+		assert.Equal(1, srv.fetchCount())
+	})
+
+	t.Run("parseKeySet", func(t *testing.T) {
+		assert := assert.New(t)
+
+		set := &jsonWebKeySet{}
+		assert.Nil(json.Unmarshal([]byte(`{"keys":[
+			{"kty":"RSA","kid":"rsa","n":"0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw","e":"AQAB"},
+			{"kty":"oct","kid":"hmac","k":"a2V5MQ"},
+			{"kty":"EC","kid":"enc","use":"enc","crv":"P-256","x":"AA","y":"AA"},
+			{"kty":"EC","kid":"bad","crv":"P-256","x":"AA","y":"AA"},
+			{"kty":"OKP","kid":"okp"}
+		]}`), set))
+		keys, kids, err := parseKeySet(set)
+		assert.Nil(err)
+		assert.Equal(2, len(keys))
+		assert.Equal(2, len(kids))
+		assert.Equal([]byte("key1"), kids["hmac"])
+		assert.Nil(CheckKey(josecrypto.SigningMethodRS256, kids["rsa"]))
+
+		_, _, err = parseKeySet(&jsonWebKeySet{})
+		assert.NotNil(err)
+	})
+
+	t.Run("SetHTTPClient", func(t *testing.T) {
+		assert := assert.New(t)
+
+		srv := newTestJWKSServer()
+		defer srv.Close()
+		key1 := srv.addKey("key1")
+
+		requests := 0
+		jwks := NewJWKS(srv.URL, time.Hour)
+		assert.Panics(func() {
+			jwks.SetHTTPClient(nil)
+		})
+		jwks.SetHTTPClient(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			requests++
+			return http.DefaultTransport.RoundTrip(req)
+		})})
+		jwter := New()
+		jwter.SetKeySource(josecrypto.SigningMethodES256, jwks)
+
+		_, err := jwter.Verify(signWithKID(key1, "key1", josejwt.Claims{"sub": "alice"}))
+		assert.Nil(err)
+		assert.Equal(1, requests)
+	})
+}
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (fn roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
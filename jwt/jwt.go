@@ -1,8 +1,11 @@
+// Package jwt is the transport-agnostic core of gear-auth.
+// It signs, decodes and verifies JWT tokens and doesn't depend on any web framework.
 package jwt
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
-	"net/textproto"
 	"time"
 
 	josecrypto "github.com/SermoDigital/jose/crypto"
@@ -18,21 +21,54 @@ type KeyPair struct {
 
 // JWT represents a module. it can be use to create, decode or verify JWT token.
 type JWT struct {
-	keys         rotating
-	expiresIn    time.Duration
-	issuer       string
-	audience     []string
-	method       josecrypto.SigningMethod
-	validator    []*josejwt.Validator
-	backupKeys   rotating
-	backupMethod josecrypto.SigningMethod
+	keys              rotating
+	expiresIn         time.Duration
+	expiresInFn       func(josejwt.Claims) time.Duration
+	issuer            string
+	audience          []string
+	method            josecrypto.SigningMethod
+	validator         []*josejwt.Validator
+	backupKeys        rotating
+	backupMethod      josecrypto.SigningMethod
+	store             Store
+	templates         map[string]josejwt.Claims
+	authorizedParties []string
+	encryptKey        []byte
+	encryptNames      []string
+	compressThreshold int
+	keySource         KeySource
+	lenientTime       bool
+	allowDuplicates   bool
+	critical          map[string]func(interface{}) error
+	headerKeyPolicy   *HeaderKeyPolicy
+	revoker           Revoker
+	version           int
+	minVersion        int
+	ctxValidators     []ContextValidator
+	namespace         string
+	namespaceAllowed  map[string]bool
+	issuerPatterns    []string
+	subjectFormats    []SubjectFormat
+	refreshMethod     josecrypto.SigningMethod
+	refreshKeys       rotating
+	refreshExpiresIn  time.Duration
+	sortedClaims      bool
+	codec             Codec
+	msPrecision       bool
+	signKID           string
+	nestedKey         []byte
+	jtiGenerator      func() string
+	clock             Clock
+	expectedAudience  []string
+	expectedIssuers   []string
 }
 
 // New returns a JWT instance.
 // if key omit, jwt will use crypto.Unsecured as signing method.
 // Otherwise crypto.SigningMethodHS256 will be used. You can change it by jwt.SetMethods.
+// Signed tokens carry a random "jti" by default, see SetJTIGenerator.
 func New(keys ...interface{}) *JWT {
-	j := &JWT{method: josecrypto.Unsecured}
+	j := &JWT{method: josecrypto.Unsecured, jtiGenerator: NewJTI}
 	j.keys = keys
 	if len(keys) == 0 {
 		j.keys = []interface{}{nil}
@@ -49,65 +85,399 @@ func New(keys ...interface{}) *JWT {
 //  claims := josejwt.Claims{} // or claims := josejws.Claims{}
 //  claims.Set("hello", "world")
 //  token2, err2 := jwt.Sign(claims)
+//  // or any struct, it will be marshaled to claims by json tags
+//  token3, err3 := jwt.Sign(&User{ID: "xxxxx"})
 //
 // if expiresIn <= 0, expiration will not be set to claims:
 //
 //  token1, err1 := jwt.Sign(map[string]interface{}{"UserId": "xxxxx"}, time.Duration(0))
 //
-func (j *JWT) Sign(content map[string]interface{}, expiresIn ...time.Duration) (string, error) {
-	claims := josejwt.Claims(content)
+// The content is never modified, defaults such as "iat", "exp" and "jti" are set to a copy of it.
+func (j *JWT) Sign(content interface{}, expiresIn ...time.Duration) (string, error) {
+	return j.SignAt(j.now(), content, expiresIn...)
+}
+
+// SignAt creates a JWT token as Sign, but issued at the given time: "iat" (if not present in content)
+// and "exp" are computed from it instead of the current time. It is useful for fixtures and golden tests
+// that need stable tokens, and for backfill jobs that mint tokens "as of" a past moment.
+// Note that a random "jti" is still generated if not present in content, call SetJTIGenerator(nil)
+// for identical tokens of identical content.
+//
+//  at := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+//  token, err := jwter.SignAt(at, map[string]interface{}{"UserId": "xxxxx"}, time.Hour)
+//
+func (j *JWT) SignAt(at time.Time, content interface{}, expiresIn ...time.Duration) (string, error) {
+	claims, err := j.toClaims(content)
+	if err != nil {
+		return "", err
+	}
+	if j.namespace != "" {
+		if err = j.checkNamespace(claims); err != nil {
+			return "", err
+		}
+	}
 	if j.issuer != "" {
 		claims.SetIssuer(j.issuer)
 	}
 	if len(j.audience) > 0 {
 		claims.SetAudience(j.audience...)
 	}
+	if err = canonicalizeAudience(claims); err != nil {
+		return "", err
+	}
+	ttl := j.expiresIn
+	if j.expiresInFn != nil {
+		ttl = j.expiresInFn(claims)
+	}
 	if len(expiresIn) > 0 {
-		if expiresIn[0] > 0 {
-			claims.SetExpiration(time.Now().Add(expiresIn[0]))
+		ttl = expiresIn[0]
+	}
+	if !claims.Has("iat") {
+		if j.msPrecision {
+			claims.Set("iat", numericDate(at))
+		} else {
+			claims.Set("iat", at.Unix())
+		}
+	}
+	if j.version > 0 && !claims.Has("ver") {
+		claims.Set("ver", j.version)
+	}
+	if j.jtiGenerator != nil && !claims.Has("jti") {
+		claims.SetJWTID(j.jtiGenerator())
+	}
+	if ttl > 0 {
+		if j.msPrecision {
+			claims.Set("exp", numericDate(at.Add(ttl)))
+		} else {
+			claims.SetExpiration(at.Add(ttl))
+		}
+	}
+	if len(j.encryptNames) > 0 {
+		if claims, err = j.encryptClaims(claims); err != nil {
+			return "", err
+		}
+	}
+	if j.compressThreshold > 0 {
+		if claims, err = j.compressClaims(claims); err != nil {
+			return "", err
+		}
+	}
+	if j.sortedClaims {
+		if claims, err = sortClaims(claims); err != nil {
+			return "", err
 		}
-	} else if j.expiresIn > 0 {
-		claims.SetExpiration(time.Now().Add(j.expiresIn))
 	}
 
-	var key interface{} = j.keys[0]
-	return Sign(claims, j.method, key)
+	if j.store != nil {
+		return j.signReference(claims, ttl)
+	}
+	keys, err := j.getKeys()
+	if err != nil {
+		return "", err
+	}
+	token, err := j.sign(claims, keys[0])
+	if err == nil && j.nestedKey != nil {
+		token, err = encryptNested(j.nestedKey, token)
+	}
+	return token, err
+}
+
+func (j *JWT) signReference(claims josejwt.Claims, ttl time.Duration) (string, error) {
+	ref, err := newReference()
+	if err == nil {
+		err = j.store.Save(ref, claims, ttl)
+	}
+	if err != nil {
+		return "", err
+	}
+	return ref, nil
+}
+
+// RegisterTemplate registers named base claims, so that standard fields of each token category
+// can be defined once and be used by SignWithTemplate.
+//
+//  jwter.RegisterTemplate("service", map[string]interface{}{"typ": "service", "scope": "internal"})
+//
+func (j *JWT) RegisterTemplate(name string, base map[string]interface{}) {
+	if name == "" || base == nil {
+		panic(errors.New("invalid template"))
+	}
+	if j.templates == nil {
+		j.templates = make(map[string]josejwt.Claims)
+	}
+	j.templates[name] = josejwt.Claims(base)
+}
+
+// SignWithTemplate creates a JWT token with the named template merged with the given content,
+// the content takes precedence over the template. The template itself is never modified.
+//
+//  token, err := jwter.SignWithTemplate("service", map[string]interface{}{"sub": "billing"})
+//
+func (j *JWT) SignWithTemplate(name string, content map[string]interface{}, expiresIn ...time.Duration) (string, error) {
+	base, ok := j.templates[name]
+	if !ok {
+		return "", errors.New("template not found: " + name)
+	}
+	claims := make(josejwt.Claims, len(base)+len(content))
+	for key, val := range base {
+		claims[key] = val
+	}
+	for key, val := range content {
+		claims[key] = val
+	}
+	return j.Sign(claims, expiresIn...)
 }
 
 // Decode parse a string token, but don't validate it.
+// In reference token mode, it returns the stored claims.
+// For nested tokens (header "cty": "JWT"), it returns the innermost claims.
 func (j *JWT) Decode(token string) (josejwt.Claims, error) {
+	if j.store != nil {
+		return j.store.Load(token)
+	}
+	token, err := decryptNested(j.nestedKey, token)
+	if err == nil {
+		token, err = decodeNested(token)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !j.allowDuplicates {
+		if err = checkDuplicateKeys(token); err != nil {
+			return nil, err
+		}
+	}
 	return Decode(token)
 }
 
 // Verify parse a string token and validate it with keys, signingMethods and validator in rotationally.
-func (j *JWT) Verify(token string) (claims josejwt.Claims, err error) {
+// In reference token mode, it resolves the token from the Store and validates the stored claims.
+// For nested tokens (header "cty": "JWT"), every layer is verified and the innermost claims are returned.
+// Encrypted nested tokens are decrypted first, see SetNestedEncryption.
+func (j *JWT) Verify(token string) (josejwt.Claims, error) {
+	t, err := j.VerifyToken(token)
+	if err != nil {
+		return nil, err
+	}
+	return t.Claims, nil
+}
+
+// VerifyToken verifies the token as Verify, and returns the verified Token with the header
+// and verification metadata besides claims.
+//
+//  t, err := jwter.VerifyToken(token)
+//  logger.Info("verified token", t.KeyID, t.Claims.Get("sub"))
+//
+func (j *JWT) VerifyToken(token string) (*Token, error) {
+	return j.VerifyContext(context.Background(), token)
+}
+
+// VerifyContext verifies the token as VerifyToken, the ctx is passed to context validators,
+// see AddContextValidator. When the ctx is done, such as the client of the request has gone away,
+// the verification is abandoned: waiting for the KeySource (remote JWKS fetch, etc.) and trying
+// the remaining keys are stopped, and the ctx's error is returned.
+func (j *JWT) VerifyContext(ctx context.Context, token string) (t *Token, err error) {
+	switch {
+	case ctx.Err() != nil: // the caller has gone away, such as the client closed the connection
+		err = ctx.Err()
+	case j.store != nil:
+		var claims josejwt.Claims
+		if claims, err = j.verifyReference(token); err == nil {
+			t = &Token{Raw: token, Claims: claims, KeyIndex: -1}
+		}
+	default:
+		t, err = j.verifyToken(ctx, token)
+	}
+	if err == nil {
+		err = j.checkClaims(t.Claims)
+	}
+	for i := 0; err == nil && i < len(j.ctxValidators); i++ {
+		err = j.ctxValidators[i].Validate(ctx, t.Claims)
+	}
+	if err == nil {
+		return t, nil
+	}
+
+	return nil, newError(err)
+}
+
+func (j *JWT) verifyToken(ctx context.Context, raw string) (*Token, error) {
+	token, err := decryptNested(j.nestedKey, raw)
+	if err == nil {
+		token, err = j.unwrapNested(token)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !j.allowDuplicates {
+		if err = checkDuplicateKeys(token); err != nil {
+			return nil, err
+		}
+	}
 	jwtToken, err := josejws.ParseJWT([]byte(token))
+	if err == nil && j.lenientTime {
+		err = normalizeTimeClaims(jwtToken.Claims())
+	}
+	if err != nil {
+		return nil, err
+	}
 
+	t := &Token{Raw: raw, Header: headerOf(jwtToken), Claims: jwtToken.Claims(), parsed: jwtToken}
+	t.KeyID, _ = t.Header.Get("kid").(string)
+	var keys rotating
+	if keys, err = j.getVerifyKeysContext(ctx, t.Header); err == nil {
+		t.KeyIndex, err = verifySignature(ctx, jwtToken, j.method, keys)
+	}
+	if err != nil && j.backupKeys != nil && ctx.Err() == nil {
+		t.KeyIndex, err = verifySignature(ctx, jwtToken, j.backupMethod, j.backupKeys)
+		t.Backup = true
+	}
+	// validators run on the restored claims, so they can check compressed and encrypted claims.
 	if err == nil {
-		claims, err = Verify(jwtToken, j.method, j.keys, j.validator...)
-		if err != nil && j.backupKeys != nil {
-			claims, err = Verify(jwtToken, j.backupMethod, j.backupKeys, j.validator...)
+		t.Claims, err = j.restoreClaims(t.Claims)
+	}
+	if err == nil {
+		restore := func() {}
+		if j.msPrecision {
+			restore = relaxExpiration(t.Claims)
 		}
-		if err == nil {
-			return claims, nil
+		err = claimsJWT(t.Claims).Validate(nil, nil, j.validators()...)
+		restore()
+	}
+	if err == nil && j.msPrecision {
+		err = j.checkPreciseTime(t.Claims)
+	}
+	if err == nil {
+		err = j.checkHeader(t.Header)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// checkClaims runs the built-in claims checks after the token is verified.
+func (j *JWT) checkClaims(claims josejwt.Claims) error {
+	if err := checkAudienceClaim(claims); err != nil {
+		return err
+	}
+	if err := j.checkIssuedAt(claims); err != nil {
+		return err
+	}
+	if len(j.issuerPatterns) > 0 || len(j.expectedIssuers) > 0 {
+		if err := j.checkIssuer(claims); err != nil {
+			return err
+		}
+	}
+	if len(j.expectedAudience) > 0 && !containsAudience(claims, j.expectedAudience) {
+		return ErrBadAudience
+	}
+	if len(j.subjectFormats) > 0 {
+		if err := j.checkSubject(claims); err != nil {
+			return err
 		}
 	}
+	if err := j.checkVersion(claims); err != nil {
+		return err
+	}
+	if err := j.checkRevoked(claims); err != nil {
+		return err
+	}
+	if len(j.authorizedParties) > 0 {
+		if err := checkAuthorizedParty(claims, j.authorizedParties); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkIssuedAt rejects tokens issued in the future beyond the validator's NBF leeway. It is only
+// enabled when the leeway is configured by SetValidator, tokens with "iat" in the future are accepted
+// by default.
+func (j *JWT) checkIssuedAt(claims josejwt.Claims) error {
+	if len(j.validator) == 0 || j.validator[0].NBF <= 0 {
+		return nil
+	}
+	iat, ok := claims.IssuedAt()
+	if ok && iat.After(j.now().Add(j.validator[0].NBF)) {
+		return errTokenUsedBeforeIssued
+	}
+	return nil
+}
+
+// VerifyForAudience verifies the token as Verify, and then requires claim "aud" containing
+// at least one of the given audiences, so a shared JWT instance can enforce route-specific audiences.
+// Audiences can be patterns with "*" matching any characters except "/", for platforms where
+// each environment or service gets its own audience URL.
+//
+//  claims, err := jwter.VerifyForAudience(token, "billing-api")
+//  claims, err := jwter.VerifyForAudience(token, "https://*.internal.example.com")
+//
+func (j *JWT) VerifyForAudience(token string, audience ...string) (josejwt.Claims, error) {
+	claims, err := j.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+	if !hasAudience(claims, audience) {
+		return nil, newError(ErrBadAudience)
+	}
+	return claims, nil
+}
+
+func (j *JWT) verifyReference(ref string) (josejwt.Claims, error) {
+	claims, err := j.store.Load(ref)
+	if err == nil {
+		claims, err = j.restoreClaims(claims)
+	}
+	if err == nil {
+		err = claimsJWT(claims).Validate(nil, nil, j.validators()...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
 
-	return nil, &textproto.Error{Code: 401, Msg: err.Error()}
+// SetStore switches jwt to reference token mode: Sign saves the claims to the store and
+// returns a random reference as token, Verify resolves the reference from the store.
+// Tokens can be revoked instantly by Revoke method.
+func (j *JWT) SetStore(store Store) {
+	if store == nil {
+		panic(errors.New("invalid store"))
+	}
+	j.store = store
+}
+
+// Revoke revokes a reference token instantly. It only works in reference token mode.
+func (j *JWT) Revoke(token string) error {
+	if j.store == nil {
+		return errors.New("no store for revocation")
+	}
+	return j.store.Delete(token)
 }
 
 // SetIssuer set a issuer to jwt.
 // Default to "", no "iss" will be added.
+// It only affects signing, use SetExpectedIssuer to check "iss" at verification.
 func (j *JWT) SetIssuer(issuer string) {
 	j.issuer = issuer
 }
 
 // SetAudience sets claim "aud" per its type in
 // https://tools.ietf.org/html/rfc7519#section-4.1.3
+// It only affects signing, use SetExpectedAudience to check "aud" at verification.
 func (j *JWT) SetAudience(audience ...string) {
 	j.audience = audience
 }
 
+// SetAuthorizedParties enables claim "azp" (authorized party) validation per OpenID Connect Core 1.0:
+// when the token has multiple audiences, "azp" is required; when "azp" is present,
+// it must be one of the given client IDs.
+// https://openid.net/specs/openid-connect-core-1_0.html#IDTokenValidation
+func (j *JWT) SetAuthorizedParties(clientIDs ...string) {
+	j.authorizedParties = clientIDs
+}
+
 // GetExpiresIn returns jwt's expiration.
 func (j *JWT) GetExpiresIn() time.Duration {
 	return j.expiresIn
@@ -119,6 +489,20 @@ func (j *JWT) SetExpiresIn(expiresIn time.Duration) {
 	j.expiresIn = expiresIn
 }
 
+// SetExpiresInFunc set a function to compute the expire duration from the claims being signed.
+// It takes precedence over SetExpiresIn, and the expiresIn argument of Sign takes precedence over it.
+//
+//  jwter.SetExpiresInFunc(func(claims josejwt.Claims) time.Duration {
+//  	if claims.Get("role") == "admin" {
+//  		return 15 * time.Minute
+//  	}
+//  	return 24 * time.Hour
+//  })
+//
+func (j *JWT) SetExpiresInFunc(fn func(claims josejwt.Claims) time.Duration) {
+	j.expiresInFn = fn
+}
+
 // SetKeys set new keys to jwt.
 // [deprecated] Please use SetSigning method.
 func (j *JWT) SetKeys(keys ...interface{}) {
@@ -126,6 +510,8 @@ func (j *JWT) SetKeys(keys ...interface{}) {
 		panic(errors.New("invalid keys"))
 	}
 	j.keys = keys
+	j.keySource = nil
+	j.signKID = ""
 }
 
 // SetMethods set one or more signing methods which can be used rotational.
@@ -138,6 +524,7 @@ func (j *JWT) SetMethods(method josecrypto.SigningMethod) {
 }
 
 // SetValidator set a custom jwt.Validator to jwt. Default to nil.
+// The validator's EXP and NBF leeway are applied to "exp", and to "nbf" and "iat" respectively.
 func (j *JWT) SetValidator(validator *josejwt.Validator) {
 	if validator == nil {
 		panic(errors.New("invalid validator"))
@@ -146,6 +533,7 @@ func (j *JWT) SetValidator(validator *josejwt.Validator) {
 }
 
 // SetSigning add signing method and keys.
+// It panics if keys don't match the signing method, see CheckKey.
 func (j *JWT) SetSigning(method josecrypto.SigningMethod, keys ...interface{}) {
 	if len(keys) == 0 || keys[0] == nil {
 		panic(errors.New("invalid keys"))
@@ -153,11 +541,15 @@ func (j *JWT) SetSigning(method josecrypto.SigningMethod, keys ...interface{}) {
 	if method == nil {
 		panic(errors.New("invalid signing method"))
 	}
+	mustCheckKeys(method, keys)
 	j.method = method
 	j.keys = keys
+	j.keySource = nil
+	j.signKID = ""
 }
 
 // SetBackupSigning add a backup signing for Verify method, not for Sign method.
+// It panics if keys don't match the signing method, see CheckKey.
 func (j *JWT) SetBackupSigning(method josecrypto.SigningMethod, keys ...interface{}) {
 	if len(keys) == 0 || keys[0] == nil {
 		panic(errors.New("invalid keys"))
@@ -165,6 +557,7 @@ func (j *JWT) SetBackupSigning(method josecrypto.SigningMethod, keys ...interfac
 	if method == nil {
 		panic(errors.New("invalid signing method"))
 	}
+	mustCheckKeys(method, keys)
 	j.backupMethod = method
 	j.backupKeys = keys
 }
@@ -194,20 +587,43 @@ func Decode(token string) (josejwt.Claims, error) {
 }
 
 // Verify parse a string token and validate it with keys, signingMethods in rotationally.
-func Verify(token josejwt.JWT, method josecrypto.SigningMethod, keys []interface{}, v ...*josejwt.Validator) (claims josejwt.Claims, err error) {
-	if rotating(keys).Verify(func(key interface{}) bool {
-		if k, ok := key.(KeyPair); ok { // try to extract PublicKey
-			key = k.PublicKey
-		}
-		if err = token.Validate(key, method, v...); err == nil {
-			claims = token.Claims()
-			return true
-		}
-		return false
-	}) >= 0 {
-		return claims, nil
+func Verify(token josejwt.JWT, method josecrypto.SigningMethod, keys []interface{}, v ...*josejwt.Validator) (josejwt.Claims, error) {
+	if _, err := verifyWithKeys(context.Background(), token, method, keys, v...); err != nil {
+		return nil, err
 	}
-	return nil, err
+	return token.Claims(), nil
+}
+
+// verifyWithKeys validates the token with keys in rotationally, and returns the index of the key verified it.
+// It stops trying keys when the ctx is done.
+func verifyWithKeys(ctx context.Context, token josejwt.JWT, method josecrypto.SigningMethod, keys rotating, v ...*josejwt.Validator) (int, error) {
+	return keys.try(ctx, func(key interface{}) error {
+		return token.Validate(key, method, v...)
+	})
+}
+
+// verifySignature verifies the signature of the token with keys in rotationally as verifyWithKeys,
+// but the claims are not validated.
+func verifySignature(ctx context.Context, token josejwt.JWT, method josecrypto.SigningMethod, keys rotating) (int, error) {
+	jws, ok := token.(josejws.JWS)
+	if !ok {
+		return -1, josejws.ErrIsNotJWT
+	}
+	return keys.try(ctx, func(key interface{}) error {
+		return jws.Verify(key, method)
+	})
+}
+
+// restoreClaims returns the claims as they were before Sign encrypted and compressed them.
+func (j *JWT) restoreClaims(claims josejwt.Claims) (res josejwt.Claims, err error) {
+	res = claims
+	if j.compressThreshold > 0 {
+		res, err = decompressClaims(res)
+	}
+	if err == nil && len(j.encryptNames) > 0 {
+		res, err = j.decryptClaims(res)
+	}
+	return
 }
 
 type rotating []interface{}
@@ -221,6 +637,98 @@ func (r rotating) Verify(v func(interface{}) bool) (index int) {
 	return -1
 }
 
+// try calls fn with the keys in rotationally until one succeeds, and returns the index of the key.
+// It stops trying keys when the ctx is done.
+func (r rotating) try(ctx context.Context, fn func(key interface{}) error) (int, error) {
+	err := errors.New("no keys to verify")
+	index := r.Verify(func(key interface{}) bool {
+		if e := ctx.Err(); e != nil {
+			err = e
+			return false
+		}
+		if k, ok := key.(KeyPair); ok { // try to extract PublicKey
+			key = k.PublicKey
+		}
+		err = fn(key)
+		return err == nil
+	})
+	if index < 0 {
+		return index, err
+	}
+	return index, nil
+}
+
+// hasAudience reports whether claim "aud" matches any of the audience patterns.
+func hasAudience(claims josejwt.Claims, audience []string) bool {
+	aud, ok := claims.Audience()
+	if !ok {
+		return false
+	}
+	for _, a := range audience {
+		for _, b := range aud {
+			if matchPattern(a, b) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func checkAuthorizedParty(claims josejwt.Claims, clientIDs []string) error {
+	azp, ok := claims.Get("azp").(string)
+	if !ok {
+		if claims.Has("azp") {
+			return errInvalidAZPClaim
+		}
+		if aud, _ := claims.Audience(); len(aud) > 1 {
+			return errors.New(`claim "azp" is required for multiple audiences`)
+		}
+		return nil
+	}
+	for _, id := range clientIDs {
+		if azp == id {
+			return nil
+		}
+	}
+	return errInvalidAZPClaim
+}
+
+var errInvalidAZPClaim = errors.New(`claim "azp" is invalid`)
+var errTokenUsedBeforeIssued = errors.New("token used before issued")
+
+// ToClaims converts map or struct content to claims. Maps are used as is,
+// other values are marshaled to JSON and unmarshaled to claims by json tags.
+func ToClaims(content interface{}) (josejwt.Claims, error) {
+	switch v := content.(type) {
+	case josejwt.Claims:
+		if v != nil {
+			return v, nil
+		}
+	case josejws.Claims:
+		if v != nil {
+			return josejwt.Claims(v), nil
+		}
+	case map[string]interface{}:
+		if v != nil {
+			return josejwt.Claims(v), nil
+		}
+	case nil:
+	default:
+		buf, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		claims := josejwt.Claims{}
+		if err = json.Unmarshal(buf, (*map[string]interface{})(&claims)); err != nil {
+			return nil, errors.New("content can't be converted to claims: " + err.Error())
+		}
+		if claims != nil {
+			return claims, nil
+		}
+	}
+	return josejwt.Claims{}, nil
+}
+
 // StrToKeys converts string slice to keys slice.
 func StrToKeys(keys ...string) (res []interface{}) {
 	for _, key := range keys {
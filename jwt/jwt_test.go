@@ -100,6 +100,38 @@ func TestJWT(t *testing.T) {
 		assert.Equal("OK", claims.Get("test"))
 	})
 
+	t.Run("Sign with struct", func(t *testing.T) {
+		assert := assert.New(t)
+
+		type User struct {
+			ID    string   `json:"sub"`
+			Name  string   `json:"name"`
+			Roles []string `json:"roles,omitempty"`
+			Age   int      `json:"-"`
+		}
+
+		jwter := New([]byte("key1"))
+		jwter.SetIssuer("Gear")
+		token, err := jwter.Sign(&User{ID: "123", Name: "gear", Age: 10})
+		assert.Nil(err)
+		claims, _ := jwter.Verify(token)
+		assert.Equal("123", claims.Get("sub"))
+		assert.Equal("gear", claims.Get("name"))
+		assert.Equal("Gear", claims.Get("iss"))
+		assert.False(claims.Has("roles"))
+		assert.False(claims.Has("Age"))
+
+		token, err = jwter.Sign(nil)
+		assert.Nil(err)
+		claims, _ = jwter.Verify(token)
+		assert.Equal("Gear", claims.Get("iss"))
+
+		_, err = jwter.Sign([]string{"a"})
+		assert.NotNil(err)
+		_, err = jwter.Sign(make(chan int))
+		assert.NotNil(err)
+	})
+
 	t.Run("Sign with custom expiresIn", func(t *testing.T) {
 		assert := assert.New(t)
 
@@ -179,6 +211,102 @@ func TestJWT(t *testing.T) {
 		assert.Equal("Gear", claims.Get("aud"))
 	})
 
+	t.Run("VerifyForAudience", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"))
+		token, _ := jwter.Sign(map[string]interface{}{"test": "OK"})
+		_, err := jwter.VerifyForAudience(token, "billing-api")
+		assert.NotNil(err)
+
+		jwter.SetAudience("billing-api", "orders-api")
+		token, _ = jwter.Sign(map[string]interface{}{"test": "OK"})
+		claims, err := jwter.VerifyForAudience(token, "orders-api")
+		assert.Nil(err)
+		assert.Equal("OK", claims.Get("test"))
+		_, err = jwter.VerifyForAudience(token, "users-api")
+		assert.NotNil(err)
+
+		_, err = jwter.VerifyForAudience(token[1:], "orders-api")
+		assert.NotNil(err)
+	})
+
+	t.Run("SetAuthorizedParties", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"))
+		jwter.SetAuthorizedParties("web", "mobile")
+
+		token, _ := jwter.Sign(map[string]interface{}{"aud": "api"})
+		_, err := jwter.Verify(token)
+		assert.Nil(err)
+
+		token, _ = jwter.Sign(map[string]interface{}{"aud": []string{"api", "web"}})
+		_, err = jwter.Verify(token)
+		assert.NotNil(err)
+
+		token, _ = jwter.Sign(map[string]interface{}{"aud": []string{"api", "web"}, "azp": "web"})
+		claims, err := jwter.Verify(token)
+		assert.Nil(err)
+		assert.Equal("web", claims.Get("azp"))
+
+		token, _ = jwter.Sign(map[string]interface{}{"aud": "api", "azp": "cli"})
+		_, err = jwter.Verify(token)
+		assert.NotNil(err)
+
+		token, _ = jwter.Sign(map[string]interface{}{"aud": "api", "azp": 1})
+		_, err = jwter.Verify(token)
+		assert.NotNil(err)
+	})
+
+	t.Run("should reject iat in the future with leeway", func(t *testing.T) {
+		assert := assert.New(t)
+
+		// iat in the future is accepted without leeway
+		jwter := New([]byte("key1"))
+		token, _ := jwter.Sign(map[string]interface{}{"iat": time.Now().Add(2 * time.Second).Unix()})
+		claims, err := jwter.Verify(token)
+		assert.Nil(err)
+		assert.True(claims.Has("iat"))
+		token, _ = jwter.Sign(map[string]interface{}{"iat": time.Now().Add(time.Minute).Unix()})
+		_, err = jwter.Verify(token)
+		assert.Nil(err)
+
+		jwter.SetValidator(&josejwt.Validator{EXP: 2 * time.Minute, NBF: 2 * time.Minute})
+		_, err = jwter.Verify(token)
+		assert.Nil(err)
+
+		token, _ = jwter.Sign(map[string]interface{}{"iat": time.Now().Add(time.Hour).Unix()})
+		_, err = jwter.Verify(token)
+		assert.Contains(err.Error(), "token used before issued")
+	})
+
+	t.Run("SignAt", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"))
+		jwter.SetJTIGenerator(nil)
+		at := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+		token1, err := jwter.SignAt(at, map[string]interface{}{"test": "OK"}, time.Hour)
+		assert.Nil(err)
+		token2, _ := jwter.SignAt(at, map[string]interface{}{"test": "OK"}, time.Hour)
+		assert.Equal(token1, token2)
+
+		claims, _ := jwter.Decode(token1)
+		iat, _ := claims.IssuedAt()
+		assert.Equal(at.Unix(), iat.Unix())
+		exp, _ := claims.Expiration()
+		assert.Equal(at.Add(time.Hour).Unix(), exp.Unix())
+		_, err = jwter.Verify(token1)
+		assert.Contains(err.Error(), "token is expired")
+
+		token, _ := jwter.SignAt(time.Now().Add(-time.Minute), map[string]interface{}{"test": "OK"}, time.Hour)
+		claims, err = jwter.Verify(token)
+		assert.Nil(err)
+		iat, _ = claims.IssuedAt()
+		assert.True(iat.Before(time.Now().Add(-time.Second * 30)))
+	})
+
 	t.Run("SetExpiresIn", func(t *testing.T) {
 		assert := assert.New(t)
 
@@ -202,6 +330,62 @@ func TestJWT(t *testing.T) {
 		assert.NotNil(err)
 	})
 
+	t.Run("SetExpiresInFunc", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"))
+		jwter.SetExpiresIn(time.Hour)
+		jwter.SetExpiresInFunc(func(claims josejwt.Claims) time.Duration {
+			if claims.Get("role") == "admin" {
+				return time.Minute
+			}
+			return 0
+		})
+
+		now := time.Now().Unix()
+		token, _ := jwter.Sign(josejwt.Claims{"role": "admin"})
+		claims, _ := jwter.Verify(token)
+		exp, _ := claims.Expiration()
+		assert.True(exp.Unix() >= now+60 && exp.Unix() < now+120)
+
+		token, _ = jwter.Sign(josejwt.Claims{"role": "user"})
+		claims, _ = jwter.Verify(token)
+		assert.False(claims.Has("exp"))
+
+		token, _ = jwter.Sign(josejwt.Claims{"role": "admin"}, time.Hour)
+		claims, _ = jwter.Verify(token)
+		exp, _ = claims.Expiration()
+		assert.True(exp.Unix() >= now+3600)
+
+		jwter.SetExpiresInFunc(nil)
+		token, _ = jwter.Sign(josejwt.Claims{"role": "user"})
+		claims, _ = jwter.Verify(token)
+		exp, _ = claims.Expiration()
+		assert.True(exp.Unix() >= now+3600)
+	})
+
+	t.Run("RegisterTemplate", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"))
+		assert.Panics(func() {
+			jwter.RegisterTemplate("service", nil)
+		})
+		_, err := jwter.SignWithTemplate("service", nil)
+		assert.NotNil(err)
+
+		base := map[string]interface{}{"typ": "service", "scope": "internal"}
+		jwter.RegisterTemplate("service", base)
+		token, err := jwter.SignWithTemplate("service", map[string]interface{}{"sub": "billing", "scope": "billing"})
+		assert.Nil(err)
+		claims, _ := jwter.Verify(token)
+		assert.Equal("service", claims.Get("typ"))
+		assert.Equal("billing", claims.Get("scope"))
+		assert.Equal("billing", claims.Get("sub"))
+		assert.Equal(2, len(base))
+		assert.Equal("internal", base["scope"])
+	})
+
 	t.Run("SetKeys", func(t *testing.T) {
 		assert := assert.New(t)
 
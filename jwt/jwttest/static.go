@@ -0,0 +1,92 @@
+// Package jwttest provides a static jwt.Signer and jwt.Verifier implementation,
+// so that handler tests don't need real keys at all.
+package jwttest
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/teambition/gear-auth/jwt"
+)
+
+// Static is a jwt.Signer and jwt.Verifier that returns canned claims or errors per token string.
+//
+//  verifier := jwttest.NewStatic().
+//  	Add("alice", josejwt.Claims{"sub": "alice"}).
+//  	AddError("expired", jwt.ErrTokenExpired)
+//  auther.SetVerifier(verifier)
+//
+type Static struct {
+	mu      sync.RWMutex
+	results map[string]result
+	count   int
+}
+
+type result struct {
+	claims josejwt.Claims
+	err    error
+}
+
+// NewStatic returns a Static instance.
+func NewStatic() *Static {
+	return &Static{results: make(map[string]result)}
+}
+
+// Add registers claims for the token.
+func (s *Static) Add(token string, claims josejwt.Claims) *Static {
+	s.mu.Lock()
+	s.results[token] = result{claims: claims}
+	s.mu.Unlock()
+	return s
+}
+
+// AddError registers an error for the token, Verify returns it as a 401 error like jwt.JWT.
+func (s *Static) AddError(token string, err error) *Static {
+	s.mu.Lock()
+	s.results[token] = result{err: &jwt.Error{Code: 401, Err: err}}
+	s.mu.Unlock()
+	return s
+}
+
+// Sign implements the jwt.Signer interface. It returns a new opaque token ("static-1", "static-2", ...)
+// registered with the content, so that it can be verified by the same Static instance.
+// expiresIn is ignored.
+func (s *Static) Sign(content interface{}, expiresIn ...time.Duration) (string, error) {
+	claims, err := jwt.ToClaims(content)
+	if err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.count++
+	token := "static-" + strconv.Itoa(s.count)
+	s.results[token] = result{claims: claims}
+	return token, nil
+}
+
+// Verify implements the jwt.Verifier interface. It returns a copy of the registered claims,
+// or a 401 error for unknown tokens.
+func (s *Static) Verify(token string) (josejwt.Claims, error) {
+	s.mu.RLock()
+	res, ok := s.results[token]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, &jwt.Error{Code: 401, Err: errors.New("unknown token")}
+	}
+	if res.err != nil {
+		return nil, res.err
+	}
+	claims := make(josejwt.Claims, len(res.claims))
+	for key, val := range res.claims {
+		claims[key] = val
+	}
+	return claims, nil
+}
+
+var (
+	_ jwt.Signer   = (*Static)(nil)
+	_ jwt.Verifier = (*Static)(nil)
+)
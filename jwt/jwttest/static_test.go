@@ -0,0 +1,50 @@
+package jwttest
+
+import (
+	"errors"
+	"testing"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatic(t *testing.T) {
+	t.Run("Verify", func(t *testing.T) {
+		assert := assert.New(t)
+
+		verifier := NewStatic().
+			Add("alice", josejwt.Claims{"sub": "alice"}).
+			AddError("expired", errors.New("token is expired"))
+
+		claims, err := verifier.Verify("alice")
+		assert.Nil(err)
+		assert.Equal("alice", claims.Get("sub"))
+		claims.Set("sub", "bob")
+		claims, _ = verifier.Verify("alice")
+		assert.Equal("alice", claims.Get("sub"))
+
+		_, err = verifier.Verify("expired")
+		assert.Equal("token is expired", err.Error())
+		_, err = verifier.Verify("xxx")
+		assert.Equal("unknown token", err.Error())
+	})
+
+	t.Run("Sign", func(t *testing.T) {
+		assert := assert.New(t)
+
+		signer := NewStatic()
+		token1, err := signer.Sign(map[string]interface{}{"test": "OK"})
+		assert.Nil(err)
+		token2, _ := signer.Sign(map[string]interface{}{"test": "OK2"})
+		assert.NotEqual(token1, token2)
+
+		claims, err := signer.Verify(token1)
+		assert.Nil(err)
+		assert.Equal("OK", claims.Get("test"))
+		claims, _ = signer.Verify(token2)
+		assert.Equal("OK2", claims.Get("test"))
+
+		_, err = signer.Sign(func() {})
+		assert.NotNil(err)
+	})
+}
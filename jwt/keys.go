@@ -0,0 +1,66 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"fmt"
+
+	josecrypto "github.com/SermoDigital/jose/crypto"
+)
+
+// CheckKey type-checks a key against the signing method, so misconfiguration surfaces at configuration
+// time rather than on the first Sign or Verify. HMAC methods require []byte, RSA, RSA-PSS, ECDSA and EdDSA
+// methods require KeyPair (PrivateKey can be omitted for verify only) or a public key,
+// EdDSA accepts a private key as well.
+// Keys of other signing methods are not checked.
+func CheckKey(method josecrypto.SigningMethod, key interface{}) error {
+	var private, public string
+	var isPrivate, isPublic func(interface{}) bool
+	switch method.(type) {
+	case *josecrypto.SigningMethodHMAC:
+		if k, ok := key.([]byte); !ok || len(k) == 0 {
+			return fmt.Errorf("%s requires non-empty []byte, got %T", method.Alg(), key)
+		}
+		return nil
+	case *josecrypto.SigningMethodRSA, *josecrypto.SigningMethodRSAPSS:
+		private, public = "*rsa.PrivateKey", "*rsa.PublicKey"
+		isPrivate = func(k interface{}) bool { _, ok := k.(*rsa.PrivateKey); return ok }
+		isPublic = func(k interface{}) bool { _, ok := k.(*rsa.PublicKey); return ok }
+	case *josecrypto.SigningMethodECDSA:
+		private, public = "*ecdsa.PrivateKey", "*ecdsa.PublicKey"
+		isPrivate = func(k interface{}) bool { _, ok := k.(*ecdsa.PrivateKey); return ok }
+		isPublic = func(k interface{}) bool { _, ok := k.(*ecdsa.PublicKey); return ok }
+	case *signingMethodEdDSA:
+		private, public = "ed25519.PrivateKey", "ed25519.PublicKey"
+		isPrivate = func(k interface{}) bool { _, ok := edPrivateKey(k); return ok }
+		isPublic = func(k interface{}) bool { _, ok := edPublicKey(k); return ok }
+		if isPrivate(key) { // it can verify as well
+			return nil
+		}
+	default:
+		return nil
+	}
+
+	kp, ok := key.(KeyPair)
+	if !ok {
+		if isPublic(key) {
+			return nil
+		}
+		return fmt.Errorf("%s requires KeyPair or %s, got %T", method.Alg(), public, key)
+	}
+	if kp.PrivateKey != nil && !isPrivate(kp.PrivateKey) {
+		return fmt.Errorf("%s requires %s, got %T", method.Alg(), private, kp.PrivateKey)
+	}
+	if !isPublic(kp.PublicKey) {
+		return fmt.Errorf("%s requires %s, got %T", method.Alg(), public, kp.PublicKey)
+	}
+	return nil
+}
+
+func mustCheckKeys(method josecrypto.SigningMethod, keys []interface{}) {
+	for _, key := range keys {
+		if err := CheckKey(method, key); err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	josecrypto "github.com/SermoDigital/jose/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeys(t *testing.T) {
+	t.Run("CheckKey", func(t *testing.T) {
+		assert := assert.New(t)
+
+		rsaKey, _ := rsa.GenerateKey(rand.Reader, 1024)
+		ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+		assert.Nil(CheckKey(josecrypto.SigningMethodHS256, []byte("key")))
+		assert.Equal("HS256 requires non-empty []byte, got string",
+			CheckKey(josecrypto.SigningMethodHS256, "key").Error())
+		assert.NotNil(CheckKey(josecrypto.SigningMethodHS256, []byte{}))
+
+		assert.Nil(CheckKey(josecrypto.SigningMethodRS256, KeyPair{PrivateKey: rsaKey, PublicKey: &rsaKey.PublicKey}))
+		assert.Nil(CheckKey(josecrypto.SigningMethodPS256, KeyPair{PublicKey: &rsaKey.PublicKey}))
+		assert.Nil(CheckKey(josecrypto.SigningMethodRS256, &rsaKey.PublicKey))
+		assert.Equal("RS256 requires KeyPair or *rsa.PublicKey, got *rsa.PrivateKey",
+			CheckKey(josecrypto.SigningMethodRS256, rsaKey).Error())
+		assert.Equal("RS256 requires *rsa.PrivateKey, got *ecdsa.PrivateKey",
+			CheckKey(josecrypto.SigningMethodRS256, KeyPair{PrivateKey: ecKey, PublicKey: &rsaKey.PublicKey}).Error())
+
+		assert.Nil(CheckKey(josecrypto.SigningMethodES256, KeyPair{PrivateKey: ecKey, PublicKey: &ecKey.PublicKey}))
+		assert.Equal("ES256 requires KeyPair or *ecdsa.PublicKey, got []uint8",
+			CheckKey(josecrypto.SigningMethodES256, []byte("key")).Error())
+		assert.Equal("ES256 requires *ecdsa.PublicKey, got <nil>",
+			CheckKey(josecrypto.SigningMethodES256, KeyPair{PrivateKey: ecKey}).Error())
+
+		assert.Nil(CheckKey(josecrypto.Unsecured, nil))
+	})
+
+	t.Run("SetSigning should fail fast", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New()
+		assert.Panics(func() {
+			jwter.SetSigning(josecrypto.SigningMethodES256, []byte("key"))
+		})
+		assert.Panics(func() {
+			jwter.SetBackupSigning(josecrypto.SigningMethodHS256, "key")
+		})
+		jwter.SetSigning(josecrypto.SigningMethodHS256, []byte("key"))
+	})
+}
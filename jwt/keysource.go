@@ -0,0 +1,162 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/SermoDigital/jose"
+	josecrypto "github.com/SermoDigital/jose/crypto"
+)
+
+// KeySource provides keys to JWT on demand, instead of static keys. The first key is used to sign,
+// all keys are used to verify in rotationally. See SetKeySource.
+type KeySource interface {
+	Keys() ([]interface{}, error)
+}
+
+// KeyIDSource is a KeySource that can resolve keys by the "kid" header of tokens, such as JWKS.
+// When the JWT's KeySource implements it, tokens with "kid" are verified with KeysByID.
+type KeyIDSource interface {
+	KeySource
+	KeysByID(kid string) ([]interface{}, error)
+}
+
+// SetKeySource set signing method and a KeySource to jwt, keys will be resolved from the source
+// on every Sign and Verify. SetSigning or SetKeys will replace the source with static keys.
+func (j *JWT) SetKeySource(method josecrypto.SigningMethod, source KeySource) {
+	if method == nil {
+		panic(errors.New("invalid signing method"))
+	}
+	if source == nil {
+		panic(errors.New("invalid key source"))
+	}
+	j.method = method
+	j.keySource = source
+	j.signKID = ""
+}
+
+// getKeys returns the static keys or keys from the KeySource.
+func (j *JWT) getKeys() (rotating, error) {
+	if j.keySource == nil {
+		return j.keys, nil
+	}
+	keys, err := j.keySource.Keys()
+	if err == nil && len(keys) == 0 {
+		err = errors.New("no keys from key source")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// getVerifyKeys returns the keys to verify a token with the header.
+func (j *JWT) getVerifyKeys(header jose.Protected) (rotating, error) {
+	if source, ok := j.keySource.(KeyIDSource); ok {
+		if kid, _ := header.Get("kid").(string); kid != "" {
+			return source.KeysByID(kid)
+		}
+	}
+	return j.getKeys()
+}
+
+// getVerifyKeysContext returns the keys as getVerifyKeys, but stops waiting for the KeySource when
+// the ctx is done. The KeySource is shared by all requests, so its work is not canceled.
+func (j *JWT) getVerifyKeysContext(ctx context.Context, header jose.Protected) (rotating, error) {
+	if j.keySource == nil || ctx.Done() == nil {
+		return j.getVerifyKeys(header)
+	}
+	type result struct {
+		keys rotating
+		err  error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		keys, err := j.getVerifyKeys(header)
+		ch <- result{keys, err}
+	}()
+	select {
+	case res := <-ch:
+		return res.keys, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// LazyKeys is a KeySource that resolves keys on first use (or in background by Prefetch) with retry.
+// It is useful for deployments where the secret store isn't reachable at process start.
+type LazyKeys struct {
+	mu      sync.Mutex
+	fetch   func() ([]interface{}, error)
+	retry   time.Duration
+	keys    []interface{}
+	err     error
+	lastTry time.Time
+}
+
+// NewLazyKeys returns a LazyKeys instance. fetch will not be called until the keys are needed,
+// after a failure, it will not be called again within retryInterval, the last error is returned instead.
+// It panics if retryInterval <= 0.
+//
+//  source := jwt.NewLazyKeys(func() ([]interface{}, error) {
+//  	secret, err := secretStore.Get("jwt-key")
+//  	if err != nil {
+//  		return nil, err
+//  	}
+//  	return []interface{}{secret}, nil
+//  }, time.Second*5)
+//  jwter.SetKeySource(josecrypto.SigningMethodHS256, source)
+//
+func NewLazyKeys(fetch func() ([]interface{}, error), retryInterval time.Duration) *LazyKeys {
+	if fetch == nil {
+		panic(errors.New("invalid fetch function"))
+	}
+	if retryInterval <= 0 {
+		panic(errors.New("invalid retry interval"))
+	}
+	return &LazyKeys{fetch: fetch, retry: retryInterval}
+}
+
+// Keys implements the KeySource interface.
+func (l *LazyKeys) Keys() ([]interface{}, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.keys != nil {
+		return l.keys, nil
+	}
+	if !l.lastTry.IsZero() && time.Since(l.lastTry) < l.retry {
+		return nil, l.err
+	}
+	l.lastTry = time.Now()
+	keys, err := l.fetch()
+	if err == nil && len(keys) == 0 {
+		err = errors.New("no keys fetched")
+	}
+	if err != nil {
+		l.err = err
+		return nil, err
+	}
+	l.keys = keys
+	l.err = nil
+	return keys, nil
+}
+
+// Prefetch resolves the keys in background, it retries every retryInterval until succeed.
+func (l *LazyKeys) Prefetch() {
+	go func() {
+		for {
+			if _, err := l.Keys(); err == nil {
+				return
+			}
+			time.Sleep(l.retry)
+		}
+	}()
+}
+
+// Check implements the HealthChecker interface, it reports whether the keys are resolved.
+func (l *LazyKeys) Check() error {
+	_, err := l.Keys()
+	return err
+}
@@ -0,0 +1,102 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	josecrypto "github.com/SermoDigital/jose/crypto"
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeySource(t *testing.T) {
+	t.Run("LazyKeys", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.Panics(func() {
+			NewLazyKeys(nil, time.Second)
+		})
+		assert.Panics(func() {
+			NewLazyKeys(func() ([]interface{}, error) { return nil, nil }, 0)
+		})
+
+		calls := 0
+		reachable := false
+		source := NewLazyKeys(func() ([]interface{}, error) {
+			calls++
+			if !reachable {
+				return nil, errors.New("secret store is unreachable")
+			}
+			return []interface{}{[]byte("key1")}, nil
+		}, 20*time.Millisecond)
+		assert.Equal(0, calls)
+
+		jwter := New()
+		assert.Panics(func() {
+			jwter.SetKeySource(josecrypto.SigningMethodHS256, nil)
+		})
+		jwter.SetKeySource(josecrypto.SigningMethodHS256, source)
+
+		_, err := jwter.Sign(josejwt.Claims{"test": "OK"})
+		assert.Equal("secret store is unreachable", err.Error())
+		assert.NotNil(source.Check())
+		assert.NotNil(jwter.Check())
+		assert.Equal(1, calls)
+
+		reachable = true
+		_, err = jwter.Sign(josejwt.Claims{"test": "OK"})
+		assert.NotNil(err)
+		assert.Equal(1, calls)
+
+		time.Sleep(30 * time.Millisecond)
+		token, err := jwter.Sign(josejwt.Claims{"test": "OK"})
+		assert.Nil(err)
+		claims, err := jwter.Verify(token)
+		assert.Nil(err)
+		assert.Equal("OK", claims.Get("test"))
+		assert.Nil(jwter.Check())
+		assert.Equal(2, calls)
+
+		_, err = New([]byte("key1")).Verify(token)
+		assert.Nil(err)
+	})
+
+	t.Run("LazyKeys Prefetch", func(t *testing.T) {
+		assert := assert.New(t)
+
+		ch := make(chan struct{}, 3)
+		source := NewLazyKeys(func() ([]interface{}, error) {
+			ch <- struct{}{}
+			if len(ch) < 2 {
+				return nil, errors.New("secret store is unreachable")
+			}
+			return []interface{}{[]byte("key1")}, nil
+		}, time.Millisecond)
+		source.Prefetch()
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(2, len(ch))
+		keys, err := source.Keys()
+		assert.Nil(err)
+		assert.Equal(1, len(keys))
+	})
+
+	t.Run("backup signing with key source failure", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New()
+		jwter.SetKeySource(josecrypto.SigningMethodHS256, NewLazyKeys(func() ([]interface{}, error) {
+			return nil, nil
+		}, time.Second))
+		jwter.SetBackupSigning(josecrypto.SigningMethodHS256, []byte("key1"))
+
+		token, _ := New([]byte("key1")).Sign(josejwt.Claims{"test": "OK"})
+		claims, err := jwter.Verify(token)
+		assert.Nil(err)
+		assert.Equal("OK", claims.Get("test"))
+
+		jwter.SetSigning(josecrypto.SigningMethodHS256, []byte("key2"))
+		_, err = jwter.Sign(josejwt.Claims{"test": "OK"})
+		assert.Nil(err)
+	})
+}
@@ -0,0 +1,88 @@
+package jwt
+
+import (
+	"errors"
+	"sort"
+
+	josecrypto "github.com/SermoDigital/jose/crypto"
+	josejws "github.com/SermoDigital/jose/jws"
+	josejwt "github.com/SermoDigital/jose/jwt"
+)
+
+// SetSigningWithKID sets signing method and keys registered with IDs. Sign uses the key of signingKID and
+// adds the "kid" header, Verify picks the exact key by the "kid" header instead of trying every key,
+// and tries all keys in rotationally only for tokens without "kid". Tokens with an unknown "kid" are rejected,
+// so misconfiguration isn't hidden. It panics if keys don't match the signing method, see CheckKey.
+//
+//  jwter.SetSigningWithKID(josecrypto.SigningMethodHS256, map[string]interface{}{
+//  	"2018-01": []byte("old key"),
+//  	"2018-02": []byte("new key"),
+//  }, "2018-02")
+//
+func (j *JWT) SetSigningWithKID(method josecrypto.SigningMethod, keys map[string]interface{}, signingKID string) {
+	if method == nil {
+		panic(errors.New("invalid signing method"))
+	}
+	if keys[signingKID] == nil {
+		panic(errors.New("invalid signing kid"))
+	}
+	source := &kidKeys{kids: make(map[string]interface{}, len(keys))}
+	ids := make([]string, 0, len(keys))
+	for kid, key := range keys {
+		if kid == "" || key == nil {
+			panic(errors.New("invalid keys"))
+		}
+		source.kids[kid] = key
+		if kid != signingKID {
+			ids = append(ids, kid)
+		}
+	}
+	sort.Strings(ids)
+	source.ids = append([]string{signingKID}, ids...)
+	for _, kid := range source.ids {
+		source.keys = append(source.keys, keys[kid])
+	}
+	mustCheckKeys(method, source.keys)
+	j.SetKeySource(method, source)
+	j.signKID = signingKID
+}
+
+// kidKeys is a static KeyIDSource, the signing key is the first.
+type kidKeys struct {
+	keys []interface{}
+	ids  []string // the kid of keys
+	kids map[string]interface{}
+}
+
+// Keys implements the KeySource interface.
+func (k *kidKeys) Keys() ([]interface{}, error) {
+	return k.keys, nil
+}
+
+// KeysByID implements the KeyIDSource interface.
+func (k *kidKeys) KeysByID(kid string) ([]interface{}, error) {
+	if key, ok := k.kids[kid]; ok {
+		return []interface{}{key}, nil
+	}
+	return nil, ErrUnknownKeyID
+}
+
+// sign signs the claims with the key, the "kid" header is added if set.
+func (j *JWT) sign(claims josejwt.Claims, key interface{}) (string, error) {
+	if j.codec != nil {
+		return signWithCodec(j.codec, claims, j.method, key, j.signKID)
+	}
+	if j.signKID == "" {
+		return Sign(claims, j.method, key)
+	}
+	if k, ok := key.(KeyPair); ok {
+		key = k.PrivateKey
+	}
+	token := josejws.NewJWT(josejws.Claims(claims), j.method)
+	token.(josejws.JWS).Protected().Set("kid", j.signKID)
+	buf, err := token.Serialize(key)
+	if err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
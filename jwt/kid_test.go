@@ -0,0 +1,96 @@
+package jwt
+
+import (
+	"testing"
+
+	josecrypto "github.com/SermoDigital/jose/crypto"
+	josejws "github.com/SermoDigital/jose/jws"
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func signHS256WithKID(key []byte, kid string, claims josejwt.Claims) string {
+	token := josejws.NewJWT(josejws.Claims(claims), josecrypto.SigningMethodHS256)
+	if kid != "" {
+		token.(josejws.JWS).Protected().Set("kid", kid)
+	}
+	buf, _ := token.Serialize(key)
+	return string(buf)
+}
+
+func TestSigningWithKID(t *testing.T) {
+	keys := map[string]interface{}{
+		"2018-01": []byte("old key"),
+		"2018-02": []byte("new key"),
+	}
+
+	t.Run("should panic with invalid arguments", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New()
+		assert.Panics(func() {
+			jwter.SetSigningWithKID(nil, keys, "2018-02")
+		})
+		assert.Panics(func() {
+			jwter.SetSigningWithKID(josecrypto.SigningMethodHS256, keys, "2018-03")
+		})
+		assert.Panics(func() {
+			jwter.SetSigningWithKID(josecrypto.SigningMethodHS256, map[string]interface{}{
+				"": []byte("key"), "a": []byte("key"),
+			}, "a")
+		})
+		assert.Panics(func() {
+			jwter.SetSigningWithKID(josecrypto.SigningMethodRS256, keys, "2018-02")
+		})
+	})
+
+	t.Run("should sign with kid and verify by kid", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New()
+		jwter.SetSigningWithKID(josecrypto.SigningMethodHS256, keys, "2018-02")
+		token, err := jwter.Sign(josejwt.Claims{"sub": "alice"})
+		assert.Nil(err)
+		tk, err := jwter.VerifyToken(token)
+		assert.Nil(err)
+		assert.Equal("2018-02", tk.KeyID)
+		assert.Equal("2018-02", tk.Header.Get("kid"))
+		_, err = New([]byte("new key")).Verify(token)
+		assert.Nil(err)
+
+		tk, err = jwter.VerifyToken(signHS256WithKID([]byte("old key"), "2018-01", josejwt.Claims{"sub": "alice"}))
+		assert.Nil(err)
+		assert.Equal("2018-01", tk.KeyID)
+
+		// no rotation for tokens with kid
+		_, err = jwter.Verify(signHS256WithKID([]byte("new key"), "2018-01", josejwt.Claims{"sub": "alice"}))
+		assert.NotNil(err)
+		_, err = jwter.Verify(signHS256WithKID([]byte("new key"), "2018-03", josejwt.Claims{"sub": "alice"}))
+		assert.Equal("unknown kid", err.Error())
+
+		// rotation for tokens without kid
+		tk, err = jwter.VerifyToken(signHS256WithKID([]byte("old key"), "", josejwt.Claims{"sub": "alice"}))
+		assert.Nil(err)
+		assert.Equal(1, tk.KeyIndex)
+
+		jwter.SetCodec(&countingCodec{})
+		token, err = jwter.Sign(josejwt.Claims{"sub": "alice"})
+		assert.Nil(err)
+		tk, err = jwter.VerifyToken(token)
+		assert.Nil(err)
+		assert.Equal("2018-02", tk.KeyID)
+	})
+
+	t.Run("should not add kid after keys replaced", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New()
+		jwter.SetSigningWithKID(josecrypto.SigningMethodHS256, keys, "2018-02")
+		jwter.SetSigning(josecrypto.SigningMethodHS256, []byte("key"))
+		token, err := jwter.Sign(josejwt.Claims{"sub": "alice"})
+		assert.Nil(err)
+		tk, err := jwter.VerifyToken(token)
+		assert.Nil(err)
+		assert.Equal("", tk.KeyID)
+	})
+}
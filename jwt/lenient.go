@@ -0,0 +1,36 @@
+package jwt
+
+import (
+	"fmt"
+	"strconv"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+)
+
+// timeClaims are the NumericDate claims defined in RFC 7519.
+var timeClaims = []string{"exp", "iat", "nbf"}
+
+// SetLenientTime enables compatibility mode for tokens from legacy issuers (some PHP/Node libraries)
+// that encode "exp", "iat" and "nbf" as strings, such as "1500000000" or "1500000000.123".
+// These claims will be normalized to numbers before validation instead of being ignored,
+// and a claim that can't be parsed as a number makes the token invalid.
+// Numbers with fractional seconds are always accepted.
+func (j *JWT) SetLenientTime(lenient bool) {
+	j.lenientTime = lenient
+}
+
+// normalizeTimeClaims converts string-encoded NumericDate claims to float64 in place.
+func normalizeTimeClaims(claims josejwt.Claims) error {
+	for _, name := range timeClaims {
+		str, ok := claims.Get(name).(string)
+		if !ok {
+			continue
+		}
+		val, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s claim: %q", name, str)
+		}
+		claims.Set(name, val)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package jwt
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLenientTime(t *testing.T) {
+	t.Run("should normalize string time claims", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"))
+		expired := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+		token, _ := jwter.Sign(josejwt.Claims{"test": "OK", "exp": expired})
+
+		// exp is ignored in strict mode
+		_, err := jwter.Verify(token)
+		assert.Nil(err)
+
+		jwter.SetLenientTime(true)
+		_, err = jwter.Verify(token)
+		assert.Contains(err.Error(), "token is expired")
+
+		exp := strconv.FormatFloat(float64(time.Now().Add(time.Hour).UnixNano())/1e9, 'f', 3, 64)
+		token, _ = jwter.Sign(josejwt.Claims{"test": "OK", "exp": exp, "iat": 1500000000.5})
+		claims, err := jwter.Verify(token)
+		assert.Nil(err)
+		val, ok := claims.Expiration()
+		assert.True(ok)
+		assert.True(val.After(time.Now()))
+		val, ok = claims.IssuedAt()
+		assert.True(ok)
+		assert.Equal(int64(1500000000), val.Unix())
+
+		nbf := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+		token, _ = jwter.Sign(josejwt.Claims{"test": "OK", "nbf": nbf})
+		_, err = jwter.Verify(token)
+		assert.Contains(err.Error(), "token is not yet valid")
+	})
+
+	t.Run("should reject invalid string time claims", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"))
+		jwter.SetLenientTime(true)
+		token, _ := jwter.Sign(josejwt.Claims{"test": "OK", "exp": "tomorrow"})
+		_, err := jwter.Verify(token)
+		assert.Contains(err.Error(), `invalid exp claim: "tomorrow"`)
+	})
+}
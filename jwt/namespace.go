@@ -0,0 +1,97 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+)
+
+// SetClaimsNamespace requires all non-registered claims written by Sign to live under namespace,
+// preventing collisions with registered claim names. If namespace contains "/" (such as
+// "https://example.com/"), it is a prefix of claim names; otherwise (such as "app") it is the name of
+// a sub-object claim holding the custom claims. Names in allowed are accepted at top level besides
+// the registered claims. Sign returns error for claims out of the namespace.
+// Set "" to disable it. See GetNamespaced and SetNamespaced.
+//
+//  jwter.SetClaimsNamespace("https://example.com/", "azp")
+//  claims := josejwt.Claims{"sub": "alice"}
+//  jwt.SetNamespaced(claims, "https://example.com/", "role", "admin") // "https://example.com/role": "admin"
+//  token, err := jwter.Sign(claims)
+//
+func (j *JWT) SetClaimsNamespace(namespace string, allowed ...string) {
+	j.namespace = namespace
+	j.namespaceAllowed = make(map[string]bool, len(allowed))
+	for _, name := range allowed {
+		j.namespaceAllowed[name] = true
+	}
+}
+
+// checkNamespace returns error if any non-registered claim is out of the namespace.
+func (j *JWT) checkNamespace(claims josejwt.Claims) error {
+	for name, val := range claims {
+		if registeredClaims[name] || name == "ver" || j.namespaceAllowed[name] {
+			continue
+		}
+		if isPrefixNamespace(j.namespace) {
+			if strings.HasPrefix(name, j.namespace) && len(name) > len(j.namespace) {
+				continue
+			}
+		} else if name == j.namespace {
+			if _, ok := namespaceObject(val); ok {
+				continue
+			}
+			return fmt.Errorf("claim %q should be an object", name)
+		}
+		return fmt.Errorf("claim %q is not in namespace %q", name, j.namespace)
+	}
+	return nil
+}
+
+// GetNamespaced returns the custom claim name under namespace, see SetClaimsNamespace.
+//
+//  role := jwt.GetNamespaced(claims, "https://example.com/", "role")
+//  // or
+//  role := jwt.GetNamespaced(claims, "app", "role")
+//
+func GetNamespaced(claims josejwt.Claims, namespace, name string) interface{} {
+	if isPrefixNamespace(namespace) {
+		return claims.Get(namespace + name)
+	}
+	if obj, ok := namespaceObject(claims.Get(namespace)); ok {
+		return obj[name]
+	}
+	return nil
+}
+
+// SetNamespaced sets the custom claim name under namespace, see SetClaimsNamespace.
+func SetNamespaced(claims josejwt.Claims, namespace, name string, val interface{}) {
+	if namespace == "" {
+		panic(errors.New("invalid claims namespace"))
+	}
+	if isPrefixNamespace(namespace) {
+		claims.Set(namespace+name, val)
+		return
+	}
+	obj, ok := namespaceObject(claims.Get(namespace))
+	if !ok {
+		obj = make(map[string]interface{})
+		claims.Set(namespace, obj)
+	}
+	obj[name] = val
+}
+
+func isPrefixNamespace(namespace string) bool {
+	return strings.Contains(namespace, "/")
+}
+
+func namespaceObject(val interface{}) (map[string]interface{}, bool) {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		return v, true
+	case josejwt.Claims:
+		return v, true
+	}
+	return nil, false
+}
@@ -0,0 +1,69 @@
+package jwt
+
+import (
+	"testing"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClaimsNamespace(t *testing.T) {
+	t.Run("should enforce prefix namespace", func(t *testing.T) {
+		assert := assert.New(t)
+
+		ns := "https://example.com/"
+		jwter := New([]byte("key1"))
+		jwter.SetClaimsNamespace(ns, "azp")
+
+		_, err := jwter.Sign(josejwt.Claims{"sub": "alice", "role": "admin"})
+		assert.Equal(`claim "role" is not in namespace "https://example.com/"`, err.Error())
+		_, err = jwter.Sign(josejwt.Claims{"sub": "alice", ns: "admin"})
+		assert.NotNil(err)
+
+		claims := josejwt.Claims{"sub": "alice", "azp": "web"}
+		SetNamespaced(claims, ns, "role", "admin")
+		assert.Equal("admin", claims.Get("https://example.com/role"))
+		token, err := jwter.Sign(claims)
+		assert.Nil(err)
+
+		claims, err = jwter.Verify(token)
+		assert.Nil(err)
+		assert.Equal("admin", GetNamespaced(claims, ns, "role"))
+		assert.Nil(GetNamespaced(claims, ns, "missing"))
+
+		jwter.SetClaimsNamespace("")
+		_, err = jwter.Sign(josejwt.Claims{"sub": "alice", "role": "admin"})
+		assert.Nil(err)
+	})
+
+	t.Run("should enforce object namespace", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"))
+		jwter.SetClaimsNamespace("app")
+		jwter.SetVersion(1)
+
+		_, err := jwter.Sign(josejwt.Claims{"sub": "alice", "role": "admin"})
+		assert.Equal(`claim "role" is not in namespace "app"`, err.Error())
+		_, err = jwter.Sign(josejwt.Claims{"sub": "alice", "app": "admin"})
+		assert.Equal(`claim "app" should be an object`, err.Error())
+
+		claims := josejwt.Claims{"sub": "alice"}
+		assert.Panics(func() {
+			SetNamespaced(claims, "", "role", "admin")
+		})
+		SetNamespaced(claims, "app", "role", "admin")
+		SetNamespaced(claims, "app", "tenant", "acme")
+		token, err := jwter.Sign(claims)
+		assert.Nil(err)
+
+		claims, err = jwter.Verify(token)
+		assert.Nil(err)
+		assert.Equal("admin", GetNamespaced(claims, "app", "role"))
+		assert.Equal("acme", GetNamespaced(claims, "app", "tenant"))
+		assert.Nil(GetNamespaced(claims, "other", "role"))
+
+		_, err = jwter.Sign(josejwt.Claims{"sub": "alice", "app": josejwt.Claims{"role": "admin"}})
+		assert.Nil(err)
+	})
+}
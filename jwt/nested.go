@@ -0,0 +1,113 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/SermoDigital/jose"
+	josecrypto "github.com/SermoDigital/jose/crypto"
+	josejws "github.com/SermoDigital/jose/jws"
+)
+
+// maxNestedDepth bounds the levels of nested tokens (RFC 7519, Section 5.2) to unwrap.
+const maxNestedDepth = 3
+
+var errNestedTooDeep = errors.New("nested token is too deep")
+
+// parseNested returns the header and the inner token of a nested token (header "cty": "JWT"),
+// or nil header for other tokens.
+func parseNested(token string) (jose.Protected, string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, "", nil
+	}
+	buf, err := jose.Base64Decode([]byte(parts[0]))
+	if err != nil {
+		return nil, "", nil
+	}
+	var header map[string]interface{}
+	if json.Unmarshal(buf, &header) != nil {
+		return nil, "", nil
+	}
+	if cty, _ := header["cty"].(string); !strings.EqualFold(cty, "JWT") {
+		return nil, "", nil
+	}
+	if buf, err = jose.Base64Decode([]byte(parts[1])); err != nil {
+		return nil, "", err
+	}
+	return jose.Protected(header), string(buf), nil
+}
+
+// decodeNested returns the innermost token of nested tokens without verifying.
+func decodeNested(token string) (string, error) {
+	for depth := 0; ; depth++ {
+		header, inner, err := parseNested(token)
+		if err != nil || header == nil {
+			return token, err
+		}
+		if depth == maxNestedDepth {
+			return "", errNestedTooDeep
+		}
+		token = inner
+	}
+}
+
+// unwrapNested verifies the outer layers of nested tokens with the same keys and header checks
+// as the token itself, and returns the innermost token.
+func (j *JWT) unwrapNested(token string) (string, error) {
+	for depth := 0; ; depth++ {
+		header, inner, err := parseNested(token)
+		if err != nil || header == nil {
+			return token, err
+		}
+		if depth == maxNestedDepth {
+			return "", errNestedTooDeep
+		}
+		if !j.allowDuplicates {
+			if err = checkDuplicateKeys(token); err != nil {
+				return "", err
+			}
+		}
+		if err = j.verifySignature(token, header); err != nil {
+			return "", err
+		}
+		if err = j.checkHeader(header); err != nil {
+			return "", err
+		}
+		token = inner
+	}
+}
+
+// verifySignature verifies the signature of a compact serialized token with keys, or backup keys.
+func (j *JWT) verifySignature(token string, header jose.Protected) error {
+	i := strings.LastIndexByte(token, '.')
+	sig, err := jose.Base64Decode([]byte(token[i+1:]))
+	if err != nil {
+		return err
+	}
+	data := []byte(token[:i])
+	keys, err := j.getVerifyKeys(header)
+	if err == nil {
+		err = verifyRaw(data, sig, header, j.method, keys)
+	}
+	if err != nil && j.backupKeys != nil {
+		err = verifyRaw(data, sig, header, j.backupMethod, j.backupKeys)
+	}
+	return err
+}
+
+func verifyRaw(data, sig []byte, header jose.Protected, method josecrypto.SigningMethod, keys rotating) error {
+	if method == nil || header.Get("alg") != method.Alg() {
+		return josejws.ErrMismatchedAlgorithms
+	}
+	err := josecrypto.ErrSignatureInvalid
+	keys.Verify(func(key interface{}) bool {
+		if k, ok := key.(KeyPair); ok { // try to extract PublicKey
+			key = k.PublicKey
+		}
+		err = method.Verify(data, sig, key)
+		return err == nil
+	})
+	return err
+}
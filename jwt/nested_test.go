@@ -0,0 +1,69 @@
+package jwt
+
+import (
+	"testing"
+
+	josecrypto "github.com/SermoDigital/jose/crypto"
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNestedToken(t *testing.T) {
+	t.Run("should verify nested tokens", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"))
+		inner, _ := jwter.Sign(josejwt.Claims{"sub": "alice"})
+		token := signRawWithHeader([]byte("key1"), `{"alg":"HS256","cty":"JWT"}`, inner)
+
+		claims, err := jwter.Verify(token)
+		assert.Nil(err)
+		assert.Equal("alice", claims.Get("sub"))
+		claims, err = jwter.Decode(token)
+		assert.Nil(err)
+		assert.Equal("alice", claims.Get("sub"))
+
+		token = signRawWithHeader([]byte("key1"), `{"alg":"HS256","cty":"jwt"}`, token)
+		claims, err = jwter.Verify(token)
+		assert.Nil(err)
+		assert.Equal("alice", claims.Get("sub"))
+
+		jwter.SetBackupSigning(josecrypto.SigningMethodHS256, []byte("old key"))
+		token = signRawWithHeader([]byte("old key"), `{"alg":"HS256","cty":"JWT"}`, inner)
+		_, err = jwter.Verify(token)
+		assert.Nil(err)
+	})
+
+	t.Run("should reject invalid nested tokens", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"))
+		inner, _ := jwter.Sign(josejwt.Claims{"sub": "alice"})
+
+		token := signRawWithHeader([]byte("key2"), `{"alg":"HS256","cty":"JWT"}`, inner)
+		_, err := jwter.Verify(token)
+		assert.Contains(err.Error(), "signature is invalid")
+
+		token = signRawWithHeader([]byte("key1"), `{"alg":"HS384","cty":"JWT"}`, inner)
+		_, err = jwter.Verify(token)
+		assert.Contains(err.Error(), "mismatched algorithms")
+
+		token = signRawWithHeader([]byte("key1"), `{"alg":"HS256","cty":"JWT","jwk":{}}`, inner)
+		_, err = jwter.Verify(token)
+		assert.Contains(err.Error(), `header "jwk" is not allowed`)
+
+		forged := signRawWithHeader([]byte("key2"), `{"alg":"HS256"}`, `{"sub":"admin"}`)
+		token = signRawWithHeader([]byte("key1"), `{"alg":"HS256","cty":"JWT"}`, forged)
+		_, err = jwter.Verify(token)
+		assert.NotNil(err)
+
+		token = inner
+		for i := 0; i <= maxNestedDepth; i++ {
+			token = signRawWithHeader([]byte("key1"), `{"alg":"HS256","cty":"JWT"}`, token)
+		}
+		_, err = jwter.Verify(token)
+		assert.Contains(err.Error(), "nested token is too deep")
+		_, err = jwter.Decode(token)
+		assert.NotNil(err)
+	})
+}
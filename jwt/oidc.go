@@ -0,0 +1,107 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	josecrypto "github.com/SermoDigital/jose/crypto"
+	josejws "github.com/SermoDigital/jose/jws"
+)
+
+// OpenIDConfiguration is the subset of the OpenID Provider Metadata used by NewFromIssuer,
+// see https://openid.net/specs/openid-connect-discovery-1_0.html#ProviderMetadata.
+type OpenIDConfiguration struct {
+	Issuer        string   `json:"issuer"`
+	JWKSURI       string   `json:"jwks_uri"`
+	SigningValues []string `json:"id_token_signing_alg_values_supported"`
+}
+
+// DiscoverOpenID fetches the OpenID Provider Metadata from "{issuer}/.well-known/openid-configuration".
+// It returns an error if the "issuer" of the metadata is not the same as the issuer requested.
+func DiscoverOpenID(ctx context.Context, client *http.Client, issuer string) (*OpenIDConfiguration, error) {
+	issuer = strings.TrimSuffix(issuer, "/")
+	if issuer == "" {
+		return nil, errors.New("invalid issuer")
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	req, err := http.NewRequest(http.MethodGet, issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, &statusError{"OpenID provider", res.StatusCode}
+	}
+	config := &OpenIDConfiguration{}
+	if err = json.NewDecoder(res.Body).Decode(config); err != nil {
+		return nil, err
+	}
+	if strings.TrimSuffix(config.Issuer, "/") != issuer {
+		return nil, errors.New("OpenID provider issuer mismatch: " + config.Issuer)
+	}
+	if config.JWKSURI == "" {
+		return nil, errors.New("OpenID provider has no jwks_uri")
+	}
+	return config, nil
+}
+
+// NewFromIssuer returns a verify-only JWT instance for tokens of an OpenID Connect provider. It fetches
+// the provider's "/.well-known/openid-configuration", loads keys from its "jwks_uri" as NewFromJWKS does,
+// only accepts tokens with the "iss" claim of the issuer, and verifies with RS256 if it is advertised in
+// "id_token_signing_alg_values_supported" (or the list is absent), otherwise with the first asymmetric
+// algorithm of the list. WithJWKSMethod overrides the algorithm,
+// WithJWKSClient is used for discovery too. The ctx only bounds discovery, use WithJWKSContext to stop
+// refreshing the key set in background.
+//
+//  jwter, err := jwt.NewFromIssuer(ctx, "https://accounts.example.com",
+//  	jwt.WithRefreshInterval(15*time.Minute))
+//
+func NewFromIssuer(ctx context.Context, issuer string, opts ...JWKSOption) (*JWT, error) {
+	o := &jwksOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	config, err := DiscoverOpenID(ctx, o.client, issuer)
+	if err != nil {
+		return nil, err
+	}
+	if o.method == nil {
+		method, err := openIDSigningMethod(config.SigningValues)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithJWKSMethod(method))
+	}
+	j := NewFromJWKS(config.JWKSURI, opts...)
+	j.SetExpectedIssuer(config.Issuer)
+	return j, nil
+}
+
+// openIDSigningMethod returns RS256 when algs is empty or has it, since OpenID providers must support
+// RS256 for ID tokens and some (such as Keycloak) advertise other algorithms first. Otherwise it returns
+// the first supported asymmetric signing method of algs.
+func openIDSigningMethod(algs []string) (josecrypto.SigningMethod, error) {
+	if len(algs) == 0 || containsString(algs, "RS256") {
+		return josecrypto.SigningMethodRS256, nil
+	}
+	for _, alg := range algs {
+		// "none" and HMAC are not verifiable with the keys of JWKS.
+		if alg == "none" || strings.HasPrefix(alg, "HS") {
+			continue
+		}
+		if method := josejws.GetSigningMethod(alg); method != nil {
+			return method, nil
+		}
+	}
+	return nil, errors.New("unsupported signing algorithms: " + strings.Join(algs, ", "))
+}
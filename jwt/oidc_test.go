@@ -0,0 +1,135 @@
+package jwt
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	josecrypto "github.com/SermoDigital/jose/crypto"
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestOpenIDServer(jwksURI string, algs ...string) *httptest.Server {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/.well-known/openid-configuration" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                srv.URL,
+			"jwks_uri":                              jwksURI,
+			"id_token_signing_alg_values_supported": algs,
+		})
+	}))
+	return srv
+}
+
+func TestNewFromIssuer(t *testing.T) {
+	jwks := newTestJWKSServer()
+	defer jwks.Close()
+	key := jwks.addKey("key1")
+
+	t.Run("should discover keys, algorithm and issuer", func(t *testing.T) {
+		assert := assert.New(t)
+
+		srv := newTestOpenIDServer(jwks.URL, "none", "HS256", "ES256", "PS256")
+		defer srv.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		jwter, err := NewFromIssuer(context.Background(), srv.URL+"/", WithJWKSContext(ctx))
+		assert.Nil(err)
+
+		claims, err := jwter.Verify(signWithKID(key, "key1", josejwt.Claims{"iss": srv.URL, "sub": "alice"}))
+		assert.Nil(err)
+		assert.Equal("alice", claims.Get("sub"))
+
+		_, err = jwter.Verify(signWithKID(key, "key1", josejwt.Claims{"iss": "https://evil.com", "sub": "alice"}))
+		assert.Equal(josejwt.ErrInvalidISSClaim.Error(), err.Error())
+		_, err = jwter.Verify(signWithKID(key, "key1", josejwt.Claims{"sub": "alice"}))
+		assert.NotNil(err)
+	})
+
+	t.Run("should prefer RS256 when advertised", func(t *testing.T) {
+		assert := assert.New(t)
+
+		rsaKey, _ := rsa.GenerateKey(rand.Reader, 1024)
+		signer := New()
+		signer.SetSigningWithKID(josecrypto.SigningMethodRS256, map[string]interface{}{
+			"rsa": KeyPair{PrivateKey: rsaKey, PublicKey: &rsaKey.PublicKey},
+		}, "rsa")
+		keys, _ := signer.PublicKeys()
+		set := jsonWebKeySet{}
+		for _, key := range keys {
+			k, _ := toJSONWebKey(key)
+			set.Keys = append(set.Keys, *k)
+		}
+		rsaJWKS := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(set)
+		}))
+		defer rsaJWKS.Close()
+
+		srv := newTestOpenIDServer(rsaJWKS.URL, "PS384", "RS256")
+		defer srv.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		jwter, err := NewFromIssuer(context.Background(), srv.URL, WithJWKSContext(ctx))
+		assert.Nil(err)
+
+		signer.SetIssuer(srv.URL)
+		token, _ := signer.Sign(josejwt.Claims{"sub": "alice"})
+		claims, err := jwter.Verify(token)
+		assert.Nil(err)
+		assert.Equal("alice", claims.Get("sub"))
+	})
+
+	t.Run("should use the signing method of options", func(t *testing.T) {
+		assert := assert.New(t)
+
+		srv := newTestOpenIDServer(jwks.URL, "RS256")
+		defer srv.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		jwter, err := NewFromIssuer(context.Background(), srv.URL,
+			WithJWKSMethod(josecrypto.SigningMethodES256), WithJWKSContext(ctx))
+		assert.Nil(err)
+		_, err = jwter.Verify(signWithKID(key, "key1", josejwt.Claims{"iss": srv.URL}))
+		assert.Nil(err)
+	})
+
+	t.Run("should return errors of discovery", func(t *testing.T) {
+		assert := assert.New(t)
+
+		_, err := NewFromIssuer(context.Background(), "")
+		assert.Equal("invalid issuer", err.Error())
+
+		_, err = NewFromIssuer(context.Background(), jwks.URL+"/other")
+		assert.Equal("OpenID provider issuer mismatch: ", err.Error())
+
+		srv := newTestOpenIDServer(jwks.URL, "HS256", "PS999")
+		defer srv.Close()
+		_, err = NewFromIssuer(context.Background(), srv.URL)
+		assert.Equal("unsupported signing algorithms: HS256, PS999", err.Error())
+
+		_, err = NewFromIssuer(context.Background(), srv.URL+"/tenant")
+		assert.Equal("OpenID provider responded 404", err.Error())
+
+		srv = newTestOpenIDServer("")
+		defer srv.Close()
+		_, err = NewFromIssuer(context.Background(), srv.URL)
+		assert.Equal("OpenID provider has no jwks_uri", err.Error())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = NewFromIssuer(ctx, srv.URL)
+		assert.NotNil(err)
+	})
+}
@@ -0,0 +1,38 @@
+package jwt
+
+import (
+	"errors"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+)
+
+// WithValidator returns a JWT for a route group that verifies tokens with the validator instead of
+// j's validator, such as zero leeway and a stricter audience on "/payments/*". The returned JWT shares
+// the keys, key source, store, revoker and all other options with j, so the configuration is not cloned.
+// Derive it after j is configured: later Set calls on j don't affect it, but rotation with a KeySource does.
+//
+//  payments := jwter.WithValidator(&josejwt.Validator{Expected: josejwt.Claims{"aud": "payments"}})
+//
+func (j *JWT) WithValidator(validator *josejwt.Validator) *JWT {
+	if validator == nil {
+		panic(errors.New("invalid validator"))
+	}
+	r := *j
+	r.validator = []*josejwt.Validator{validator}
+	return &r
+}
+
+// WithContextValidators returns a JWT as WithValidator, but adds context validators to j's instead of
+// replacing the validator.
+//
+//  payments := jwter.WithContextValidators(jwt.ContextValidatorFunc(checkMFA))
+//
+func (j *JWT) WithContextValidators(validators ...ContextValidator) *JWT {
+	r := *j
+	r.ctxValidators = make([]ContextValidator, 0, len(j.ctxValidators)+len(validators))
+	r.ctxValidators = append(r.ctxValidators, j.ctxValidators...)
+	for _, v := range validators {
+		r.AddContextValidator(v)
+	}
+	return &r
+}
@@ -0,0 +1,75 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatorOverrides(t *testing.T) {
+	t.Run("should replace the validator", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key"))
+		jwter.SetValidator(&josejwt.Validator{EXP: time.Minute})
+		assert.Panics(func() {
+			jwter.WithValidator(nil)
+		})
+		strict := jwter.WithValidator(&josejwt.Validator{Expected: josejwt.Claims{"aud": "payments"}})
+
+		token, err := jwter.SignAt(time.Now().Add(-2*time.Hour), josejwt.Claims{"aud": "payments"}, 2*time.Hour-10*time.Second)
+		assert.Nil(err)
+		_, err = jwter.Verify(token)
+		assert.Nil(err)
+		_, err = strict.Verify(token)
+		assert.Equal("token is expired", err.Error())
+
+		token, err = strict.Sign(josejwt.Claims{"aud": "orders"})
+		assert.Nil(err)
+		_, err = jwter.Verify(token)
+		assert.Nil(err)
+		_, err = strict.Verify(token)
+		assert.NotNil(err)
+
+		token, err = jwter.Sign(josejwt.Claims{"aud": "payments"})
+		assert.Nil(err)
+		_, err = strict.Verify(token)
+		assert.Nil(err)
+	})
+
+	t.Run("should add context validators", func(t *testing.T) {
+		assert := assert.New(t)
+
+		calls := 0
+		jwter := New([]byte("key"))
+		jwter.AddContextValidator(ContextValidatorFunc(func(ctx context.Context, claims josejwt.Claims) error {
+			calls++
+			return nil
+		}))
+		mfa := jwter.WithContextValidators(ContextValidatorFunc(func(ctx context.Context, claims josejwt.Claims) error {
+			if claims.Get("amr") != "mfa" {
+				return errors.New("mfa required")
+			}
+			return nil
+		}))
+		assert.Panics(func() {
+			jwter.WithContextValidators(nil)
+		})
+
+		token, _ := jwter.Sign(josejwt.Claims{"sub": "user"})
+		_, err := jwter.Verify(token)
+		assert.Nil(err)
+		_, err = mfa.Verify(token)
+		assert.Equal("mfa required", err.Error())
+		assert.Equal(2, calls)
+
+		token, _ = jwter.Sign(josejwt.Claims{"sub": "user", "amr": "mfa"})
+		_, err = mfa.Verify(token)
+		assert.Nil(err)
+		assert.Equal(3, calls)
+	})
+}
@@ -0,0 +1,66 @@
+package jwt
+
+import (
+	"errors"
+	"path"
+	"strings"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+)
+
+// SetIssuerPatterns requires claim "iss" matching one of the patterns at verification, "*" matches any
+// characters except "/". It is useful for multi-tenant IdPs where each tenant has a distinct issuer URL
+// but shares keys. Set no patterns to disable it.
+//
+//  jwter.SetIssuerPatterns("https://login.example.com/tenants/*")
+//
+func (j *JWT) SetIssuerPatterns(patterns ...string) {
+	j.issuerPatterns = patterns
+}
+
+// SetExpectedIssuer requires claim "iss" equal to one of the issuers at verification, so tokens of
+// untrusted issuers are rejected even if they are signed with shared or federated keys. Issuers are
+// compared exactly, use SetIssuerPatterns for wildcards. Set no issuers to disable it.
+// It panics if an issuer is empty, so an unset configuration doesn't disable the check silently.
+//
+//  jwter.SetExpectedIssuer("https://accounts.example.com", "https://login.partner.com")
+//
+func (j *JWT) SetExpectedIssuer(iss ...string) {
+	for _, i := range iss {
+		if strings.TrimSpace(i) == "" {
+			panic(errors.New("invalid issuer"))
+		}
+	}
+	j.expectedIssuers = iss
+}
+
+// checkIssuer rejects tokens whose "iss" isn't expected or doesn't match the issuer patterns.
+func (j *JWT) checkIssuer(claims josejwt.Claims) error {
+	iss, _ := claims.Issuer()
+	if len(j.expectedIssuers) > 0 && !containsString(j.expectedIssuers, iss) {
+		return josejwt.ErrInvalidISSClaim
+	}
+	if len(j.issuerPatterns) == 0 {
+		return nil
+	}
+	for _, pattern := range j.issuerPatterns {
+		if matchPattern(pattern, iss) {
+			return nil
+		}
+	}
+	return josejwt.ErrInvalidISSClaim
+}
+
+// matchPattern reports whether s matches pattern. A pattern without "*" must equal s,
+// otherwise "*" matches any sequence of characters except "/", so "https://*.internal.example.com"
+// matches "https://billing.staging.internal.example.com" but not "https://evil.com/.internal.example.com".
+func matchPattern(pattern, s string) bool {
+	if pattern == s {
+		return true
+	}
+	if !strings.Contains(pattern, "*") {
+		return false
+	}
+	ok, err := path.Match(pattern, s)
+	return err == nil && ok
+}
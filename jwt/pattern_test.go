@@ -0,0 +1,100 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPattern(t *testing.T) {
+	t.Run("matchPattern", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.True(matchPattern("billing", "billing"))
+		assert.False(matchPattern("billing", "billing-api"))
+		assert.True(matchPattern("[billing]", "[billing]"))
+		assert.True(matchPattern("https://*.internal.example.com", "https://billing.internal.example.com"))
+		assert.True(matchPattern("https://*.internal.example.com", "https://billing.staging.internal.example.com"))
+		assert.False(matchPattern("https://*.internal.example.com", "https://internal.example.com"))
+		assert.False(matchPattern("https://*.internal.example.com", "https://evil.com/.internal.example.com"))
+		assert.False(matchPattern("https://*.internal.example.com", "https://billing.internal.example.com.evil.com"))
+		assert.False(matchPattern("https://*[", "https://billing"))
+	})
+
+	t.Run("should verify wildcard audience", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"))
+		token, _ := jwter.Sign(josejwt.Claims{"aud": []string{"https://billing.staging.internal.example.com"}})
+		_, err := jwter.VerifyForAudience(token, "https://*.internal.example.com")
+		assert.Nil(err)
+		_, err = jwter.VerifyForAudience(token, "https://*.public.example.com", "https://*.internal.example.com")
+		assert.Nil(err)
+		_, err = jwter.VerifyForAudience(token, "https://*.public.example.com")
+		assert.Contains(err.Error(), josejwt.ErrInvalidAUDClaim.Error())
+	})
+	t.Run("should verify issuer patterns", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"))
+		jwter.SetIssuerPatterns("https://login.example.com/tenants/*", "https://accounts.example.com")
+		acme, _ := jwter.Sign(josejwt.Claims{"iss": "https://login.example.com/tenants/acme"})
+		accounts, _ := jwter.Sign(josejwt.Claims{"iss": "https://accounts.example.com"})
+		nested, _ := jwter.Sign(josejwt.Claims{"iss": "https://login.example.com/tenants/acme/evil"})
+		noIss, _ := jwter.Sign(josejwt.Claims{"sub": "alice"})
+
+		_, err := jwter.Verify(acme)
+		assert.Nil(err)
+		_, err = jwter.Verify(accounts)
+		assert.Nil(err)
+		_, err = jwter.Verify(nested)
+		assert.Contains(err.Error(), josejwt.ErrInvalidISSClaim.Error())
+		_, err = jwter.Verify(noIss)
+		assert.Contains(err.Error(), josejwt.ErrInvalidISSClaim.Error())
+
+		jwter.SetIssuerPatterns()
+		_, err = jwter.Verify(noIss)
+		assert.Nil(err)
+	})
+
+	t.Run("should verify expected issuers", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"))
+		jwter.SetExpectedIssuer("https://accounts.example.com", "https://login.partner.com")
+		accounts, _ := jwter.Sign(josejwt.Claims{"iss": "https://accounts.example.com"})
+		partner, _ := jwter.Sign(josejwt.Claims{"iss": "https://login.partner.com"})
+		other, _ := jwter.Sign(josejwt.Claims{"iss": "https://accounts.example.com/"})
+		noIss, _ := jwter.Sign(josejwt.Claims{"sub": "alice"})
+
+		_, err := jwter.Verify(accounts)
+		assert.Nil(err)
+		_, err = jwter.Verify(partner)
+		assert.Nil(err)
+		_, err = jwter.Verify(other)
+		assert.True(errors.Is(err, ErrBadIssuer))
+		_, err = jwter.Verify(noIss)
+		assert.True(errors.Is(err, ErrBadIssuer))
+
+		// both expected issuers and patterns are required
+		jwter.SetIssuerPatterns("https://login.*.com")
+		_, err = jwter.Verify(partner)
+		assert.Nil(err)
+		_, err = jwter.Verify(accounts)
+		assert.True(errors.Is(err, ErrBadIssuer))
+
+		assert.Panics(func() {
+			jwter.SetExpectedIssuer("", "https://accounts.example.com")
+		})
+		assert.Panics(func() {
+			jwter.SetExpectedIssuer(" ")
+		})
+
+		jwter.SetIssuerPatterns()
+		jwter.SetExpectedIssuer()
+		_, err = jwter.Verify(noIss)
+		assert.Nil(err)
+	})
+}
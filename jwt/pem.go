@@ -0,0 +1,208 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+)
+
+// LoadRSAKeyPairFromPEM returns a KeyPair for RS* and PS* signing methods from PEM or DER data, such as
+// the output of "openssl genrsa" or "openssl rsa -pubout". The data can contain a private key (PKCS #1
+// or PKCS #8), a public key (PKIX or PKCS #1) or a certificate, the PrivateKey is nil if there is only
+// a public key, so the KeyPair is verify-only. Encrypted private keys are decrypted with the passphrase,
+// both encrypted PKCS #8 ("ENCRYPTED PRIVATE KEY", the output of "openssl genrsa -aes256" since
+// OpenSSL 3.0) with PBES2, and legacy encrypted PEM blocks ("Proc-Type: 4,ENCRYPTED").
+//
+//  pair, err := jwt.LoadRSAKeyPairFromPEM(pemBytes, os.Getenv("JWT_KEY_PASSPHRASE"))
+//  jwter.SetSigning(josecrypto.SigningMethodRS256, pair)
+//
+func LoadRSAKeyPairFromPEM(data []byte, passphrase ...string) (KeyPair, error) {
+	private, public, err := parseKeys(data, passphrase)
+	if err != nil {
+		return KeyPair{}, err
+	}
+	pair := KeyPair{}
+	if private != nil {
+		key, ok := private.(*rsa.PrivateKey)
+		if !ok {
+			return KeyPair{}, fmt.Errorf("RSA private key required, got %T", private)
+		}
+		pair.PrivateKey, public = key, &key.PublicKey
+	}
+	if _, ok := public.(*rsa.PublicKey); !ok {
+		return KeyPair{}, fmt.Errorf("RSA public key required, got %T", public)
+	}
+	pair.PublicKey = public
+	return pair, nil
+}
+
+// LoadECKeyPairFromPEM returns a KeyPair for ES* signing methods from PEM or DER data, such as the output
+// of "openssl ecparam -genkey" or "openssl ec -pubout". The data can contain a private key (SEC 1 or
+// PKCS #8), a public key (PKIX) or a certificate, the PrivateKey is nil if there is only a public key.
+// Encrypted PEM blocks are decrypted with the passphrase.
+//
+//  pair, err := jwt.LoadECKeyPairFromPEM(pemBytes)
+//  jwter.SetSigning(josecrypto.SigningMethodES256, pair)
+//
+func LoadECKeyPairFromPEM(data []byte, passphrase ...string) (KeyPair, error) {
+	private, public, err := parseKeys(data, passphrase)
+	if err != nil {
+		return KeyPair{}, err
+	}
+	pair := KeyPair{}
+	if private != nil {
+		key, ok := private.(*ecdsa.PrivateKey)
+		if !ok {
+			return KeyPair{}, fmt.Errorf("ECDSA private key required, got %T", private)
+		}
+		pair.PrivateKey, public = key, &key.PublicKey
+	}
+	if _, ok := public.(*ecdsa.PublicKey); !ok {
+		return KeyPair{}, fmt.Errorf("ECDSA public key required, got %T", public)
+	}
+	pair.PublicKey = public
+	return pair, nil
+}
+
+// LoadPublicKeyFromCert returns the public key of a PEM or DER certificate, such as *rsa.PublicKey
+// or *ecdsa.PublicKey, for identity providers that publish X.509 certificates instead of keys.
+// The validity and chain of the certificate are not checked.
+//
+//  key, err := jwt.LoadPublicKeyFromCert(certBytes)
+//  verifier.SetSigning(josecrypto.SigningMethodRS256, key)
+//
+func LoadPublicKeyFromCert(data []byte) (interface{}, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		if block.Type != "CERTIFICATE" {
+			return nil, errors.New("unsupported PEM block for certificate: " + block.Type)
+		}
+		data = block.Bytes
+	}
+	cert, err := x509.ParseCertificate(data)
+	if err != nil {
+		return nil, err
+	}
+	return cert.PublicKey, nil
+}
+
+// LoadRSAKeyPairFromFile reads the file and returns a KeyPair as LoadRSAKeyPairFromPEM.
+func LoadRSAKeyPairFromFile(filename string, passphrase ...string) (KeyPair, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return KeyPair{}, err
+	}
+	return LoadRSAKeyPairFromPEM(data, passphrase...)
+}
+
+// LoadECKeyPairFromFile reads the file and returns a KeyPair as LoadECKeyPairFromPEM.
+func LoadECKeyPairFromFile(filename string, passphrase ...string) (KeyPair, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return KeyPair{}, err
+	}
+	return LoadECKeyPairFromPEM(data, passphrase...)
+}
+
+// LoadPublicKeyFromCertFile reads the file and returns the public key as LoadPublicKeyFromCert.
+func LoadPublicKeyFromCertFile(filename string) (interface{}, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return LoadPublicKeyFromCert(data)
+}
+
+// parseKeys returns the first private key and the first public key in the PEM blocks,
+// or in the DER data if it is not PEM.
+func parseKeys(data []byte, passphrase []string) (private, public interface{}, err error) {
+	block, rest := pem.Decode(data)
+	if block == nil {
+		if private, public = parseDER(data); private == nil && public == nil {
+			return nil, nil, errors.New("no PEM or DER key found")
+		}
+		return private, public, nil
+	}
+	for ; block != nil; block, rest = pem.Decode(rest) {
+		der := block.Bytes
+		encrypted := x509.IsEncryptedPEMBlock(block)
+		if (encrypted || block.Type == "ENCRYPTED PRIVATE KEY") && len(passphrase) == 0 {
+			return nil, nil, errors.New("passphrase required for encrypted PEM block")
+		}
+		// legacy encrypted PEM (RFC 1423), such as the output of "openssl genrsa -aes256" before OpenSSL 3.0.
+		if encrypted {
+			if der, err = x509.DecryptPEMBlock(block, []byte(passphrase[0])); err != nil {
+				return nil, nil, err
+			}
+		}
+		var key interface{}
+		switch block.Type {
+		case "RSA PRIVATE KEY":
+			key, err = x509.ParsePKCS1PrivateKey(der)
+		case "EC PRIVATE KEY":
+			key, err = x509.ParseECPrivateKey(der)
+		case "PRIVATE KEY":
+			key, err = x509.ParsePKCS8PrivateKey(der)
+		case "PUBLIC KEY":
+			key, err = x509.ParsePKIXPublicKey(der)
+		case "RSA PUBLIC KEY":
+			key, err = x509.ParsePKCS1PublicKey(der)
+		case "CERTIFICATE":
+			var cert *x509.Certificate
+			if cert, err = x509.ParseCertificate(der); err == nil {
+				key = cert.PublicKey
+			}
+		case "EC PARAMETERS": // prepended by "openssl ecparam -genkey"
+			continue
+		case "ENCRYPTED PRIVATE KEY":
+			if der, err = decryptPKCS8(der, passphrase[0]); err == nil {
+				key, err = x509.ParsePKCS8PrivateKey(der)
+			}
+		default:
+			return nil, nil, errors.New("unsupported PEM block: " + block.Type)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		switch block.Type {
+		case "RSA PRIVATE KEY", "EC PRIVATE KEY", "PRIVATE KEY", "ENCRYPTED PRIVATE KEY":
+			if private == nil {
+				private = key
+			}
+		default:
+			if public == nil {
+				public = key
+			}
+		}
+	}
+	if private == nil && public == nil {
+		return nil, nil, errors.New("no PEM or DER key found")
+	}
+	return private, public, nil
+}
+
+// parseDER returns the private or public key of the DER data, they are nil if it is not a key.
+func parseDER(der []byte) (private, public interface{}) {
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKIXPublicKey(der); err == nil {
+		return nil, key
+	}
+	if key, err := x509.ParsePKCS1PublicKey(der); err == nil {
+		return nil, key
+	}
+	if cert, err := x509.ParseCertificate(der); err == nil {
+		return nil, cert.PublicKey
+	}
+	return nil, nil
+}
@@ -0,0 +1,79 @@
+package jwt
+
+import (
+	"strings"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+)
+
+// MatchPermission reports whether the granted permission covers the required one. Permissions are
+// hierarchical segments separated by ":" or ".", such as "orders:read" or "admin.users.read".
+// A "*" segment matches any single segment, and a trailing "*" matches all remaining segments:
+//
+//  jwt.MatchPermission("orders:*", "orders:read")            // true
+//  jwt.MatchPermission("orders:*", "orders:items:read")      // true
+//  jwt.MatchPermission("admin.*.read", "admin.users.read")   // true
+//  jwt.MatchPermission("admin.*.read", "admin.users.write")  // false
+//
+func MatchPermission(granted, required string) bool {
+	g := splitPermission(granted)
+	r := splitPermission(required)
+	for i, seg := range g {
+		if seg == "*" && i == len(g)-1 {
+			return len(r) > i
+		}
+		if i >= len(r) || (seg != "*" && seg != r[i]) {
+			return false
+		}
+	}
+	return len(g) == len(r)
+}
+
+func splitPermission(perm string) []string {
+	return strings.FieldsFunc(perm, func(r rune) bool {
+		return r == ':' || r == '.'
+	})
+}
+
+// Permissions returns the permissions from the claim, it can be an array of strings
+// or a space-delimited string.
+func Permissions(claims josejwt.Claims, claim string) []string {
+	switch v := claims.Get(claim).(type) {
+	case string:
+		return strings.Fields(v)
+	case []string:
+		return v
+	case []interface{}:
+		perms := make([]string, 0, len(v))
+		for _, p := range v {
+			if str, ok := p.(string); ok {
+				perms = append(perms, str)
+			}
+		}
+		return perms
+	}
+	return nil
+}
+
+// HasPermissions reports whether the permissions in the claim cover all the required permissions,
+// see MatchPermission.
+//
+//  claims, _ := jwter.Verify(token) // {"permissions": ["orders:*", "admin.users.read"]}
+//  jwt.HasPermissions(claims, "permissions", "orders:write", "admin.users.read") // true
+//
+func HasPermissions(claims josejwt.Claims, claim string, required ...string) bool {
+	granted := Permissions(claims, claim)
+	for _, perm := range required {
+		matched := false
+		for _, g := range granted {
+			if MatchPermission(g, perm) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,45 @@
+package jwt
+
+import (
+	"testing"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPermission(t *testing.T) {
+	t.Run("MatchPermission", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.True(MatchPermission("orders:read", "orders:read"))
+		assert.False(MatchPermission("orders:read", "orders:write"))
+		assert.False(MatchPermission("orders:read", "orders"))
+		assert.False(MatchPermission("orders", "orders:read"))
+		assert.True(MatchPermission("orders:*", "orders:read"))
+		assert.True(MatchPermission("orders:*", "orders:items:read"))
+		assert.False(MatchPermission("orders:*", "orders"))
+		assert.False(MatchPermission("orders:*", "users:read"))
+		assert.True(MatchPermission("*", "anything:at:all"))
+		assert.True(MatchPermission("admin.users.read", "admin:users:read"))
+		assert.True(MatchPermission("admin.*.read", "admin.users.read"))
+		assert.False(MatchPermission("admin.*.read", "admin.users.write"))
+		assert.False(MatchPermission("admin.*.read", "admin.users.read.all"))
+		assert.False(MatchPermission("", "orders"))
+	})
+
+	t.Run("HasPermissions", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"))
+		token, _ := jwter.Sign(josejwt.Claims{"permissions": []string{"orders:*", "admin.users.read"}})
+		claims, _ := jwter.Verify(token)
+
+		assert.Equal([]string{"orders:*", "admin.users.read"}, Permissions(claims, "permissions"))
+		assert.True(HasPermissions(claims, "permissions", "orders:write", "admin.users.read"))
+		assert.False(HasPermissions(claims, "permissions", "orders:write", "admin.users.write"))
+		assert.False(HasPermissions(claims, "perms", "orders:write"))
+		assert.True(HasPermissions(claims, "permissions"))
+
+		assert.True(HasPermissions(josejwt.Claims{"perms": "orders:read users:*"}, "perms", "users:delete"))
+	})
+}
@@ -0,0 +1,122 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"hash"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+var (
+	oidPBES2          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHMACWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA224 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 8}
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACWithSHA384 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 10}
+	oidHMACWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+	oidAES128CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+)
+
+// encryptedPrivateKeyInfo is the EncryptedPrivateKeyInfo of RFC 5208, Section 6.
+type encryptedPrivateKeyInfo struct {
+	Algorithm     pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+// pbes2Params is the PBES2-params of RFC 8018, Appendix A.4.
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+// pbkdf2Params is the PBKDF2-params of RFC 8018, Appendix A.2.
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	PRF            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// decryptPKCS8 decrypts the DER of an "ENCRYPTED PRIVATE KEY" PEM block, such as the output of
+// "openssl genpkey -aes256" or "openssl pkcs8 -topk8", to the DER of a PKCS #8 private key.
+// Only PBES2 with PBKDF2 and AES-CBC is supported, it is the default of OpenSSL since 1.1.0.
+func decryptPKCS8(der []byte, passphrase string) ([]byte, error) {
+	var info encryptedPrivateKeyInfo
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, err
+	}
+	if !info.Algorithm.Algorithm.Equal(oidPBES2) {
+		return nil, errors.New("unsupported PKCS #8 encryption: " + info.Algorithm.Algorithm.String())
+	}
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(info.Algorithm.Parameters.FullBytes, &params); err != nil {
+		return nil, err
+	}
+	if !params.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+		return nil, errors.New("unsupported PKCS #8 key derivation: " + params.KeyDerivationFunc.Algorithm.String())
+	}
+	var kdf pbkdf2Params
+	if _, err := asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdf); err != nil {
+		return nil, err
+	}
+
+	var prf func() hash.Hash
+	switch oid := kdf.PRF.Algorithm; {
+	case len(oid) == 0, oid.Equal(oidHMACWithSHA1):
+		prf = sha1.New
+	case oid.Equal(oidHMACWithSHA224):
+		prf = sha256.New224
+	case oid.Equal(oidHMACWithSHA256):
+		prf = sha256.New
+	case oid.Equal(oidHMACWithSHA384):
+		prf = sha512.New384
+	case oid.Equal(oidHMACWithSHA512):
+		prf = sha512.New
+	default:
+		return nil, errors.New("unsupported PKCS #8 PRF: " + oid.String())
+	}
+
+	var keyLen int
+	switch oid := params.EncryptionScheme.Algorithm; {
+	case oid.Equal(oidAES128CBC):
+		keyLen = 16
+	case oid.Equal(oidAES192CBC):
+		keyLen = 24
+	case oid.Equal(oidAES256CBC):
+		keyLen = 32
+	default:
+		return nil, errors.New("unsupported PKCS #8 cipher: " + oid.String())
+	}
+	var iv []byte
+	if _, err := asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+		return nil, err
+	}
+	if len(iv) != aes.BlockSize || len(info.EncryptedData) == 0 || len(info.EncryptedData)%aes.BlockSize != 0 {
+		return nil, errors.New("invalid PKCS #8 encrypted data")
+	}
+
+	block, err := aes.NewCipher(pbkdf2.Key([]byte(passphrase), kdf.Salt, kdf.IterationCount, keyLen, prf))
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, len(info.EncryptedData))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(data, info.EncryptedData)
+
+	// PKCS #7 padding, it is invalid on a wrong passphrase in most cases.
+	n := int(data[len(data)-1])
+	if n == 0 || n > aes.BlockSize || !bytes.Equal(data[len(data)-n:], bytes.Repeat([]byte{byte(n)}, n)) {
+		return nil, errors.New("failed to decrypt PKCS #8 key, the passphrase may be wrong")
+	}
+	return data[:len(data)-n], nil
+}
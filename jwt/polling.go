@@ -0,0 +1,113 @@
+package jwt
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// PollingKeys is a KeySource that periodically fetches keys from a secrets manager
+// (AWS Secrets Manager, GCP Secret Manager, Vault, etc.) by a user-supplied fetch function,
+// and atomically swaps keys when they are changed.
+type PollingKeys struct {
+	mu       sync.RWMutex
+	fetch    func() ([]interface{}, error)
+	onRotate func(old, keys []interface{})
+	keys     []interface{}
+	err      error
+	stop     chan struct{}
+	once     sync.Once
+}
+
+// NewPollingKeys fetches keys immediately and returns a PollingKeys instance that polls the fetch
+// function every interval. When fetched keys differ from the current keys, they are swapped in and
+// onRotate (optional) is called with old and new keys. Polling failures keep the current keys,
+// the last failure is reported by Check.
+//
+//  source, err := jwt.NewPollingKeys(func() ([]interface{}, error) {
+//  	out, err := sm.GetSecretValue(&secretsmanager.GetSecretValueInput{SecretId: aws.String("jwt-keys")})
+//  	if err != nil {
+//  		return nil, err
+//  	}
+//  	return jwt.StrToKeys(strings.Split(*out.SecretString, ",")...), nil
+//  }, time.Minute, func(old, keys []interface{}) {
+//  	logger.Info("jwt keys rotated")
+//  })
+//  jwter.SetKeySource(josecrypto.SigningMethodHS256, source)
+//
+func NewPollingKeys(fetch func() ([]interface{}, error), interval time.Duration,
+	onRotate func(old, keys []interface{})) (*PollingKeys, error) {
+	if fetch == nil {
+		panic(errors.New("invalid fetch function"))
+	}
+	if interval <= 0 {
+		panic(errors.New("invalid polling interval"))
+	}
+	p := &PollingKeys{fetch: fetch, onRotate: onRotate, stop: make(chan struct{})}
+	if err := p.poll(); err != nil {
+		return nil, err
+	}
+	go p.run(interval)
+	return p, nil
+}
+
+func (p *PollingKeys) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.poll()
+		}
+	}
+}
+
+// poll fetches keys and swaps them if changed.
+func (p *PollingKeys) poll() error {
+	keys, err := p.fetch()
+	if err == nil && len(keys) == 0 {
+		err = errors.New("no keys fetched")
+	}
+
+	p.mu.Lock()
+	p.err = err
+	if err != nil {
+		p.mu.Unlock()
+		return err
+	}
+	old := p.keys
+	changed := !reflect.DeepEqual(old, keys)
+	if changed {
+		p.keys = keys
+	}
+	p.mu.Unlock()
+
+	if changed && old != nil && p.onRotate != nil {
+		p.onRotate(old, keys)
+	}
+	return nil
+}
+
+// Keys implements the KeySource interface.
+func (p *PollingKeys) Keys() ([]interface{}, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.keys, nil
+}
+
+// Check implements the HealthChecker interface, it reports the last polling failure.
+func (p *PollingKeys) Check() error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.err
+}
+
+// Stop stops polling, the current keys are still available.
+func (p *PollingKeys) Stop() {
+	p.once.Do(func() {
+		close(p.stop)
+	})
+}
@@ -0,0 +1,91 @@
+package jwt
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	josecrypto "github.com/SermoDigital/jose/crypto"
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPollingKeys(t *testing.T) {
+	t.Run("should fail when initial fetch failed", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.Panics(func() {
+			NewPollingKeys(nil, time.Second, nil)
+		})
+		assert.Panics(func() {
+			NewPollingKeys(func() ([]interface{}, error) { return nil, nil }, 0, nil)
+		})
+
+		_, err := NewPollingKeys(func() ([]interface{}, error) {
+			return nil, errors.New("secret store is unreachable")
+		}, time.Second, nil)
+		assert.Equal("secret store is unreachable", err.Error())
+		_, err = NewPollingKeys(func() ([]interface{}, error) {
+			return nil, nil
+		}, time.Second, nil)
+		assert.NotNil(err)
+	})
+
+	t.Run("should swap keys and call onRotate", func(t *testing.T) {
+		assert := assert.New(t)
+
+		var mu sync.Mutex
+		secret := "key1"
+		var fetchErr error
+		rotated := make(chan []interface{}, 1)
+
+		source, err := NewPollingKeys(func() ([]interface{}, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if fetchErr != nil {
+				return nil, fetchErr
+			}
+			return StrToKeys(strings.Split(secret, ",")...), nil
+		}, 5*time.Millisecond, func(old, keys []interface{}) {
+			rotated <- keys
+		})
+		assert.Nil(err)
+		defer source.Stop()
+
+		jwter := New()
+		jwter.SetKeySource(josecrypto.SigningMethodHS256, source)
+		token, err := jwter.Sign(josejwt.Claims{"test": "OK"})
+		assert.Nil(err)
+
+		mu.Lock()
+		fetchErr = errors.New("secret store is unreachable")
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		assert.NotNil(source.Check())
+		_, err = jwter.Verify(token)
+		assert.Nil(err)
+
+		mu.Lock()
+		fetchErr = nil
+		secret = "key2,key1"
+		mu.Unlock()
+		select {
+		case keys := <-rotated:
+			assert.Equal(StrToKeys("key2", "key1"), keys)
+		case <-time.After(time.Second):
+			assert.Fail("keys not rotated")
+		}
+		assert.Nil(source.Check())
+
+		_, err = jwter.Verify(token)
+		assert.Nil(err)
+		token, _ = jwter.Sign(josejwt.Claims{"test": "OK"})
+		_, err = New([]byte("key2")).Verify(token)
+		assert.Nil(err)
+
+		source.Stop()
+		source.Stop()
+	})
+}
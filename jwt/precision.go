@@ -0,0 +1,62 @@
+package jwt
+
+import (
+	"math"
+	"time"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+)
+
+// SetMillisecondPrecision makes Sign set "iat" and "exp" with millisecond precision, such as 1514764800.123,
+// and makes Verify check "exp" and "nbf" with millisecond precision. It is needed for very short-lived tokens
+// (sub-second signed URLs) where whole-second truncation causes off-by-one expiry failures.
+// Fractional NumericDate is allowed by RFC 7519, but the verifying side should enable it too,
+// otherwise such tokens may be considered expired up to one second early.
+//
+//  jwter.SetMillisecondPrecision(true)
+//  token, err := jwter.Sign(map[string]interface{}{"path": "/files/1"}, 500*time.Millisecond)
+//
+func (j *JWT) SetMillisecondPrecision(enabled bool) {
+	j.msPrecision = enabled
+}
+
+// numericDate returns the NumericDate of t with millisecond precision.
+func numericDate(t time.Time) float64 {
+	return float64(t.UnixNano()/int64(time.Millisecond)) / 1000
+}
+
+// preciseTime returns the time of a NumericDate claim with millisecond precision.
+func preciseTime(claims josejwt.Claims, name string) (time.Time, bool) {
+	if val, ok := claims.Get(name).(float64); ok {
+		ms := int64(math.Round(val * 1000))
+		return time.Unix(ms/1000, ms%1000*int64(time.Millisecond)), true
+	}
+	return claims.GetTime(name)
+}
+
+// relaxExpiration rounds a fractional "exp" up, so that the whole-second validation of the jose library
+// never rejects a token before it expires. It returns a function to restore the claim.
+func relaxExpiration(claims josejwt.Claims) func() {
+	exp, ok := claims.Get("exp").(float64)
+	if !ok || exp == math.Trunc(exp) {
+		return func() {}
+	}
+	claims.Set("exp", math.Ceil(exp))
+	return func() { claims.Set("exp", exp) }
+}
+
+// checkPreciseTime checks "exp" and "nbf" with millisecond precision and the validator's leeway.
+func (j *JWT) checkPreciseTime(claims josejwt.Claims) error {
+	var expLeeway, nbfLeeway time.Duration
+	if len(j.validator) > 0 {
+		expLeeway, nbfLeeway = j.validator[0].EXP, j.validator[0].NBF
+	}
+	now := j.now()
+	if exp, ok := preciseTime(claims, "exp"); ok && now.After(exp.Add(expLeeway)) {
+		return josejwt.ErrTokenIsExpired
+	}
+	if nbf, ok := preciseTime(claims, "nbf"); ok && !now.After(nbf.Add(-nbfLeeway)) {
+		return josejwt.ErrTokenNotYetValid
+	}
+	return nil
+}
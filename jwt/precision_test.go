@@ -0,0 +1,70 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMillisecondPrecision(t *testing.T) {
+	t.Run("should sign with millisecond precision", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key"))
+		jwter.SetMillisecondPrecision(true)
+		at := time.Date(2018, 1, 1, 0, 0, 0, 123456789, time.UTC)
+		token, err := jwter.SignAt(at, josejwt.Claims{"sub": "user"}, 500*time.Millisecond)
+		assert.Nil(err)
+		claims, err := jwter.Decode(token)
+		assert.Nil(err)
+		assert.Equal(1514764800.123, claims.Get("iat"))
+		assert.Equal(1514764800.623, claims.Get("exp"))
+
+		jwter.SetMillisecondPrecision(false)
+		token, err = jwter.SignAt(at, josejwt.Claims{"sub": "user"}, 500*time.Millisecond)
+		assert.Nil(err)
+		claims, err = jwter.Decode(token)
+		assert.Nil(err)
+		assert.Equal(float64(1514764800), claims.Get("iat"))
+		assert.Equal(float64(1514764800), claims.Get("exp"))
+	})
+
+	t.Run("should verify with millisecond precision", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key"))
+		jwter.SetMillisecondPrecision(true)
+		now := time.Now()
+
+		token, err := jwter.SignAt(now.Add(-1200*time.Millisecond), josejwt.Claims{"sub": "user"}, 1500*time.Millisecond)
+		assert.Nil(err)
+		claims, err := jwter.Verify(token)
+		assert.Nil(err)
+		exp, _ := claims.Get("exp").(float64)
+		assert.Equal(numericDate(now.Add(300*time.Millisecond)), exp)
+
+		token, err = jwter.SignAt(now.Add(-1600*time.Millisecond), josejwt.Claims{"sub": "user"}, 1500*time.Millisecond)
+		assert.Nil(err)
+		_, err = jwter.Verify(token)
+		assert.Equal("token is expired", err.Error())
+
+		token, err = jwter.Sign(josejwt.Claims{"sub": "user", "nbf": numericDate(now.Add(time.Minute))})
+		assert.Nil(err)
+		_, err = jwter.Verify(token)
+		assert.Equal("token is not yet valid", err.Error())
+	})
+
+	t.Run("should apply validator leeway", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key"))
+		jwter.SetMillisecondPrecision(true)
+		jwter.SetValidator(&josejwt.Validator{EXP: time.Second})
+		token, err := jwter.SignAt(time.Now().Add(-1600*time.Millisecond), josejwt.Claims{"sub": "user"}, 1500*time.Millisecond)
+		assert.Nil(err)
+		_, err = jwter.Verify(token)
+		assert.Nil(err)
+	})
+}
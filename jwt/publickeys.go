@@ -0,0 +1,45 @@
+package jwt
+
+import josecrypto "github.com/SermoDigital/jose/crypto"
+
+// PublicKeys returns the public keys to verify tokens of jwt as JWKs with "use": "sig" and the "alg" of
+// the signing method: the keys of SetSigning (or the KeySource) first, then the keys of SetBackupSigning.
+// Keys of SetSigningWithKID have their "kid". HMAC keys are secrets, so they are never returned.
+// They can be published as a JWK Set with ExportJWK, see auth.JWKSHandler.
+func (j *JWT) PublicKeys() ([]*JWK, error) {
+	keys, err := j.getKeys()
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	if source, ok := j.keySource.(*kidKeys); ok {
+		ids = source.ids
+	}
+	res := make([]*JWK, 0, len(keys)+len(j.backupKeys))
+	add := func(method josecrypto.SigningMethod, key interface{}, kid string) error {
+		if _, ok := key.([]byte); ok || method == nil || method == josecrypto.Unsecured {
+			return nil
+		}
+		public, err := publicKeyOf(key)
+		if err != nil {
+			return err
+		}
+		res = append(res, &JWK{Key: public, KeyID: kid, Algorithm: method.Alg(), Use: "sig"})
+		return nil
+	}
+	for i, key := range keys {
+		kid := ""
+		if i < len(ids) {
+			kid = ids[i]
+		}
+		if err = add(j.method, key, kid); err != nil {
+			return nil, err
+		}
+	}
+	for _, key := range j.backupKeys {
+		if err = add(j.backupMethod, key, ""); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
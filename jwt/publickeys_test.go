@@ -0,0 +1,57 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	josecrypto "github.com/SermoDigital/jose/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublicKeys(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 1024)
+	oldKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	newKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	t.Run("should return public keys", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New()
+		jwter.SetSigning(josecrypto.SigningMethodRS256, KeyPair{PrivateKey: rsaKey, PublicKey: &rsaKey.PublicKey})
+		public, private, _ := ed25519.GenerateKey(rand.Reader)
+		jwter.SetBackupSigning(SigningMethodEdDSA, private)
+		keys, err := jwter.PublicKeys()
+		assert.Nil(err)
+		assert.Equal([]*JWK{
+			{Key: &rsaKey.PublicKey, Algorithm: "RS256", Use: "sig"},
+			{Key: public, Algorithm: "EdDSA", Use: "sig"},
+		}, keys)
+
+		jwter.SetSigningWithKID(josecrypto.SigningMethodES256, map[string]interface{}{
+			"2018-01": KeyPair{PublicKey: &oldKey.PublicKey},
+			"2018-02": KeyPair{PrivateKey: newKey, PublicKey: &newKey.PublicKey},
+		}, "2018-02")
+		keys, err = jwter.PublicKeys()
+		assert.Nil(err)
+		assert.Equal([]*JWK{
+			{Key: &newKey.PublicKey, KeyID: "2018-02", Algorithm: "ES256", Use: "sig"},
+			{Key: &oldKey.PublicKey, KeyID: "2018-01", Algorithm: "ES256", Use: "sig"},
+			{Key: public, Algorithm: "EdDSA", Use: "sig"},
+		}, keys)
+	})
+
+	t.Run("should not return HMAC keys", func(t *testing.T) {
+		assert := assert.New(t)
+
+		keys, err := New([]byte("secret")).PublicKeys()
+		assert.Nil(err)
+		assert.Equal(0, len(keys))
+		keys, err = New().PublicKeys()
+		assert.Nil(err)
+		assert.Equal(0, len(keys))
+	})
+}
@@ -0,0 +1,125 @@
+package jwt
+
+import (
+	"errors"
+	"time"
+
+	josecrypto "github.com/SermoDigital/jose/crypto"
+	josejwt "github.com/SermoDigital/jose/jwt"
+)
+
+// SetRefreshSigning sets a distinct signing method and keys for refresh tokens, they are used by
+// SignRefresh and VerifyRefresh only. Access tokens are never verified with the refresh keys and
+// refresh tokens are never verified with the access keys, so a leaked refresh verification key
+// can't be used to forge access tokens.
+// It panics if keys don't match the signing method, see CheckKey.
+//
+//  jwter := jwt.New(accessKey)
+//  jwter.SetRefreshSigning(josecrypto.SigningMethodHS512, refreshKey)
+//  jwter.SetRefreshExpiresIn(30 * 24 * time.Hour)
+//
+func (j *JWT) SetRefreshSigning(method josecrypto.SigningMethod, keys ...interface{}) {
+	if len(keys) == 0 || keys[0] == nil {
+		panic(errors.New("invalid keys"))
+	}
+	if method == nil {
+		panic(errors.New("invalid signing method"))
+	}
+	mustCheckKeys(method, keys)
+	j.refreshMethod = method
+	j.refreshKeys = keys
+}
+
+// SetRefreshExpiresIn set a default expire duration for refresh tokens.
+func (j *JWT) SetRefreshExpiresIn(expiresIn time.Duration) {
+	j.refreshExpiresIn = expiresIn
+}
+
+// SignRefresh creates a refresh token with the given content and optional expiresIn as Sign,
+// but signed with the refresh signing method and keys. It returns an error if SetRefreshSigning was not called.
+func (j *JWT) SignRefresh(content interface{}, expiresIn ...time.Duration) (string, error) {
+	r, err := j.refresher()
+	if err != nil {
+		return "", err
+	}
+	return r.Sign(content, expiresIn...)
+}
+
+// VerifyRefresh verifies a refresh token as Verify, but with the refresh signing method and keys.
+func (j *JWT) VerifyRefresh(token string) (josejwt.Claims, error) {
+	r, err := j.refresher()
+	if err != nil {
+		return nil, err
+	}
+	return r.Verify(token)
+}
+
+// TokenPair is an access token with its refresh token, see SignPair.
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// SignPair creates an access token and a refresh token with the same content. The access token
+// is signed as Sign, the refresh token as SignRefresh, so they have separate keys and expirations.
+// It returns an error if SetRefreshSigning was not called.
+//
+//  pair, err := jwter.SignPair(map[string]interface{}{"sub": "xxxxx"})
+//  // after the access token expired
+//  access, err := jwter.Refresh(pair.RefreshToken)
+//
+func (j *JWT) SignPair(content interface{}) (*TokenPair, error) {
+	r, err := j.refresher()
+	if err != nil {
+		return nil, err
+	}
+	claims, err := j.toClaims(content)
+	if err != nil {
+		return nil, err
+	}
+	pair := &TokenPair{}
+	if pair.AccessToken, err = j.Sign(copyClaims(claims)); err != nil {
+		return nil, err
+	}
+	if pair.RefreshToken, err = r.Sign(copyClaims(claims)); err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// Refresh verifies the refresh token as VerifyRefresh, and creates a new access token with its content.
+// The time claims ("iat", "nbf" and "exp"), "jti" and "ver" of the refresh token are not copied,
+// they are set again for the access token.
+func (j *JWT) Refresh(refreshToken string) (string, error) {
+	claims, err := j.VerifyRefresh(refreshToken)
+	if err != nil {
+		return "", err
+	}
+	for _, name := range []string{"iat", "nbf", "exp", "jti", "ver"} {
+		claims.Del(name)
+	}
+	return j.Sign(claims)
+}
+
+func copyClaims(claims josejwt.Claims) josejwt.Claims {
+	res := make(josejwt.Claims, len(claims))
+	for k, v := range claims {
+		res[k] = v
+	}
+	return res
+}
+
+// refresher returns a copy of the JWT that signs and verifies with the refresh signing,
+// all other options (issuer, audience, validators, etc.) are shared with access tokens.
+func (j *JWT) refresher() (*JWT, error) {
+	if j.refreshMethod == nil {
+		return nil, errors.New("refresh signing not set")
+	}
+	r := *j
+	r.method, r.keys = j.refreshMethod, j.refreshKeys
+	r.backupMethod, r.backupKeys = nil, nil
+	r.keySource, r.signKID = nil, ""
+	r.store = nil
+	r.expiresIn, r.expiresInFn = j.refreshExpiresIn, nil
+	return &r, nil
+}
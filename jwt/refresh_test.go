@@ -0,0 +1,141 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+
+	josecrypto "github.com/SermoDigital/jose/crypto"
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefreshSigning(t *testing.T) {
+	t.Run("should panic with invalid arguments", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("access key"))
+		assert.Panics(func() {
+			jwter.SetRefreshSigning(josecrypto.SigningMethodHS256)
+		})
+		assert.Panics(func() {
+			jwter.SetRefreshSigning(nil, []byte("refresh key"))
+		})
+		assert.Panics(func() {
+			jwter.SetRefreshSigning(josecrypto.SigningMethodRS256, []byte("refresh key"))
+		})
+	})
+
+	t.Run("should return error without refresh signing", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("access key"))
+		_, err := jwter.SignRefresh(josejwt.Claims{"sub": "user"})
+		assert.Equal("refresh signing not set", err.Error())
+		token, _ := jwter.Sign(josejwt.Claims{"sub": "user"})
+		_, err = jwter.VerifyRefresh(token)
+		assert.Equal("refresh signing not set", err.Error())
+	})
+
+	t.Run("should separate access and refresh tokens", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("access key"))
+		jwter.SetIssuer("gear")
+		jwter.SetExpiresIn(time.Minute)
+		jwter.SetRefreshSigning(josecrypto.SigningMethodHS512, []byte("refresh key"))
+		jwter.SetRefreshExpiresIn(time.Hour)
+
+		access, err := jwter.Sign(josejwt.Claims{"sub": "user"})
+		assert.Nil(err)
+		refresh, err := jwter.SignRefresh(josejwt.Claims{"sub": "user"})
+		assert.Nil(err)
+
+		claims, err := jwter.Verify(access)
+		assert.Nil(err)
+		exp, _ := claims.Expiration()
+		assert.True(exp.Before(time.Now().Add(2 * time.Minute)))
+		_, err = jwter.VerifyRefresh(access)
+		assert.NotNil(err)
+
+		claims, err = jwter.VerifyRefresh(refresh)
+		assert.Nil(err)
+		assert.Equal("user", claims.Get("sub"))
+		assert.Equal("gear", claims.Get("iss"))
+		exp, _ = claims.Expiration()
+		assert.True(exp.After(time.Now().Add(time.Minute)))
+		_, err = jwter.Verify(refresh)
+		assert.NotNil(err)
+
+		// a leaked refresh key can't forge access tokens
+		forged, err := New([]byte("refresh key")).Sign(josejwt.Claims{"sub": "admin"})
+		assert.Nil(err)
+		_, err = jwter.Verify(forged)
+		assert.NotNil(err)
+	})
+
+	t.Run("should not verify refresh tokens with backup signing", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("access key"))
+		jwter.SetBackupSigning(josecrypto.SigningMethodHS256, []byte("old key"))
+		jwter.SetRefreshSigning(josecrypto.SigningMethodHS256, []byte("refresh key"))
+
+		token, err := New([]byte("old key")).Sign(josejwt.Claims{"sub": "user"})
+		assert.Nil(err)
+		_, err = jwter.Verify(token)
+		assert.Nil(err)
+		_, err = jwter.VerifyRefresh(token)
+		assert.NotNil(err)
+	})
+	t.Run("should sign pair and refresh access token", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("access key"))
+		jwter.SetExpiresIn(time.Minute)
+		_, err := jwter.SignPair(josejwt.Claims{"sub": "user"})
+		assert.Equal("refresh signing not set", err.Error())
+		_, err = jwter.Refresh("token")
+		assert.Equal("refresh signing not set", err.Error())
+
+		jwter.SetRefreshSigning(josecrypto.SigningMethodHS512, []byte("refresh key"))
+		jwter.SetRefreshExpiresIn(time.Hour)
+		content := josejwt.Claims{"sub": "user", "role": "admin"}
+		pair, err := jwter.SignPair(content)
+		assert.Nil(err)
+		assert.Equal(2, len(content))
+
+		claims, err := jwter.Verify(pair.AccessToken)
+		assert.Nil(err)
+		assert.Equal("admin", claims.Get("role"))
+		exp, _ := claims.Expiration()
+		assert.True(exp.Before(time.Now().Add(2 * time.Minute)))
+		_, err = jwter.Verify(pair.RefreshToken)
+		assert.NotNil(err)
+		_, err = jwter.Refresh(pair.AccessToken)
+		assert.NotNil(err)
+
+		// a refresh token issued half an hour ago is still valid
+		refresh, err := jwter.refresher()
+		assert.Nil(err)
+		token, err := refresh.SignAt(time.Now().Add(-30*time.Minute), josejwt.Claims{"sub": "user", "jti": "abc"})
+		assert.Nil(err)
+		access, err := jwter.Refresh(token)
+		assert.Nil(err)
+		claims, err = jwter.Verify(access)
+		assert.Nil(err)
+		assert.Equal("user", claims.Get("sub"))
+		assert.NotEqual("abc", claims.Get("jti"))
+		iat, _ := claims.IssuedAt()
+		assert.True(iat.After(time.Now().Add(-time.Minute)))
+		exp, _ = claims.Expiration()
+		assert.True(exp.Before(time.Now().Add(2 * time.Minute)))
+
+		pair, err = jwter.SignPair(struct {
+			Sub string `json:"sub"`
+		}{"user"})
+		assert.Nil(err)
+		claims, err = jwter.Verify(pair.AccessToken)
+		assert.Nil(err)
+		assert.Equal("user", claims.Get("sub"))
+	})
+}
@@ -0,0 +1,152 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	josecrypto "github.com/SermoDigital/jose/crypto"
+	josejwt "github.com/SermoDigital/jose/jwt"
+)
+
+// IssuerConfig configures the verification of an issuer's tokens, see IssuerRegistry.Register.
+type IssuerConfig struct {
+	// Method is the signing method of the issuer's tokens, it is required.
+	Method josecrypto.SigningMethod
+	// Keys are the static keys to verify tokens, KeySource is used if it is empty.
+	Keys []interface{}
+	// KeySource provides keys on demand, such as a JWKS of the issuer.
+	KeySource KeySource
+	// Audience requires claim "aud" matching one of them if not empty, see SetExpectedAudience.
+	Audience []string
+	// Leeway is applied to "exp", "nbf" and "iat" for the clock skew of the issuer.
+	Leeway time.Duration
+}
+
+// IssuerRegistry is a ContextVerifier for multi-tenant APIs that accept tokens from several identity
+// providers. Each trusted issuer has its own verifier, such as a JWT with its own keys, algorithm,
+// audience and leeway. Tokens are dispatched by claim "iss" peeked before verification, and tokens of
+// unknown issuers are rejected with ErrBadIssuer. It is safe for concurrent use, so issuers can be
+// added or removed at runtime.
+//
+//  registry := jwt.NewIssuerRegistry()
+//  registry.Register("https://accounts.example.com", jwt.IssuerConfig{
+//  	Method:    josecrypto.SigningMethodRS256,
+//  	KeySource: jwt.NewJWKS("https://accounts.example.com/jwks.json", time.Hour),
+//  	Audience:  []string{"billing-api"},
+//  })
+//  partner, _ := jwt.NewFromIssuer(ctx, "https://login.partner.com")
+//  registry.Add("https://login.partner.com", partner)
+//  auther := auth.New().SetVerifier(registry)
+//
+type IssuerRegistry struct {
+	mu        sync.RWMutex
+	verifiers map[string]ContextVerifier
+}
+
+var _ ContextVerifier = (*IssuerRegistry)(nil)
+
+// NewIssuerRegistry returns an IssuerRegistry without issuers.
+func NewIssuerRegistry() *IssuerRegistry {
+	return &IssuerRegistry{verifiers: make(map[string]ContextVerifier)}
+}
+
+// Add adds or replaces the verifier of the issuer. The verifier should check the claims of the
+// issuer's tokens besides "iss", the registry checks "iss" after verification.
+// It panics if the issuer is empty or the verifier is nil.
+func (r *IssuerRegistry) Add(iss string, verifier ContextVerifier) *IssuerRegistry {
+	if iss == "" {
+		panic(errors.New("invalid issuer"))
+	}
+	if verifier == nil {
+		panic(errors.New("invalid verifier"))
+	}
+	r.mu.Lock()
+	r.verifiers[iss] = verifier
+	r.mu.Unlock()
+	return r
+}
+
+// Register adds a verify-only JWT for the issuer built from the config, and returns it for further
+// settings, such as SetSubjectFormat or AddContextValidator.
+// It panics if the issuer is empty, or the config has no method or keys.
+func (r *IssuerRegistry) Register(iss string, config IssuerConfig) *JWT {
+	j := New()
+	switch {
+	case len(config.Keys) > 0:
+		j.SetSigning(config.Method, config.Keys...)
+	case config.KeySource != nil:
+		j.SetKeySource(config.Method, config.KeySource)
+	default:
+		panic(errors.New("invalid keys"))
+	}
+	if len(config.Audience) > 0 {
+		j.SetExpectedAudience(config.Audience...)
+	}
+	if config.Leeway > 0 {
+		j.SetValidator(&josejwt.Validator{EXP: config.Leeway, NBF: config.Leeway})
+	}
+	j.SetExpectedIssuer(iss)
+	r.Add(iss, j)
+	return j
+}
+
+// Remove removes the issuer, its tokens are rejected from now on.
+func (r *IssuerRegistry) Remove(iss string) {
+	r.mu.Lock()
+	delete(r.verifiers, iss)
+	r.mu.Unlock()
+}
+
+// Issuers returns the trusted issuers in order.
+func (r *IssuerRegistry) Issuers() []string {
+	r.mu.RLock()
+	issuers := make([]string, 0, len(r.verifiers))
+	for iss := range r.verifiers {
+		issuers = append(issuers, iss)
+	}
+	r.mu.RUnlock()
+	sort.Strings(issuers)
+	return issuers
+}
+
+// Verify implements the Verifier interface.
+func (r *IssuerRegistry) Verify(token string) (josejwt.Claims, error) {
+	t, err := r.VerifyContext(context.Background(), token)
+	if err != nil {
+		return nil, err
+	}
+	return t.Claims, nil
+}
+
+// VerifyToken implements the TokenVerifier interface.
+func (r *IssuerRegistry) VerifyToken(token string) (*Token, error) {
+	return r.VerifyContext(context.Background(), token)
+}
+
+// VerifyContext implements the ContextVerifier interface. The token is verified by the verifier of
+// its "iss", the peeked "iss" is not trusted until the verifier accepts the token.
+func (r *IssuerRegistry) VerifyContext(ctx context.Context, token string) (*Token, error) {
+	claims, err := Decode(token)
+	if err != nil {
+		return nil, newError(err)
+	}
+	iss, _ := claims.Issuer()
+	r.mu.RLock()
+	verifier, ok := r.verifiers[iss]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, newError(ErrBadIssuer)
+	}
+	t, err := verifier.VerifyContext(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	// verifiers such as RemoteVerifier may return claims other than the peeked ones.
+	if verified, _ := t.Claims.Issuer(); verified != iss {
+		return nil, newError(ErrBadIssuer)
+	}
+	return t, nil
+}
@@ -0,0 +1,108 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	josecrypto "github.com/SermoDigital/jose/crypto"
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssuerRegistry(t *testing.T) {
+	t.Run("should panic with invalid arguments", func(t *testing.T) {
+		assert := assert.New(t)
+
+		registry := NewIssuerRegistry()
+		assert.Panics(func() {
+			registry.Add("", New([]byte("key")))
+		})
+		assert.Panics(func() {
+			registry.Add("https://a.example.com", nil)
+		})
+		assert.Panics(func() {
+			registry.Register("https://a.example.com", IssuerConfig{Method: josecrypto.SigningMethodHS256})
+		})
+		assert.Panics(func() {
+			registry.Register("https://a.example.com", IssuerConfig{Keys: []interface{}{[]byte("key")}})
+		})
+		assert.Panics(func() {
+			registry.Register("", IssuerConfig{Method: josecrypto.SigningMethodHS256, Keys: []interface{}{[]byte("key")}})
+		})
+	})
+
+	t.Run("should dispatch tokens by issuer", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwks := newTestJWKSServer()
+		defer jwks.Close()
+		key := jwks.addKey("key1")
+
+		registry := NewIssuerRegistry()
+		registry.Register("https://a.example.com", IssuerConfig{
+			Method:   josecrypto.SigningMethodHS256,
+			Keys:     []interface{}{[]byte("key a")},
+			Audience: []string{"billing-api"},
+		})
+		registry.Register("https://b.example.com", IssuerConfig{
+			Method:    josecrypto.SigningMethodES256,
+			KeySource: NewJWKS(jwks.URL, time.Minute),
+			Leeway:    time.Minute,
+		})
+		assert.Equal([]string{"https://a.example.com", "https://b.example.com"}, registry.Issuers())
+
+		signerA := New([]byte("key a"))
+		signerA.SetIssuer("https://a.example.com")
+		token, _ := signerA.Sign(josejwt.Claims{"sub": "alice", "aud": "billing-api"})
+		claims, err := registry.Verify(token)
+		assert.Nil(err)
+		assert.Equal("alice", claims.Get("sub"))
+
+		// the audience of issuer a is required
+		token, _ = signerA.Sign(josejwt.Claims{"sub": "alice", "aud": "web"})
+		_, err = registry.Verify(token)
+		assert.True(errors.Is(err, ErrBadAudience))
+
+		// the leeway of issuer b is applied
+		expired := time.Now().Add(-30 * time.Second).Unix()
+		tk, err := registry.VerifyContext(context.Background(), signWithKID(key, "key1",
+			josejwt.Claims{"iss": "https://b.example.com", "sub": "bob", "exp": expired}))
+		assert.Nil(err)
+		assert.Equal("bob", tk.Claims.Get("sub"))
+		assert.Equal("key1", tk.KeyID)
+
+		// the keys of issuer a can't verify tokens of issuer b
+		signerA.SetIssuer("https://b.example.com")
+		token, _ = signerA.Sign(josejwt.Claims{"sub": "alice"})
+		_, err = registry.VerifyToken(token)
+		assert.NotNil(err)
+
+		signerA.SetIssuer("https://c.example.com")
+		token, _ = signerA.Sign(josejwt.Claims{"sub": "alice"})
+		_, err = registry.Verify(token)
+		assert.True(errors.Is(err, ErrBadIssuer))
+		assert.Equal(401, err.(*Error).Status())
+
+		_, err = registry.Verify("invalid")
+		assert.NotNil(err)
+
+		registry.Remove("https://b.example.com")
+		assert.Equal([]string{"https://a.example.com"}, registry.Issuers())
+		_, err = registry.Verify(signWithKID(key, "key1", josejwt.Claims{"iss": "https://b.example.com"}))
+		assert.True(errors.Is(err, ErrBadIssuer))
+	})
+
+	t.Run("should work with any ContextVerifier", func(t *testing.T) {
+		assert := assert.New(t)
+
+		registry := NewIssuerRegistry()
+		registry.Add("https://a.example.com", New([]byte("key a")))
+		jwter := New([]byte("key a"))
+		jwter.SetIssuer("https://a.example.com")
+		token, _ := jwter.Sign(josejwt.Claims{"sub": "alice"})
+		_, err := registry.Verify(token)
+		assert.Nil(err)
+	})
+}
@@ -0,0 +1,238 @@
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+)
+
+// RemoteVerifier is a ContextVerifier that delegates verification to a central auth service, for
+// organizations that keep verification policy outside each service. By default it POSTs the token as
+// JSON {"token": "..."} to the service, and trusts its JSON verdict:
+//
+//  {"valid": true, "claims": {"sub": "alice", "exp": 1546300800}}
+//  {"valid": false, "error": "token revoked"}
+//
+// Other transports can be plugged by NewRemoteVerifierFunc, such as package remotegrpc.
+// Verdicts are cached by the hash of the token for the TTL, valid verdicts no longer than the "exp" claim.
+// Failures of the service (transport errors and non-200 responses) are not cached.
+type RemoteVerifier struct {
+	url     string
+	ttl     time.Duration
+	call    RemoteCall
+	client  *http.Client
+	breaker *CircuitBreaker
+	backoff *Backoff
+	cache   Cache
+}
+
+// RemoteVerdict is the verdict of the auth service on a token.
+type RemoteVerdict struct {
+	Valid  bool                   `json:"valid"`
+	Claims map[string]interface{} `json:"claims"`
+	Error  string                 `json:"error"`
+}
+
+// RemoteCall asks the auth service for the verdict on the token, it returns error only if the
+// service can't give a verdict. The context carries the deadline and cancellation of the request.
+type RemoteCall func(ctx context.Context, token string) (*RemoteVerdict, error)
+
+var _ ContextVerifier = (*RemoteVerifier)(nil)
+
+// NewRemoteVerifier returns a RemoteVerifier for the url of the auth service, verdicts are cached for ttl,
+// ttl <= 0 disables caching. Use SetHTTPClient to configure mTLS.
+//
+//  verifier := jwt.NewRemoteVerifier("https://auth.internal/verify", time.Minute)
+//  auther.SetVerifier(verifier)
+//
+func NewRemoteVerifier(url string, ttl time.Duration) *RemoteVerifier {
+	if url == "" {
+		panic(errors.New("invalid remote verifier url"))
+	}
+	v := newRemoteVerifier(ttl)
+	v.url = url
+	v.call = v.post
+	v.client = &http.Client{Timeout: 5 * time.Second}
+	return v
+}
+
+// NewRemoteVerifierFunc returns a RemoteVerifier that asks the auth service for verdicts by call,
+// verdicts are cached for ttl, ttl <= 0 disables caching.
+func NewRemoteVerifierFunc(call RemoteCall, ttl time.Duration) *RemoteVerifier {
+	if call == nil {
+		panic(errors.New("invalid remote call"))
+	}
+	v := newRemoteVerifier(ttl)
+	v.call = call
+	return v
+}
+
+func newRemoteVerifier(ttl time.Duration) *RemoteVerifier {
+	return &RemoteVerifier{ttl: ttl, cache: NewMemoryCache()}
+}
+
+// SetHTTPClient set a custom http.Client to call the auth service, such as with client certificates
+// for mTLS. Default to a client with 5 seconds timeout. It panics for verifiers created by NewRemoteVerifierFunc.
+//
+//  verifier.SetHTTPClient(&http.Client{
+//  	Timeout:   2 * time.Second,
+//  	Transport: &http.Transport{TLSClientConfig: &tls.Config{
+//  		Certificates: []tls.Certificate{clientCert},
+//  		RootCAs:      internalCAs,
+//  	}},
+//  })
+//
+func (v *RemoteVerifier) SetHTTPClient(client *http.Client) *RemoteVerifier {
+	if client == nil || v.url == "" {
+		panic(errors.New("invalid http client"))
+	}
+	v.client = client
+	return v
+}
+
+// SetCircuitBreaker set a CircuitBreaker for calling the auth service, so that an outage of it
+// doesn't make every request wait for the timeout. While the circuit is open, tokens are rejected.
+func (v *RemoteVerifier) SetCircuitBreaker(breaker *CircuitBreaker) *RemoteVerifier {
+	if breaker == nil {
+		panic(errors.New("invalid circuit breaker"))
+	}
+	v.breaker = breaker
+	return v
+}
+
+// SetBackoff set a Backoff to retry failed calls to the auth service within the request's deadline,
+// a circuit breaker counts them as one failure.
+func (v *RemoteVerifier) SetBackoff(backoff *Backoff) *RemoteVerifier {
+	if backoff == nil {
+		panic(errors.New("invalid backoff"))
+	}
+	v.backoff = backoff
+	return v
+}
+
+// SetCache set a Cache for verdicts, such as a Redis cache shared by all instances of a service.
+// Default to a MemoryCache.
+func (v *RemoteVerifier) SetCache(cache Cache) *RemoteVerifier {
+	if cache == nil {
+		panic(errors.New("invalid cache"))
+	}
+	v.cache = cache
+	return v
+}
+
+// Verify implements the Verifier interface.
+func (v *RemoteVerifier) Verify(token string) (josejwt.Claims, error) {
+	t, err := v.VerifyContext(context.Background(), token)
+	if err != nil {
+		return nil, err
+	}
+	return t.Claims, nil
+}
+
+// VerifyToken implements the TokenVerifier interface.
+func (v *RemoteVerifier) VerifyToken(token string) (*Token, error) {
+	return v.VerifyContext(context.Background(), token)
+}
+
+// VerifyContext implements the ContextVerifier interface, the context cancels the call to the auth service.
+func (v *RemoteVerifier) VerifyContext(ctx context.Context, token string) (*Token, error) {
+	sum := sha256.Sum256([]byte(token))
+	key := "verdict:" + hex.EncodeToString(sum[:])
+	verdict, ok := v.loadVerdict(key)
+	if !ok {
+		call := func() (err error) {
+			verdict, err = v.call(ctx, token)
+			return
+		}
+		if v.backoff != nil {
+			call = retry(ctx, v.backoff, call)
+		}
+		var err error
+		if v.breaker != nil {
+			err = v.breaker.Do(call)
+		} else {
+			err = call()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("remote verification failed: %v", err)
+		}
+		v.saveVerdict(key, verdict)
+	}
+
+	if !verdict.Valid {
+		msg := verdict.Error
+		if msg == "" {
+			msg = "token is invalid"
+		}
+		return nil, newError(errors.New(msg))
+	}
+	if verdict.Claims == nil {
+		verdict.Claims = make(map[string]interface{})
+	}
+	return &Token{Raw: token, Claims: verdict.Claims, KeyIndex: -1}, nil
+}
+
+func (v *RemoteVerifier) post(ctx context.Context, token string) (*RemoteVerdict, error) {
+	body, err := json.Marshal(map[string]string{"token": token})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("POST", v.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := v.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, &statusError{"auth service", res.StatusCode}
+	}
+	verdict := &RemoteVerdict{}
+	if err = json.NewDecoder(res.Body).Decode(verdict); err != nil {
+		return nil, err
+	}
+	return verdict, nil
+}
+
+func (v *RemoteVerifier) loadVerdict(key string) (*RemoteVerdict, bool) {
+	if v.ttl <= 0 {
+		return nil, false
+	}
+	data, err := v.cache.Get(key)
+	if err != nil {
+		return nil, false
+	}
+	verdict := &RemoteVerdict{}
+	if err = json.Unmarshal(data, verdict); err != nil {
+		return nil, false
+	}
+	return verdict, true
+}
+
+func (v *RemoteVerifier) saveVerdict(key string, verdict *RemoteVerdict) {
+	if v.ttl <= 0 {
+		return
+	}
+	ttl := v.ttl
+	if verdict.Valid {
+		if exp, ok := josejwt.Claims(verdict.Claims).Expiration(); ok && time.Until(exp) < ttl {
+			if ttl = time.Until(exp); ttl <= 0 {
+				return
+			}
+		}
+	}
+	if data, err := json.Marshal(verdict); err == nil {
+		v.cache.Set(key, data, ttl)
+	}
+}
@@ -0,0 +1,150 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoteVerifier(t *testing.T) {
+	newAuthService := func(calls *int) *httptest.Server {
+		return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*calls++
+			var body struct {
+				Token string `json:"token"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			switch body.Token {
+			case "good":
+				w.Write([]byte(`{"valid": true, "claims": {"sub": "alice"}}`))
+			case "expiring":
+				exp := time.Now().Add(-time.Second).Unix()
+				json.NewEncoder(w).Encode(map[string]interface{}{"valid": true, "claims": map[string]interface{}{"sub": "bob", "exp": exp}})
+			case "revoked":
+				w.Write([]byte(`{"valid": false, "error": "token revoked"}`))
+			case "slow":
+				time.Sleep(100 * time.Millisecond)
+				w.Write([]byte(`{"valid": true}`))
+			default:
+				w.WriteHeader(500)
+			}
+		}))
+	}
+
+	t.Run("should verify by the auth service", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.Panics(func() {
+			NewRemoteVerifier("", time.Minute)
+		})
+		assert.Panics(func() {
+			NewRemoteVerifier("http://auth", time.Minute).SetHTTPClient(nil)
+		})
+		assert.Panics(func() {
+			NewRemoteVerifier("http://auth", time.Minute).SetCircuitBreaker(nil)
+		})
+		assert.Panics(func() {
+			NewRemoteVerifierFunc(nil, time.Minute)
+		})
+
+		calls := 0
+		ts := newAuthService(&calls)
+		defer ts.Close()
+
+		verifier := NewRemoteVerifier(ts.URL, time.Minute)
+		_, err := verifier.Verify("good")
+		assert.Contains(err.Error(), "remote verification failed")
+
+		verifier.SetHTTPClient(ts.Client())
+		claims, err := verifier.Verify("good")
+		assert.Nil(err)
+		assert.Equal("alice", claims.Get("sub"))
+		claims.Set("sub", "mallory")
+
+		tk, err := verifier.VerifyToken("good")
+		assert.Nil(err)
+		assert.Equal("good", tk.Raw)
+		assert.Equal(-1, tk.KeyIndex)
+		assert.Equal("alice", tk.Claims.Get("sub"))
+		assert.Equal(1, calls)
+
+		_, err = verifier.Verify("revoked")
+		assert.Equal("token revoked", err.Error())
+		_, err = verifier.Verify("revoked")
+		assert.Equal("token revoked", err.Error())
+		assert.Equal(2, calls)
+
+		_, err = verifier.Verify("expiring")
+		assert.Nil(err)
+		_, err = verifier.Verify("expiring")
+		assert.Nil(err)
+		assert.Equal(4, calls)
+
+		_, err = verifier.Verify("unknown")
+		assert.Equal("remote verification failed: auth service responded 500", err.Error())
+		_, err = verifier.Verify("unknown")
+		assert.NotNil(err)
+		assert.Equal(6, calls)
+	})
+
+	t.Run("should work with context and circuit breaker", func(t *testing.T) {
+		assert := assert.New(t)
+
+		calls := 0
+		ts := newAuthService(&calls)
+		defer ts.Close()
+
+		verifier := NewRemoteVerifier(ts.URL, 0).SetHTTPClient(ts.Client())
+		verifier.SetCircuitBreaker(NewCircuitBreaker(1, time.Minute))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := verifier.VerifyContext(ctx, "slow")
+		assert.Contains(err.Error(), "remote verification failed")
+
+		_, err = verifier.Verify("good")
+		assert.Equal("remote verification failed: "+ErrCircuitOpen.Error(), err.Error())
+	})
+
+	t.Run("should work with custom transport", func(t *testing.T) {
+		assert := assert.New(t)
+
+		calls := 0
+		verifier := NewRemoteVerifierFunc(func(ctx context.Context, token string) (*RemoteVerdict, error) {
+			calls++
+			return &RemoteVerdict{Valid: token == "good"}, nil
+		}, time.Minute)
+		assert.Panics(func() {
+			verifier.SetHTTPClient(http.DefaultClient)
+		})
+
+		claims, err := verifier.Verify("good")
+		assert.Nil(err)
+		assert.Equal(0, len(claims))
+		_, err = verifier.Verify("bad")
+		assert.Equal("token is invalid", err.Error())
+		_, err = verifier.Verify("good")
+		assert.Nil(err)
+		assert.Equal(2, calls)
+	})
+
+	t.Run("should not cache if ttl <= 0", func(t *testing.T) {
+		assert := assert.New(t)
+
+		calls := 0
+		ts := newAuthService(&calls)
+		defer ts.Close()
+
+		verifier := NewRemoteVerifier(ts.URL, 0).SetHTTPClient(ts.Client())
+		_, err := verifier.Verify("good")
+		assert.Nil(err)
+		_, err = verifier.Verify("good")
+		assert.Nil(err)
+		assert.Equal(2, calls)
+	})
+}
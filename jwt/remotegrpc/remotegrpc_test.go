@@ -0,0 +1,112 @@
+package remotegrpc_test
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+
+	"github.com/teambition/gear-auth/jwt/remotegrpc"
+)
+
+type authService struct {
+	mu        sync.Mutex
+	calls     int
+	deadlines []bool
+}
+
+func (s *authService) Verify(ctx context.Context, req *remotegrpc.VerifyRequest) (*remotegrpc.VerifyResponse, error) {
+	s.mu.Lock()
+	s.calls++
+	_, ok := ctx.Deadline()
+	s.deadlines = append(s.deadlines, ok)
+	s.mu.Unlock()
+	switch req.Token {
+	case "good":
+		return &remotegrpc.VerifyResponse{Valid: true, Claims: `{"sub":"alice","admin":true}`}, nil
+	case "bad-claims":
+		return &remotegrpc.VerifyResponse{Valid: true, Claims: `[]`}, nil
+	case "slow":
+		time.Sleep(100 * time.Millisecond)
+		return &remotegrpc.VerifyResponse{Valid: true}, nil
+	default:
+		return &remotegrpc.VerifyResponse{Error: "token revoked"}, nil
+	}
+}
+
+func TestRemoteGRPC(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer()
+	service := &authService{}
+	remotegrpc.RegisterVerifierServer(srv, service)
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	t.Run("should verify by the auth service", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.Panics(func() {
+			remotegrpc.Dial("", 1)
+		})
+		assert.Panics(func() {
+			remotegrpc.Dial(lis.Addr().String(), 0)
+		})
+		assert.Panics(func() {
+			remotegrpc.NewVerifier(nil, time.Minute)
+		})
+
+		pool, err := remotegrpc.Dial(lis.Addr().String(), 2, grpc.WithInsecure())
+		assert.Nil(err)
+		defer pool.Close()
+		assert.Panics(func() {
+			pool.SetTimeout(0)
+		})
+
+		verifier := remotegrpc.NewVerifier(pool, time.Minute)
+		claims, err := verifier.Verify("good")
+		assert.Nil(err)
+		assert.Equal("alice", claims.Get("sub"))
+		assert.Equal(true, claims.Get("admin"))
+		_, err = verifier.Verify("good")
+		assert.Nil(err)
+
+		_, err = verifier.Verify("revoked")
+		assert.Equal("token revoked", err.Error())
+		_, err = verifier.Verify("bad-claims")
+		assert.Contains(err.Error(), "remote verification failed")
+
+		service.mu.Lock()
+		assert.Equal(3, service.calls)
+		assert.Equal([]bool{true, true, true}, service.deadlines)
+		service.mu.Unlock()
+	})
+
+	t.Run("should propagate the deadline", func(t *testing.T) {
+		assert := assert.New(t)
+
+		pool, err := remotegrpc.Dial(lis.Addr().String(), 1, grpc.WithInsecure())
+		assert.Nil(err)
+		defer pool.Close()
+
+		verifier := remotegrpc.NewVerifier(pool, 0)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err = verifier.VerifyContext(ctx, "slow")
+		assert.Contains(err.Error(), "DeadlineExceeded")
+
+		pool.SetTimeout(20 * time.Millisecond)
+		_, err = verifier.Verify("slow")
+		assert.Contains(err.Error(), "DeadlineExceeded")
+
+		pool.SetTimeout(time.Second)
+		_, err = verifier.Verify("slow")
+		assert.Nil(err)
+	})
+}
@@ -0,0 +1,80 @@
+package remotegrpc
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+)
+
+// Messages and service descriptor of verify.proto, keep them in sync with it.
+
+const verifyMethod = "/gearauth.verify.v1.Verifier/Verify"
+
+// VerifyRequest is the request message of Verifier.Verify.
+type VerifyRequest struct {
+	Token string `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+}
+
+// Reset implements the proto.Message interface.
+func (m *VerifyRequest) Reset() { *m = VerifyRequest{} }
+
+// String implements the proto.Message interface.
+func (m *VerifyRequest) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements the proto.Message interface.
+func (*VerifyRequest) ProtoMessage() {}
+
+// VerifyResponse is the response message of Verifier.Verify.
+type VerifyResponse struct {
+	Valid bool `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	// Claims is the JSON object of the verified claims.
+	Claims string `protobuf:"bytes,2,opt,name=claims,proto3" json:"claims,omitempty"`
+	// Error is the reason why the token is invalid.
+	Error string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+// Reset implements the proto.Message interface.
+func (m *VerifyResponse) Reset() { *m = VerifyResponse{} }
+
+// String implements the proto.Message interface.
+func (m *VerifyResponse) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage implements the proto.Message interface.
+func (*VerifyResponse) ProtoMessage() {}
+
+// VerifierServer is the server API of the Verifier service, auth services implement it.
+type VerifierServer interface {
+	Verify(ctx context.Context, req *VerifyRequest) (*VerifyResponse, error)
+}
+
+// RegisterVerifierServer registers the Verifier service implementation to the gRPC server.
+func RegisterVerifierServer(s *grpc.Server, srv VerifierServer) {
+	s.RegisterService(&verifierServiceDesc, srv)
+}
+
+var verifierServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gearauth.verify.v1.Verifier",
+	HandlerType: (*VerifierServer)(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Verify",
+		Handler:    verifyHandler,
+	}},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "verify.proto",
+}
+
+func verifyHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	req := &VerifyRequest{}
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VerifierServer).Verify(ctx, req)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: verifyMethod}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VerifierServer).Verify(ctx, req.(*VerifyRequest))
+	}
+	return interceptor(ctx, req, info, handler)
+}
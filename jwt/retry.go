@@ -0,0 +1,93 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/url"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Backoff retries remote operations (JWKS fetching, remote verification, etc.) with exponential backoff
+// and jitter. Only retryable failures are retried: network errors, 5xx or 429 responses and gRPC
+// Unavailable or DeadlineExceeded errors (such as of remotegrpc), other failures such as 4xx responses
+// or malformed results are returned immediately.
+type Backoff struct {
+	retries int
+	min     time.Duration
+	max     time.Duration
+}
+
+// NewBackoff returns a Backoff that retries at most retries times, waiting min for the first retry,
+// doubled for every next retry but no longer than max. A random jitter up to half of the wait is
+// subtracted, so that instances don't retry in lockstep.
+//
+//  backoff := jwt.NewBackoff(3, 100*time.Millisecond, 2*time.Second)
+//  jwks.SetBackoff(backoff)
+//
+func NewBackoff(retries int, min, max time.Duration) *Backoff {
+	if retries <= 0 || min <= 0 || max < min {
+		panic(errors.New("invalid backoff arguments"))
+	}
+	return &Backoff{retries: retries, min: min, max: max}
+}
+
+// Do calls fn, and retries it if it returns a retryable error, until it succeeds, the retries are
+// exhausted or the context is done. It returns the last error of fn.
+func (b *Backoff) Do(ctx context.Context, fn func() error) error {
+	wait := b.min
+	for i := 0; ; i++ {
+		err := fn()
+		if err == nil || i >= b.retries || !isRetryable(err) {
+			return err
+		}
+		timer := time.NewTimer(wait - time.Duration(rand.Int63n(int64(wait)/2+1)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		if wait *= 2; wait > b.max {
+			wait = b.max
+		}
+	}
+}
+
+// retry wraps fn to be retried by the backoff.
+func retry(ctx context.Context, b *Backoff, fn func() error) func() error {
+	return func() error {
+		return b.Do(ctx, fn)
+	}
+}
+
+// statusError is returned by remote operations when the server responded a unexpected status code.
+type statusError struct {
+	server string
+	code   int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s responded %d", e.server, e.code)
+}
+
+// isRetryable reports whether the error of a remote operation is worth retrying.
+func isRetryable(err error) bool {
+	switch e := err.(type) {
+	case *statusError:
+		return e.code >= 500 || e.code == 429
+	case *url.Error:
+		return true
+	case net.Error:
+		return true
+	}
+	if st, ok := status.FromError(err); ok {
+		return st.Code() == codes.Unavailable || st.Code() == codes.DeadlineExceeded
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBackoff(t *testing.T) {
+	t.Run("should retry retryable errors", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.Panics(func() {
+			NewBackoff(0, time.Millisecond, time.Second)
+		})
+		assert.Panics(func() {
+			NewBackoff(3, 0, time.Second)
+		})
+		assert.Panics(func() {
+			NewBackoff(3, time.Second, time.Millisecond)
+		})
+
+		backoff := NewBackoff(3, time.Millisecond, 2*time.Millisecond)
+		calls := 0
+		err := backoff.Do(context.Background(), func() error {
+			calls++
+			if calls < 3 {
+				return &statusError{"test", 503}
+			}
+			return nil
+		})
+		assert.Nil(err)
+		assert.Equal(3, calls)
+
+		calls = 0
+		err = backoff.Do(context.Background(), func() error {
+			calls++
+			return &statusError{"test", 500}
+		})
+		assert.Equal("test responded 500", err.Error())
+		assert.Equal(4, calls)
+
+		calls = 0
+		err = backoff.Do(context.Background(), func() error {
+			calls++
+			return &statusError{"test", 404}
+		})
+		assert.Equal("test responded 404", err.Error())
+		assert.Equal(1, calls)
+
+		calls = 0
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err = NewBackoff(3, time.Hour, time.Hour).Do(ctx, func() error {
+			calls++
+			return &statusError{"test", 429}
+		})
+		assert.NotNil(err)
+		assert.Equal(1, calls)
+	})
+
+	t.Run("isRetryable", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.True(isRetryable(&statusError{"test", 502}))
+		assert.True(isRetryable(&statusError{"test", 429}))
+		assert.False(isRetryable(&statusError{"test", 401}))
+		assert.True(isRetryable(&url.Error{Op: "Get", URL: "http://idp", Err: errors.New("connection refused")}))
+		assert.False(isRetryable(errors.New("invalid character")))
+		assert.True(isRetryable(status.Error(codes.Unavailable, "connection refused")))
+		assert.True(isRetryable(status.Error(codes.DeadlineExceeded, "context deadline exceeded")))
+		assert.False(isRetryable(status.Error(codes.InvalidArgument, "invalid token")))
+		assert.False(isRetryable(status.Error(codes.Unauthenticated, "unauthenticated")))
+	})
+
+	t.Run("JWKS with backoff", func(t *testing.T) {
+		assert := assert.New(t)
+
+		srv := newTestJWKSServer()
+		defer srv.Close()
+		srv.addKey("key1")
+		var failures int32 = 2
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&failures, -1) >= 0 {
+				w.WriteHeader(503)
+				return
+			}
+			res, err := http.Get(srv.URL)
+			if err != nil {
+				w.WriteHeader(502)
+				return
+			}
+			defer res.Body.Close()
+			io.Copy(w, res.Body)
+		}))
+		defer proxy.Close()
+
+		assert.Panics(func() {
+			NewJWKS(proxy.URL, time.Minute).SetBackoff(nil)
+		})
+		_, err := NewJWKS(proxy.URL, time.Minute).Keys()
+		assert.Equal("JWKS responded 503", err.Error())
+
+		jwks := NewJWKS(proxy.URL, time.Minute).SetBackoff(NewBackoff(3, time.Millisecond, time.Millisecond))
+		keys, err := jwks.Keys()
+		assert.Nil(err)
+		assert.Equal(1, len(keys))
+	})
+
+	t.Run("RemoteVerifier with backoff", func(t *testing.T) {
+		assert := assert.New(t)
+
+		calls := 0
+		verifier := NewRemoteVerifierFunc(func(ctx context.Context, token string) (*RemoteVerdict, error) {
+			calls++
+			if calls == 1 {
+				return nil, &statusError{"auth service", 503}
+			}
+			return &RemoteVerdict{Valid: true}, nil
+		}, 0)
+		assert.Panics(func() {
+			verifier.SetBackoff(nil)
+		})
+		verifier.SetBackoff(NewBackoff(1, time.Millisecond, time.Millisecond))
+		_, err := verifier.Verify("good")
+		assert.Nil(err)
+		assert.Equal(2, calls)
+	})
+}
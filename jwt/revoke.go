@@ -0,0 +1,137 @@
+package jwt
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+)
+
+// ErrTokenRevoked is returned by Verify when the token's "jti" is revoked.
+var ErrTokenRevoked = errors.New("token revoked")
+
+// Revoker is a blocklist of revoked tokens by claim "jti". When a Revoker is set to a JWT instance
+// by SetRevoker, Verify consults it after the signature is verified. Tokens without "jti" can't be revoked.
+type Revoker interface {
+	// Revoke revokes the token with the jti, exp is the token's expiration,
+	// the revocation can be dropped after it.
+	Revoke(jti string, exp time.Time) error
+	// IsRevoked reports whether the token with the jti is revoked.
+	IsRevoked(jti string) bool
+}
+
+// SubjectRevoker is a Revoker that can also revoke all tokens of a subject issued before a cutoff,
+// such as on password change. When the JWT's Revoker implements it, Verify rejects tokens
+// whose "iat" is before the cutoff of their "sub", or without "iat".
+type SubjectRevoker interface {
+	Revoker
+	// RevokeSubject revokes all tokens of the subject issued before the time.
+	RevokeSubject(sub string, before time.Time) error
+	// RevokedBefore returns the cutoff of the subject, or zero time if the subject isn't revoked.
+	RevokedBefore(sub string) time.Time
+}
+
+// SetRevoker set a Revoker to jwt, tokens with revoked "jti" will be rejected by Verify.
+func (j *JWT) SetRevoker(revoker Revoker) {
+	if revoker == nil {
+		panic(errors.New("invalid revoker"))
+	}
+	j.revoker = revoker
+}
+
+// checkRevoked rejects tokens whose "jti" is revoked, or whose subject is revoked after they were issued.
+func (j *JWT) checkRevoked(claims josejwt.Claims) error {
+	if j.revoker == nil {
+		return nil
+	}
+	if jti, _ := claims.JWTID(); jti != "" && j.revoker.IsRevoked(jti) {
+		return ErrTokenRevoked
+	}
+	if sr, ok := j.revoker.(SubjectRevoker); ok {
+		if sub, _ := claims.Subject(); sub != "" {
+			if before := sr.RevokedBefore(sub); !before.IsZero() {
+				// "iat" has seconds precision, tokens issued in the same second as the cutoff are accepted.
+				if iat, ok := claims.IssuedAt(); !ok || iat.Unix() < before.Unix() {
+					return ErrTokenRevoked
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// MemoryRevoker is a in-memory Revoker and RevocationSource implementation, it is suitable for single
+// process deployments and tests. Revocations are dropped after the token's expiration, expired entries
+// are swept periodically on Revoke. A zero expiration (tokens without "exp") means the revocation never expires.
+//
+//  revoker := jwt.NewMemoryRevoker()
+//  jwter.SetRevoker(revoker)
+//  // on logout
+//  err = revoker.Revoke(jti, exp)
+//
+type MemoryRevoker struct {
+	mu        sync.RWMutex
+	revoked   map[string]time.Time
+	lastSweep time.Time
+}
+
+var _ RevocationSource = (*MemoryRevoker)(nil)
+
+// NewMemoryRevoker returns a MemoryRevoker instance.
+func NewMemoryRevoker() *MemoryRevoker {
+	return &MemoryRevoker{revoked: make(map[string]time.Time), lastSweep: time.Now()}
+}
+
+// Revoke implements the Revoker interface.
+func (r *MemoryRevoker) Revoke(jti string, exp time.Time) error {
+	if jti == "" {
+		return errors.New("invalid jti")
+	}
+	now := time.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// the expiration never moves backward, zero time is the latest.
+	if old, ok := r.revoked[jti]; !ok || exp.IsZero() || (!old.IsZero() && exp.After(old)) {
+		r.revoked[jti] = exp
+	}
+
+	if now.Sub(r.lastSweep) > time.Minute {
+		r.lastSweep = now
+		for key, exp := range r.revoked {
+			if revocationExpired(exp, now) {
+				delete(r.revoked, key)
+			}
+		}
+	}
+	return nil
+}
+
+// IsRevoked implements the Revoker interface.
+func (r *MemoryRevoker) IsRevoked(jti string) bool {
+	r.mu.RLock()
+	exp, ok := r.revoked[jti]
+	r.mu.RUnlock()
+	return ok && !revocationExpired(exp, time.Now())
+}
+
+// Revoked implements the RevocationSource interface.
+func (r *MemoryRevoker) Revoked() ([]string, error) {
+	now := time.Now()
+	r.mu.RLock()
+	res := make([]string, 0, len(r.revoked))
+	for jti, exp := range r.revoked {
+		if !revocationExpired(exp, now) {
+			res = append(res, jti)
+		}
+	}
+	r.mu.RUnlock()
+	sort.Strings(res)
+	return res, nil
+}
+
+// revocationExpired reports whether a revocation with the expiration has expired, zero time never expires.
+func revocationExpired(exp, now time.Time) bool {
+	return !exp.IsZero() && now.After(exp)
+}
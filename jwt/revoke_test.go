@@ -0,0 +1,87 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRevoker(t *testing.T) {
+	t.Run("should revoke and expire tokens", func(t *testing.T) {
+		assert := assert.New(t)
+
+		revoker := NewMemoryRevoker()
+		assert.Equal("invalid jti", revoker.Revoke("", time.Now().Add(time.Hour)).Error())
+		assert.Nil(revoker.Revoke("a", time.Now().Add(time.Hour)))
+		assert.Nil(revoker.Revoke("b", time.Now().Add(-time.Second)))
+		assert.True(revoker.IsRevoked("a"))
+		assert.False(revoker.IsRevoked("b"))
+		assert.False(revoker.IsRevoked("c"))
+
+		// the expiration never moves backward
+		assert.Nil(revoker.Revoke("a", time.Now().Add(-time.Second)))
+		assert.True(revoker.IsRevoked("a"))
+
+		jtis, err := revoker.Revoked()
+		assert.Nil(err)
+		assert.Equal([]string{"a"}, jtis)
+
+		revoker.lastSweep = time.Now().Add(-2 * time.Minute)
+		assert.Nil(revoker.Revoke("c", time.Now().Add(time.Hour)))
+		assert.Equal(2, len(revoker.revoked))
+		jtis, _ = revoker.Revoked()
+		assert.Equal([]string{"a", "c"}, jtis)
+	})
+
+	t.Run("should never expire revocations of tokens without exp", func(t *testing.T) {
+		assert := assert.New(t)
+
+		revoker := NewMemoryRevoker()
+		assert.Nil(revoker.Revoke("a", time.Time{}))
+		assert.True(revoker.IsRevoked("a"))
+
+		// a zero expiration is not replaced by a finite one
+		assert.Nil(revoker.Revoke("a", time.Now().Add(-time.Second)))
+		assert.True(revoker.IsRevoked("a"))
+		assert.Nil(revoker.Revoke("b", time.Now().Add(time.Hour)))
+		assert.Nil(revoker.Revoke("b", time.Time{}))
+
+		revoker.lastSweep = time.Now().Add(-2 * time.Minute)
+		assert.Nil(revoker.Revoke("c", time.Now().Add(-time.Second)))
+		jtis, err := revoker.Revoked()
+		assert.Nil(err)
+		assert.Equal([]string{"a", "b"}, jtis)
+		assert.True(revoker.IsRevoked("b"))
+
+		jwter := New([]byte("key"))
+		jwter.SetRevoker(revoker)
+		token, _ := jwter.Sign(josejwt.Claims{"jti": "d"})
+		_, err = jwter.Verify(token)
+		assert.Nil(err)
+		assert.Nil(revoker.Revoke("d", time.Time{}))
+		_, err = jwter.Verify(token)
+		assert.NotNil(err)
+	})
+
+	t.Run("should reject revoked tokens in Verify", func(t *testing.T) {
+		assert := assert.New(t)
+
+		revoker := NewMemoryRevoker()
+		jwter := New([]byte("key"))
+		jwter.SetRevoker(revoker)
+		token, _ := jwter.Sign(josejwt.Claims{"jti": "abc"}, time.Hour)
+		claims, err := jwter.Verify(token)
+		assert.Nil(err)
+
+		exp, _ := claims.Expiration()
+		assert.Nil(revoker.Revoke("abc", exp))
+		_, err = jwter.Verify(token)
+		assert.Equal("token revoked", err.Error())
+
+		token, _ = jwter.Sign(josejwt.Claims{"jti": "xyz"}, time.Hour)
+		_, err = jwter.Verify(token)
+		assert.Nil(err)
+	})
+}
@@ -0,0 +1,208 @@
+// Package revokebolt implements jwt.Revoker on top of an embedded bbolt database file,
+// for single-node deployments where revocations must survive restarts without running Redis.
+package revokebolt
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+
+	authJwt "github.com/teambition/gear-auth/jwt"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	bucketRevoked  = []byte("revoked")
+	bucketSubjects = []byte("subjects")
+)
+
+// Revoker is a jwt.RevocationSource and jwt.SubjectRevoker backed by a bbolt database, revoked "jti" are
+// stored with their expiration, expired entries are swept periodically on Revoke.
+type Revoker struct {
+	db        *bolt.DB
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+var (
+	_ authJwt.RevocationSource = (*Revoker)(nil)
+	_ authJwt.SubjectRevoker   = (*Revoker)(nil)
+)
+
+// Open opens (or creates) the database file and returns a Revoker instance.
+//
+//  revoker, err := revokebolt.Open("/var/lib/myapp/revoked.db")
+//  if err != nil {
+//  	panic(err)
+//  }
+//  defer revoker.Close()
+//  jwter.SetRevoker(revoker)
+//
+func Open(path string) (*Revoker, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	r, err := New(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// New returns a Revoker instance with an opened database, the "revoked" and "subjects" buckets
+// are created if not exists.
+func New(db *bolt.DB) (*Revoker, error) {
+	if db == nil {
+		panic(errors.New("invalid bolt database"))
+	}
+	err := db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(bucketRevoked); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(bucketSubjects)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Revoker{db: db, lastSweep: time.Now()}, nil
+}
+
+// Revoke implements the jwt.Revoker interface.
+func (r *Revoker) Revoke(jti string, exp time.Time) error {
+	if jti == "" {
+		return errors.New("invalid jti")
+	}
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketRevoked).Put([]byte(jti), encodeTime(exp))
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	r.mu.Lock()
+	sweep := now.Sub(r.lastSweep) > time.Minute
+	if sweep {
+		r.lastSweep = now
+	}
+	r.mu.Unlock()
+	if sweep {
+		return r.Sweep()
+	}
+	return nil
+}
+
+// IsRevoked implements the jwt.Revoker interface. It fails closed: the token is treated as revoked
+// when the database can't be read.
+func (r *Revoker) IsRevoked(jti string) bool {
+	revoked := true
+	r.db.View(func(tx *bolt.Tx) error {
+		val := tx.Bucket(bucketRevoked).Get([]byte(jti))
+		revoked = val != nil && !expired(val, time.Now())
+		return nil
+	})
+	return revoked
+}
+
+// RevokeSubject implements the jwt.SubjectRevoker interface. The cutoff never moves backward.
+func (r *Revoker) RevokeSubject(sub string, before time.Time) error {
+	if sub == "" {
+		return errors.New("invalid sub")
+	}
+	return r.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketSubjects)
+		if val := b.Get([]byte(sub)); len(val) == 8 && int64(binary.BigEndian.Uint64(val)) >= before.Unix() {
+			return nil
+		}
+		return b.Put([]byte(sub), encodeTime(before))
+	})
+}
+
+// RevokedBefore implements the jwt.SubjectRevoker interface. It fails closed: the current time is
+// returned when the database can't be read.
+func (r *Revoker) RevokedBefore(sub string) time.Time {
+	before := time.Now()
+	r.db.View(func(tx *bolt.Tx) error {
+		before = time.Time{}
+		if val := tx.Bucket(bucketSubjects).Get([]byte(sub)); len(val) == 8 {
+			before = time.Unix(int64(binary.BigEndian.Uint64(val)), 0)
+		}
+		return nil
+	})
+	return before
+}
+
+// Revoked implements the jwt.RevocationSource interface.
+func (r *Revoker) Revoked() ([]string, error) {
+	var ids []string
+	now := time.Now()
+	err := r.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketRevoked).ForEach(func(key, val []byte) error {
+			if !expired(val, now) {
+				ids = append(ids, string(key))
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// Sweep deletes expired revocations.
+func (r *Revoker) Sweep() error {
+	now := time.Now()
+	return r.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketRevoked)
+		var keys [][]byte
+		b.ForEach(func(key, val []byte) error {
+			if expired(val, now) {
+				keys = append(keys, append([]byte(nil), key...))
+			}
+			return nil
+		})
+		for _, key := range keys {
+			if err := b.Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Check implements the jwt.HealthChecker interface, it reports whether the database is readable.
+func (r *Revoker) Check() error {
+	return r.db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(bucketRevoked) == nil || tx.Bucket(bucketSubjects) == nil {
+			return errors.New("bucket not found")
+		}
+		return nil
+	})
+}
+
+// Close closes the database.
+func (r *Revoker) Close() error {
+	return r.db.Close()
+}
+
+// encodeTime encodes the expiration as unix seconds, zero time means never expires.
+func encodeTime(t time.Time) []byte {
+	buf := make([]byte, 8)
+	if !t.IsZero() {
+		binary.BigEndian.PutUint64(buf, uint64(t.Unix()))
+	}
+	return buf
+}
+
+func expired(val []byte, now time.Time) bool {
+	if len(val) != 8 {
+		return false
+	}
+	exp := int64(binary.BigEndian.Uint64(val))
+	return exp != 0 && now.Unix() >= exp
+}
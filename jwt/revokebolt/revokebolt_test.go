@@ -0,0 +1,122 @@
+package revokebolt_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	josecrypto "github.com/SermoDigital/jose/crypto"
+	josejws "github.com/SermoDigital/jose/jws"
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/teambition/gear-auth/jwt"
+	"github.com/teambition/gear-auth/jwt/revokebolt"
+)
+
+func TestRevoker(t *testing.T) {
+	dir, err := ioutil.TempDir("", "revokebolt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "revoked.db")
+
+	t.Run("should revoke and survive restarts", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.Panics(func() {
+			revokebolt.New(nil)
+		})
+
+		revoker, err := revokebolt.Open(path)
+		assert.Nil(err)
+		assert.Nil(revoker.Check())
+		assert.NotNil(revoker.Revoke("", time.Now()))
+		assert.Nil(revoker.Revoke("abc", time.Now().Add(time.Hour)))
+		assert.Nil(revoker.Revoke("forever", time.Time{}))
+		assert.Nil(revoker.Revoke("expired", time.Now().Add(-time.Second)))
+		assert.True(revoker.IsRevoked("abc"))
+		assert.False(revoker.IsRevoked("expired"))
+		assert.False(revoker.IsRevoked("xyz"))
+		assert.Nil(revoker.Close())
+		assert.NotNil(revoker.Check())
+		assert.True(revoker.IsRevoked("xyz"))
+
+		revoker, err = revokebolt.Open(path)
+		assert.Nil(err)
+		defer revoker.Close()
+		assert.True(revoker.IsRevoked("abc"))
+		assert.True(revoker.IsRevoked("forever"))
+
+		ids, err := revoker.Revoked()
+		assert.Nil(err)
+		sort.Strings(ids)
+		assert.Equal([]string{"abc", "forever"}, ids)
+
+		assert.Nil(revoker.Sweep())
+		assert.False(revoker.IsRevoked("expired"))
+		ids, _ = revoker.Revoked()
+		assert.Equal(2, len(ids))
+	})
+
+	t.Run("should work with jwt", func(t *testing.T) {
+		assert := assert.New(t)
+
+		revoker, err := revokebolt.Open(filepath.Join(dir, "jwt.db"))
+		assert.Nil(err)
+		defer revoker.Close()
+
+		jwter := jwt.New([]byte("key1"))
+		jwter.SetRevoker(revoker)
+		token, _ := jwter.Sign(josejwt.Claims{"jti": "abc"}, time.Hour)
+		_, err = jwter.Verify(token)
+		assert.Nil(err)
+
+		revoker.Revoke("abc", time.Now().Add(time.Hour))
+		_, err = jwter.Verify(token)
+		assert.Contains(err.Error(), jwt.ErrTokenRevoked.Error())
+
+		bloom, err := jwt.NewBloomRevoker(revoker, 0.01, time.Hour)
+		assert.Nil(err)
+		defer bloom.Stop()
+		assert.True(bloom.IsRevoked("abc"))
+		assert.False(bloom.IsRevoked("xyz"))
+	})
+
+	t.Run("should revoke subjects", func(t *testing.T) {
+		assert := assert.New(t)
+
+		revoker, err := revokebolt.Open(filepath.Join(dir, "subjects.db"))
+		assert.Nil(err)
+
+		jwter := jwt.New([]byte("key1"))
+		jwter.SetRevoker(revoker)
+		at := time.Now().Add(-time.Minute)
+		token, _ := jwter.SignAt(at, josejwt.Claims{"sub": "alice"}, time.Hour)
+		other, _ := jwter.SignAt(at, josejwt.Claims{"sub": "bob"}, time.Hour)
+		noIat, _ := josejws.NewJWT(josejws.Claims{"sub": "alice"}, josecrypto.SigningMethodHS256).Serialize([]byte("key1"))
+
+		assert.True(revoker.RevokedBefore("alice").IsZero())
+		assert.NotNil(revoker.RevokeSubject("", time.Now()))
+		assert.Nil(revoker.RevokeSubject("alice", at.Add(time.Second)))
+		assert.Nil(revoker.RevokeSubject("alice", at))
+		assert.Equal(at.Add(time.Second).Unix(), revoker.RevokedBefore("alice").Unix())
+
+		_, err = jwter.Verify(token)
+		assert.Contains(err.Error(), jwt.ErrTokenRevoked.Error())
+		_, err = jwter.Verify(string(noIat))
+		assert.Contains(err.Error(), jwt.ErrTokenRevoked.Error())
+		_, err = jwter.Verify(other)
+		assert.Nil(err)
+		newToken, _ := jwter.Sign(josejwt.Claims{"sub": "alice"}, time.Hour)
+		_, err = jwter.Verify(newToken)
+		assert.Nil(err)
+
+		assert.Nil(revoker.Close())
+		assert.False(revoker.RevokedBefore("bob").IsZero())
+	})
+}
@@ -0,0 +1,70 @@
+// Package revokeredis implements jwt.Revoker with Redis, so that revoked tokens are rejected by all
+// instances of a service. Every revoked "jti" is stored as a key, which expires with the token.
+//
+//  client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+//  jwter.SetRevoker(revokeredis.New(client, "myapp:revoked:"))
+//
+package revokeredis
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/teambition/gear-auth/jwt"
+)
+
+// Revoker is a jwt.Revoker backed by Redis.
+type Revoker struct {
+	client redis.Cmdable
+	prefix string
+}
+
+var _ jwt.Revoker = (*Revoker)(nil)
+
+// New returns a Revoker with the Redis client, such as *redis.Client or *redis.ClusterClient.
+// Revoked "jti" keys are prefixed with prefix.
+func New(client redis.Cmdable, prefix string) *Revoker {
+	if client == nil {
+		panic(errors.New("invalid redis client"))
+	}
+	return &Revoker{client: client, prefix: prefix}
+}
+
+// revokeScript stores the key with TTL (milliseconds), the TTL never moves backward.
+// TTL 0 stores the key without TTL, a key without TTL (PTTL -1) is never given one.
+var revokeScript = redis.NewScript(`
+local ttl = redis.call('PTTL', KEYS[1])
+if ARGV[1] == '0' then
+	redis.call('SET', KEYS[1], '1')
+elseif ttl ~= -1 and ttl < tonumber(ARGV[1]) then
+	redis.call('SET', KEYS[1], '1', 'PX', ARGV[1])
+end
+return 'ok'
+`)
+
+// Revoke implements the jwt.Revoker interface. Expired tokens are not stored. A zero exp (tokens
+// without "exp") means the token never expires, so it is stored without TTL.
+func (r *Revoker) Revoke(jti string, exp time.Time) error {
+	if jti == "" {
+		return errors.New("invalid jti")
+	}
+	var ttl int64
+	if !exp.IsZero() {
+		if ttl = unixMilli(exp) - unixMilli(time.Now()); ttl <= 0 {
+			return nil
+		}
+	}
+	return revokeScript.Run(r.client, []string{r.prefix + jti}, ttl).Err()
+}
+
+// IsRevoked implements the jwt.Revoker interface. It fails closed: the token is treated as revoked
+// when Redis can't be read.
+func (r *Revoker) IsRevoked(jti string) bool {
+	n, err := r.client.Exists(r.prefix + jti).Result()
+	return err != nil || n > 0
+}
+
+func unixMilli(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
@@ -0,0 +1,103 @@
+package revokeredis_test
+
+import (
+	"testing"
+	"time"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/alicebob/miniredis"
+	"github.com/go-redis/redis"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/teambition/gear-auth/jwt"
+	"github.com/teambition/gear-auth/jwt/revokeredis"
+)
+
+func TestRevoker(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mr.Close()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	t.Run("should revoke tokens until they expire", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.Panics(func() {
+			revokeredis.New(nil, "")
+		})
+
+		revoker := revokeredis.New(client, "revoked:")
+		assert.Equal("invalid jti", revoker.Revoke("", time.Now().Add(time.Hour)).Error())
+		assert.Nil(revoker.Revoke("a", time.Now().Add(time.Hour)))
+		assert.Nil(revoker.Revoke("b", time.Now().Add(-time.Second)))
+		assert.True(revoker.IsRevoked("a"))
+		assert.False(revoker.IsRevoked("b"))
+		assert.False(mr.Exists("revoked:b"))
+		ttl := mr.TTL("revoked:a")
+		assert.True(ttl > 59*time.Minute && ttl <= time.Hour)
+
+		// the TTL never moves backward
+		assert.Nil(revoker.Revoke("a", time.Now().Add(time.Minute)))
+		assert.True(mr.TTL("revoked:a") > 59*time.Minute)
+		assert.Nil(revoker.Revoke("a", time.Now().Add(2*time.Hour)))
+		assert.True(mr.TTL("revoked:a") > time.Hour)
+
+		mr.FastForward(3 * time.Hour)
+		assert.False(revoker.IsRevoked("a"))
+	})
+
+	t.Run("should store tokens without exp without TTL", func(t *testing.T) {
+		assert := assert.New(t)
+
+		revoker := revokeredis.New(client, "revoked:")
+		assert.Nil(revoker.Revoke("c", time.Time{}))
+		assert.True(revoker.IsRevoked("c"))
+		assert.Equal(time.Duration(0), mr.TTL("revoked:c"))
+
+		// a key without TTL is never given one
+		assert.Nil(revoker.Revoke("c", time.Now().Add(time.Hour)))
+		assert.Equal(time.Duration(0), mr.TTL("revoked:c"))
+		assert.Nil(revoker.Revoke("d", time.Now().Add(time.Hour)))
+		assert.Nil(revoker.Revoke("d", time.Time{}))
+		assert.Equal(time.Duration(0), mr.TTL("revoked:d"))
+
+		mr.FastForward(3 * time.Hour)
+		assert.True(revoker.IsRevoked("c"))
+		assert.True(revoker.IsRevoked("d"))
+	})
+
+	t.Run("should reject revoked tokens in Verify", func(t *testing.T) {
+		assert := assert.New(t)
+
+		revoker := revokeredis.New(client, "revoked:")
+		jwter := jwt.New([]byte("key"))
+		jwter.SetRevoker(revoker)
+		token, _ := jwter.Sign(josejwt.Claims{"jti": "abc"}, time.Hour)
+		claims, err := jwter.Verify(token)
+		assert.Nil(err)
+
+		exp, _ := claims.Expiration()
+		assert.Nil(revoker.Revoke("abc", exp))
+		_, err = jwter.Verify(token)
+		assert.Equal("token revoked", err.Error())
+	})
+
+	t.Run("should fail closed when redis is down", func(t *testing.T) {
+		assert := assert.New(t)
+
+		down, err := miniredis.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+		downClient := redis.NewClient(&redis.Options{Addr: down.Addr()})
+		defer downClient.Close()
+		down.Close()
+
+		revoker := revokeredis.New(downClient, "revoked:")
+		assert.True(revoker.IsRevoked("a"))
+		assert.NotNil(revoker.Revoke("a", time.Now().Add(time.Hour)))
+	})
+}
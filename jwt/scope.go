@@ -0,0 +1,50 @@
+package jwt
+
+import (
+	"strings"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+)
+
+// scopeClaims are the claims carrying scopes: "scope" is a space-delimited string per RFC 8693,
+// "scp" is an array of strings used by Azure AD and Okta. Both forms are accepted for both claims.
+var scopeClaims = []string{"scope", "scp"}
+
+// Scopes returns the scopes of claims regardless of issuer conventions, from space-delimited
+// "scope" strings and array-valued "scp" claims (either form is accepted for either claim),
+// duplicates are removed and the order is kept.
+//
+//  claims, _ := jwter.Verify(token)
+//  scopes := jwt.Scopes(claims) // ["read", "write"] for {"scope": "read write"} or {"scp": ["read", "write"]}
+//
+func Scopes(claims josejwt.Claims) []string {
+	var scopes []string
+	for _, name := range scopeClaims {
+		switch v := claims.Get(name).(type) {
+		case string:
+			scopes = append(scopes, strings.Fields(v)...)
+		case []string:
+			for _, s := range v {
+				scopes = append(scopes, strings.Fields(s)...)
+			}
+		case []interface{}:
+			for _, s := range v {
+				if str, ok := s.(string); ok {
+					scopes = append(scopes, strings.Fields(str)...)
+				}
+			}
+		}
+	}
+	return normalizeAudience(scopes)
+}
+
+// HasScopes reports whether claims have all the scopes, see Scopes.
+func HasScopes(claims josejwt.Claims, scopes ...string) bool {
+	granted := Scopes(claims)
+	for _, scope := range scopes {
+		if !containsString(granted, scope) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,31 @@
+package jwt
+
+import (
+	"testing"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScopes(t *testing.T) {
+	t.Run("should normalize scope and scp", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.Equal(0, len(Scopes(josejwt.Claims{})))
+		assert.Equal([]string{"read", "write"}, Scopes(josejwt.Claims{"scope": " read  write "}))
+		assert.Equal([]string{"read", "write"}, Scopes(josejwt.Claims{"scp": []string{"read", "write"}}))
+		assert.Equal([]string{"read", "write"}, Scopes(josejwt.Claims{"scp": "read write"}))
+		assert.Equal([]string{"read", "write", "admin"}, Scopes(josejwt.Claims{
+			"scope": []interface{}{"read", 1, "write"},
+			"scp":   []interface{}{"write", "admin"},
+		}))
+
+		jwter := New([]byte("key1"))
+		token, _ := jwter.Sign(josejwt.Claims{"scp": []string{"read", "write"}})
+		claims, _ := jwter.Verify(token)
+		assert.True(HasScopes(claims, "read"))
+		assert.True(HasScopes(claims, "write", "read"))
+		assert.False(HasScopes(claims, "read", "admin"))
+		assert.True(HasScopes(claims))
+	})
+}
@@ -0,0 +1,142 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"golang.org/x/oauth2"
+)
+
+// ServiceTokenClient caches a self-signed service token for outbound calls between services,
+// and renews it shortly before expiry. Concurrent renewals are coalesced into one signing call.
+type ServiceTokenClient struct {
+	signer      Signer
+	claims      josejwt.Claims
+	expiresIn   time.Duration
+	renewBefore time.Duration
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+	renewAt   time.Time
+	inflight  *tokenCall
+}
+
+type tokenCall struct {
+	done      chan struct{}
+	token     string
+	expiresAt time.Time
+	err       error
+}
+
+// NewServiceTokenClient returns a ServiceTokenClient, tokens are signed by signer with the claims and expiresIn.
+// Tokens are renewed 10% of expiresIn before expiry by default, see SetRenewBefore.
+//
+//  client := jwt.NewServiceTokenClient(jwter, map[string]interface{}{"sub": "billing"}, time.Hour)
+//  token, err := client.Token(ctx)
+//  req.Header.Set("Authorization", "Bearer "+token)
+//
+func NewServiceTokenClient(signer Signer, claims map[string]interface{}, expiresIn time.Duration) *ServiceTokenClient {
+	if signer == nil {
+		panic(errors.New("invalid token signer"))
+	}
+	if expiresIn <= 0 {
+		panic(errors.New("invalid service token expiresIn"))
+	}
+	return &ServiceTokenClient{
+		signer:      signer,
+		claims:      josejwt.Claims(claims),
+		expiresIn:   expiresIn,
+		renewBefore: expiresIn / 10,
+	}
+}
+
+// SetRenewBefore set how long before expiry the token is renewed.
+func (c *ServiceTokenClient) SetRenewBefore(renewBefore time.Duration) *ServiceTokenClient {
+	if renewBefore < 0 || renewBefore >= c.expiresIn {
+		panic(errors.New("invalid service token renewBefore"))
+	}
+	c.mu.Lock()
+	c.renewBefore = renewBefore
+	c.renewAt = c.expiresAt.Add(-renewBefore)
+	c.mu.Unlock()
+	return c
+}
+
+// Token returns the cached token, or a renewed one if it is about to expire. While renewing, the current
+// token is returned if it is still valid, otherwise it waits for the renewal or ctx to be done.
+func (c *ServiceTokenClient) Token(ctx context.Context) (string, error) {
+	token, _, err := c.current(ctx)
+	return token, err
+}
+
+// TokenSource returns an oauth2.TokenSource backed by the client, so it plugs into HTTP clients
+// and SDKs that accept a TokenSource. ctx is used for waiting renewals.
+//
+//  httpClient := oauth2.NewClient(ctx, client.TokenSource(ctx))
+//
+func (c *ServiceTokenClient) TokenSource(ctx context.Context) oauth2.TokenSource {
+	return serviceTokenSource{c, ctx}
+}
+
+type serviceTokenSource struct {
+	c   *ServiceTokenClient
+	ctx context.Context
+}
+
+func (s serviceTokenSource) Token() (*oauth2.Token, error) {
+	token, expiresAt, err := s.c.current(s.ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &oauth2.Token{AccessToken: token, TokenType: "Bearer", Expiry: expiresAt}, nil
+}
+
+func (c *ServiceTokenClient) current(ctx context.Context) (string, time.Time, error) {
+	now := time.Now()
+	c.mu.Lock()
+	token, expiresAt := c.token, c.expiresAt
+	if token != "" && now.Before(c.renewAt) {
+		c.mu.Unlock()
+		return token, expiresAt, nil
+	}
+	call := c.inflight
+	if call == nil {
+		call = &tokenCall{done: make(chan struct{})}
+		c.inflight = call
+		go c.renew(call)
+	}
+	c.mu.Unlock()
+	if token != "" && now.Before(expiresAt) {
+		return token, expiresAt, nil
+	}
+
+	select {
+	case <-call.done:
+		return call.token, call.expiresAt, call.err
+	case <-ctx.Done():
+		return "", time.Time{}, ctx.Err()
+	}
+}
+
+func (c *ServiceTokenClient) renew(call *tokenCall) {
+	claims := make(josejwt.Claims, len(c.claims))
+	for key, val := range c.claims {
+		claims[key] = val
+	}
+	call.expiresAt = time.Now().Add(c.expiresIn)
+	call.token, call.err = c.signer.Sign(claims, c.expiresIn)
+
+	c.mu.Lock()
+	if call.err == nil {
+		c.token = call.token
+		c.expiresAt = call.expiresAt
+		c.renewAt = c.expiresAt.Add(-c.renewBefore)
+	}
+	c.inflight = nil
+	c.mu.Unlock()
+	close(call.done)
+}
@@ -0,0 +1,137 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+// countingSigner counts Sign calls, and fails when failed is set.
+type countingSigner struct {
+	*JWT
+	calls  int32
+	failed int32
+	delay  time.Duration
+}
+
+func (s *countingSigner) Sign(content interface{}, expiresIn ...time.Duration) (string, error) {
+	atomic.AddInt32(&s.calls, 1)
+	time.Sleep(s.delay)
+	if atomic.LoadInt32(&s.failed) == 1 {
+		return "", errors.New("signer failed")
+	}
+	return s.JWT.Sign(content, expiresIn...)
+}
+
+func TestServiceTokenClient(t *testing.T) {
+	t.Run("should cache and renew token", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.Panics(func() {
+			NewServiceTokenClient(nil, nil, time.Hour)
+		})
+		assert.Panics(func() {
+			NewServiceTokenClient(New([]byte("key1")), nil, 0)
+		})
+
+		signer := &countingSigner{JWT: New([]byte("key1")), delay: 10 * time.Millisecond}
+		client := NewServiceTokenClient(signer, map[string]interface{}{"sub": "billing"}, 2*time.Second)
+		assert.Panics(func() {
+			client.SetRenewBefore(2 * time.Second)
+		})
+
+		var wg sync.WaitGroup
+		tokens := make([]string, 10)
+		for i := range tokens {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				tokens[i], _ = client.Token(context.Background())
+			}(i)
+		}
+		wg.Wait()
+		assert.Equal(int32(1), atomic.LoadInt32(&signer.calls))
+		for _, token := range tokens {
+			assert.Equal(tokens[0], token)
+		}
+		claims, err := signer.Verify(tokens[0])
+		assert.Nil(err)
+		assert.Equal("billing", claims.Get("sub"))
+
+		// renew in background and return the current token
+		client.SetRenewBefore(1990 * time.Millisecond)
+		time.Sleep(20 * time.Millisecond)
+		token, err := client.Token(context.Background())
+		assert.Nil(err)
+		assert.Equal(tokens[0], token)
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(int32(2), atomic.LoadInt32(&signer.calls))
+	})
+
+	t.Run("should return error or ctx error", func(t *testing.T) {
+		assert := assert.New(t)
+
+		signer := &countingSigner{JWT: New([]byte("key1")), failed: 1, delay: 20 * time.Millisecond}
+		client := NewServiceTokenClient(signer, map[string]interface{}{"sub": "billing"}, time.Hour)
+
+		_, err := client.Token(context.Background())
+		assert.Equal("signer failed", err.Error())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+		defer cancel()
+		_, err = client.Token(ctx)
+		assert.Equal(context.DeadlineExceeded, err)
+
+		atomic.StoreInt32(&signer.failed, 0)
+		time.Sleep(30 * time.Millisecond)
+		token, err := client.Token(context.Background())
+		assert.Nil(err)
+		assert.NotEqual("", token)
+	})
+
+	t.Run("should work as oauth2.TokenSource", func(t *testing.T) {
+		assert := assert.New(t)
+
+		signer := &countingSigner{JWT: New([]byte("key1"))}
+		client := NewServiceTokenClient(signer, map[string]interface{}{"sub": "billing"}, time.Hour)
+		var source oauth2.TokenSource = client.TokenSource(context.Background())
+
+		token, err := source.Token()
+		assert.Nil(err)
+		assert.True(token.Valid())
+		assert.Equal("Bearer", token.Type())
+		assert.True(token.Expiry.After(time.Now().Add(59 * time.Minute)))
+		raw, _ := client.Token(context.Background())
+		assert.Equal(raw, token.AccessToken)
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, err := signer.Verify(r.Header.Get("Authorization")[7:])
+			if err != nil {
+				w.WriteHeader(401)
+				return
+			}
+			w.Write([]byte(claims.Get("sub").(string)))
+		}))
+		defer ts.Close()
+
+		res, err := oauth2.NewClient(context.Background(), source).Get(ts.URL)
+		assert.Nil(err)
+		assert.Equal(200, res.StatusCode)
+		res.Body.Close()
+		assert.Equal(int32(1), atomic.LoadInt32(&signer.calls))
+
+		atomic.StoreInt32(&signer.failed, 1)
+		client = NewServiceTokenClient(signer, nil, time.Hour)
+		token, err = client.TokenSource(context.Background()).Token()
+		assert.Nil(token)
+		assert.Equal("signer failed", err.Error())
+	})
+}
@@ -0,0 +1,37 @@
+package jwt
+
+import (
+	"bytes"
+	"encoding/json"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+)
+
+// SetSortedClaims makes Sign serialize claims with sorted keys at every level, so identical claims
+// always produce byte-identical payloads, which is needed for content-addressed caching of tokens
+// and for golden-file tests. Top-level keys and nested maps are always sorted by encoding/json,
+// but structs nested in claims are serialized in field order, they are converted to sorted objects
+// when enabled. Numbers are kept as is.
+//
+//  jwter.SetSortedClaims(true)
+//  token, err := jwter.SignAt(at, map[string]interface{}{"user": &User{Name: "x", ID: "y"}})
+//  // payload: {"iat":1514764800,"user":{"ID":"y","Name":"x"}}
+//
+func (j *JWT) SetSortedClaims(sorted bool) {
+	j.sortedClaims = sorted
+}
+
+// sortClaims returns a copy of claims with all nested values converted to plain JSON values.
+func sortClaims(claims josejwt.Claims) (josejwt.Claims, error) {
+	buf, err := json.Marshal(map[string]interface{}(claims))
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(buf))
+	decoder.UseNumber()
+	sorted := josejwt.Claims{}
+	if err = decoder.Decode((*map[string]interface{})(&sorted)); err != nil {
+		return nil, err
+	}
+	return sorted, nil
+}
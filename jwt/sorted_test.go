@@ -0,0 +1,63 @@
+package jwt
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SermoDigital/jose"
+	"github.com/stretchr/testify/assert"
+)
+
+type sortedUser struct {
+	Name  string  `json:"name"`
+	ID    string  `json:"id"`
+	Score float64 `json:"score"`
+}
+
+func TestSortedClaims(t *testing.T) {
+	payloadOf := func(token string) string {
+		buf, err := jose.Base64Decode([]byte(strings.Split(token, ".")[1]))
+		if err != nil {
+			panic(err)
+		}
+		return string(buf)
+	}
+	at := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("should serialize nested structs in field order by default", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key"))
+		jwter.SetJTIGenerator(nil)
+		token, err := jwter.SignAt(at, map[string]interface{}{"user": &sortedUser{"x", "y", 1.5}})
+		assert.Nil(err)
+		assert.Equal(`{"iat":1514764800,"user":{"name":"x","id":"y","score":1.5}}`, payloadOf(token))
+	})
+
+	t.Run("should serialize claims with sorted keys", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key"))
+		jwter.SetJTIGenerator(nil)
+		jwter.SetSortedClaims(true)
+		token1, err := jwter.SignAt(at, map[string]interface{}{
+			"user": &sortedUser{"x", "y", 1.5},
+			"big":  int64(9007199254740993),
+		}, time.Hour)
+		assert.Nil(err)
+		assert.Equal(`{"big":9007199254740993,"exp":1514768400,"iat":1514764800,"user":{"id":"y","name":"x","score":1.5}}`,
+			payloadOf(token1))
+
+		token2, err := jwter.SignAt(at, map[string]interface{}{
+			"big":  int64(9007199254740993),
+			"user": map[string]interface{}{"score": 1.5, "id": "y", "name": "x"},
+		}, time.Hour)
+		assert.Nil(err)
+		assert.Equal(token1, token2)
+
+		claims, err := jwter.Decode(token1)
+		assert.Nil(err)
+		assert.Equal("x", claims.Get("user").(map[string]interface{})["name"])
+	})
+}
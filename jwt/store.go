@@ -0,0 +1,156 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"sync"
+	"time"
+
+	josecrypto "github.com/SermoDigital/jose/crypto"
+	josejwt "github.com/SermoDigital/jose/jwt"
+)
+
+// ErrReferenceNotFound is returned by Store when the reference token doesn't exist or has expired.
+var ErrReferenceNotFound = errors.New("reference token not found")
+
+// Store is a server-side claims store for opaque reference tokens.
+// When a Store is set to a JWT instance by SetStore, Sign returns a random reference
+// instead of a signed token, and Verify resolves the reference from the Store.
+type Store interface {
+	// Save saves claims with the reference, expiresIn <= 0 means the claims never expires.
+	Save(ref string, claims josejwt.Claims, expiresIn time.Duration) error
+	// Load returns the claims of the reference, or ErrReferenceNotFound.
+	Load(ref string) (josejwt.Claims, error)
+	// Delete deletes the reference, it is used for instant revocation.
+	Delete(ref string) error
+}
+
+// MemoryStore is a in-memory Store implementation, it is suitable for single process deployments.
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	lastSweep time.Time
+}
+
+type memoryEntry struct {
+	claims    josejwt.Claims
+	expiresAt time.Time
+}
+
+// NewMemoryStore returns a MemoryStore instance.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]memoryEntry), lastSweep: time.Now()}
+}
+
+// Save implements the Store interface. The claims are copied, so changes of the caller's claims
+// don't affect the saved ones.
+func (s *MemoryStore) Save(ref string, claims josejwt.Claims, expiresIn time.Duration) error {
+	entry := memoryEntry{claims: deepCopyClaims(claims)}
+	now := time.Now()
+	if expiresIn > 0 {
+		entry.expiresAt = now.Add(expiresIn)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[ref] = entry
+	if now.Sub(s.lastSweep) > time.Minute {
+		s.lastSweep = now
+		for key, e := range s.entries {
+			if e.expired(now) {
+				delete(s.entries, key)
+			}
+		}
+	}
+	return nil
+}
+
+// Load implements the Store interface. It returns a copy of the saved claims, so verifications of
+// the same reference token don't share the claims.
+func (s *MemoryStore) Load(ref string) (josejwt.Claims, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[ref]
+	if !ok {
+		return nil, ErrReferenceNotFound
+	}
+	if entry.expired(time.Now()) {
+		delete(s.entries, ref)
+		return nil, ErrReferenceNotFound
+	}
+	return deepCopyClaims(entry.claims), nil
+}
+
+// Delete implements the Store interface.
+func (s *MemoryStore) Delete(ref string) error {
+	s.mu.Lock()
+	delete(s.entries, ref)
+	s.mu.Unlock()
+	return nil
+}
+
+// deepCopyClaims copies the claims with nested maps and slices.
+func deepCopyClaims(claims josejwt.Claims) josejwt.Claims {
+	if claims == nil {
+		return nil
+	}
+	return josejwt.Claims(deepCopyValue(map[string]interface{}(claims)).(map[string]interface{}))
+}
+
+func deepCopyValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(val))
+		for k, v := range val {
+			res[k] = deepCopyValue(v)
+		}
+		return res
+	case josejwt.Claims:
+		return deepCopyClaims(val)
+	case []interface{}:
+		res := make([]interface{}, len(val))
+		for i, v := range val {
+			res[i] = deepCopyValue(v)
+		}
+		return res
+	case []string:
+		return append([]string(nil), val...)
+	}
+	return v
+}
+
+func (e memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && now.After(e.expiresAt)
+}
+
+// newReference returns a random, URL safe reference token.
+func newReference() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// claimsJWT wraps claims loaded from Store as a josejwt.JWT, so that josejwt.Validator can validate it.
+type claimsJWT josejwt.Claims
+
+func (c claimsJWT) Claims() josejwt.Claims {
+	return josejwt.Claims(c)
+}
+
+func (c claimsJWT) Validate(_ interface{}, _ josecrypto.SigningMethod, v ...*josejwt.Validator) error {
+	var v1 josejwt.Validator
+	if len(v) > 0 {
+		v1 = *v[0]
+	}
+	if err := v1.Validate(c); err != nil {
+		return err
+	}
+	return josejwt.Claims(c).Validate(time.Now(), v1.EXP, v1.NBF)
+}
+
+func (c claimsJWT) Serialize(_ interface{}) ([]byte, error) {
+	return nil, errors.New("reference claims can't be serialized")
+}
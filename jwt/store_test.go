@@ -0,0 +1,116 @@
+package jwt
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore(t *testing.T) {
+	t.Run("MemoryStore", func(t *testing.T) {
+		assert := assert.New(t)
+
+		store := NewMemoryStore()
+		assert.Nil(store.Save("a", josejwt.Claims{"test": "OK"}, 0))
+		assert.Nil(store.Save("b", josejwt.Claims{"test": "OK"}, time.Millisecond))
+
+		claims, err := store.Load("a")
+		assert.Nil(err)
+		assert.Equal("OK", claims.Get("test"))
+
+		time.Sleep(5 * time.Millisecond)
+		_, err = store.Load("b")
+		assert.Equal(ErrReferenceNotFound, err)
+
+		assert.Nil(store.Delete("a"))
+		_, err = store.Load("a")
+		assert.Equal(ErrReferenceNotFound, err)
+	})
+
+	t.Run("reference token mode", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"))
+		assert.Panics(func() {
+			jwter.SetStore(nil)
+		})
+		assert.NotNil(jwter.Revoke("xxx"))
+
+		jwter.SetStore(NewMemoryStore())
+		jwter.SetIssuer("Gear")
+		token, err := jwter.Sign(josejwt.Claims{"test": "OK"})
+		assert.Nil(err)
+		assert.False(strings.Contains(token, "."))
+
+		claims, err := jwter.Verify(token)
+		assert.Nil(err)
+		assert.Equal("OK", claims.Get("test"))
+		assert.Equal("Gear", claims.Get("iss"))
+		assert.True(claims.Has("iat"))
+
+		claims, err = jwter.Decode(token)
+		assert.Nil(err)
+		assert.Equal("OK", claims.Get("test"))
+
+		assert.Nil(jwter.Revoke(token))
+		_, err = jwter.Verify(token)
+		assert.NotNil(err)
+
+		validator := &josejwt.Validator{}
+		validator.SetSubject("test")
+		jwter.SetValidator(validator)
+		token, _ = jwter.Sign(josejwt.Claims{"test": "OK"})
+		_, err = jwter.Verify(token)
+		assert.NotNil(err)
+		token, _ = jwter.Sign(josejwt.Claims{"test": "OK", "sub": "test"})
+		_, err = jwter.Verify(token)
+		assert.Nil(err)
+	})
+
+	t.Run("should not share claims of reference tokens", func(t *testing.T) {
+		assert := assert.New(t)
+
+		store := NewMemoryStore()
+		claims := josejwt.Claims{"sub": "alice", "scope": []interface{}{"read"}, "ext": map[string]interface{}{"a": 1}}
+		assert.Nil(store.Save("a", claims, 0))
+		claims.Set("sub", "bob")
+		claims.Get("scope").([]interface{})[0] = "write"
+		claims.Get("ext").(map[string]interface{})["a"] = 2
+
+		loaded, _ := store.Load("a")
+		assert.Equal("alice", loaded.Get("sub"))
+		assert.Equal([]interface{}{"read"}, loaded.Get("scope"))
+		assert.Equal(map[string]interface{}{"a": 1}, loaded.Get("ext"))
+		loaded.Set("sub", "bob")
+		loaded, _ = store.Load("a")
+		assert.Equal("alice", loaded.Get("sub"))
+
+		jwter := New([]byte("key1"))
+		jwter.SetStore(store)
+		jwter.SetCompression(1)
+		jwter.SetClaimsEncryption([]byte("a 32 bytes long encryption key!!"), "email")
+		token, err := jwter.Sign(josejwt.Claims{"sub": "alice", "email": "alice@example.com", "aud": []string{"api", "web"}})
+		assert.Nil(err)
+		for i := 0; i < 2; i++ {
+			claims, err := jwter.Verify(token)
+			assert.Nil(err)
+			assert.Equal("alice@example.com", claims.Get("email"))
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				claims, err := jwter.Verify(token)
+				assert.Nil(err)
+				assert.Equal("alice@example.com", claims.Get("email"))
+			}()
+		}
+		wg.Wait()
+	})
+}
@@ -0,0 +1,57 @@
+package jwt
+
+import (
+	"errors"
+	"net/mail"
+	"regexp"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+)
+
+var errMalformedSubject = errors.New(`claim "sub" is malformed`)
+
+// SubjectFormat reports whether claim "sub" is well-formed, see SetSubjectFormat.
+type SubjectFormat func(sub string) bool
+
+var (
+	uuidRegexp    = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	numericRegexp = regexp.MustCompile(`^[1-9][0-9]{0,18}$`)
+)
+
+// Built-in SubjectFormats.
+var (
+	// SubjectUUID accepts UUIDs in the canonical 8-4-4-4-12 form.
+	SubjectUUID SubjectFormat = uuidRegexp.MatchString
+	// SubjectNumeric accepts positive decimal IDs that fit in int64, without leading zeros.
+	SubjectNumeric SubjectFormat = numericRegexp.MatchString
+	// SubjectEmail accepts bare email addresses, such as "alice@example.com".
+	SubjectEmail SubjectFormat = func(sub string) bool {
+		addr, err := mail.ParseAddress(sub)
+		return err == nil && addr.Address == sub && addr.Name == ""
+	}
+)
+
+// SetSubjectFormat requires claim "sub" matching one of the formats at verification, so malformed
+// subjects are rejected before they reach database lookups. Set no formats to disable it.
+//
+//  jwter.SetSubjectFormat(jwt.SubjectUUID, jwt.SubjectEmail)
+//
+func (j *JWT) SetSubjectFormat(formats ...SubjectFormat) {
+	for _, format := range formats {
+		if format == nil {
+			panic(errors.New("invalid subject format"))
+		}
+	}
+	j.subjectFormats = formats
+}
+
+// checkSubject rejects tokens whose "sub" doesn't match the subject formats.
+func (j *JWT) checkSubject(claims josejwt.Claims) error {
+	sub, _ := claims.Subject()
+	for _, format := range j.subjectFormats {
+		if sub != "" && format(sub) {
+			return nil
+		}
+	}
+	return errMalformedSubject
+}
@@ -0,0 +1,55 @@
+package jwt
+
+import (
+	"testing"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubjectFormat(t *testing.T) {
+	t.Run("built-in formats", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.True(SubjectUUID("3f2504e0-4f89-11d3-9a0c-0305e82c3301"))
+		assert.False(SubjectUUID("3f2504e0-4f89-11d3-9a0c-0305e82c330"))
+		assert.False(SubjectUUID("3f2504e0-4f89-11d3-9a0c-0305e82c3301' OR 1=1"))
+
+		assert.True(SubjectNumeric("12345"))
+		assert.False(SubjectNumeric("012345"))
+		assert.False(SubjectNumeric("-1"))
+		assert.False(SubjectNumeric("99999999999999999999"))
+
+		assert.True(SubjectEmail("alice@example.com"))
+		assert.False(SubjectEmail("Alice <alice@example.com>"))
+		assert.False(SubjectEmail("alice"))
+	})
+
+	t.Run("should reject malformed subjects", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"))
+		assert.Panics(func() {
+			jwter.SetSubjectFormat(nil)
+		})
+		jwter.SetSubjectFormat(SubjectUUID, SubjectEmail)
+
+		token, _ := jwter.Sign(josejwt.Claims{"sub": "3f2504e0-4f89-11d3-9a0c-0305e82c3301"})
+		_, err := jwter.Verify(token)
+		assert.Nil(err)
+		token, _ = jwter.Sign(josejwt.Claims{"sub": "alice@example.com"})
+		_, err = jwter.Verify(token)
+		assert.Nil(err)
+
+		token, _ = jwter.Sign(josejwt.Claims{"sub": "12345"})
+		_, err = jwter.Verify(token)
+		assert.Contains(err.Error(), errMalformedSubject.Error())
+		token, _ = jwter.Sign(josejwt.Claims{"test": "OK"})
+		_, err = jwter.Verify(token)
+		assert.Contains(err.Error(), errMalformedSubject.Error())
+
+		jwter.SetSubjectFormat()
+		_, err = jwter.Verify(token)
+		assert.Nil(err)
+	})
+}
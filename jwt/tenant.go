@@ -0,0 +1,58 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+)
+
+// TenantExtractor returns the tenant identifier of verified claims, or "" if there is no tenant.
+type TenantExtractor func(claims josejwt.Claims) string
+
+// TenantClaim returns a TenantExtractor that reads the tenant from the string claim name, such as "tid".
+func TenantClaim(name string) TenantExtractor {
+	if name == "" {
+		panic(errors.New("invalid tenant claim"))
+	}
+	return func(claims josejwt.Claims) string {
+		tenant, _ := claims.Get(name).(string)
+		return tenant
+	}
+}
+
+// TenantFromIssuer returns a TenantExtractor that reads the tenant from the last path segment of
+// the "iss" claim, such as "acme" from "https://login.example.com/acme/".
+func TenantFromIssuer() TenantExtractor {
+	return func(claims josejwt.Claims) string {
+		iss, _ := claims.Issuer()
+		u, err := url.Parse(iss)
+		if err != nil {
+			return ""
+		}
+		segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+		return segments[len(segments)-1]
+	}
+}
+
+// tenantKey is the context key of the tenant identifier.
+type tenantKey struct{}
+
+// WithTenant returns a context carrying the tenant identifier, see TenantFromContext.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant identifier stored in the context by WithTenant,
+// or "" if there is no tenant. Data layers can use it to scope queries.
+//
+//  func (s *Store) Orders(ctx context.Context) ([]*Order, error) {
+//  	return s.query(ctx, "SELECT * FROM orders WHERE tenant = ?", jwt.TenantFromContext(ctx))
+//  }
+//
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
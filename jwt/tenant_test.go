@@ -0,0 +1,42 @@
+package jwt
+
+import (
+	"context"
+	"testing"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenant(t *testing.T) {
+	t.Run("TenantClaim", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.Panics(func() {
+			TenantClaim("")
+		})
+		tenant := TenantClaim("tid")
+		assert.Equal("acme", tenant(josejwt.Claims{"tid": "acme"}))
+		assert.Equal("", tenant(josejwt.Claims{"tid": 123}))
+		assert.Equal("", tenant(josejwt.Claims{}))
+	})
+
+	t.Run("TenantFromIssuer", func(t *testing.T) {
+		assert := assert.New(t)
+
+		tenant := TenantFromIssuer()
+		assert.Equal("acme", tenant(josejwt.Claims{"iss": "https://login.example.com/acme/"}))
+		assert.Equal("acme", tenant(josejwt.Claims{"iss": "https://login.example.com/tenants/acme"}))
+		assert.Equal("", tenant(josejwt.Claims{"iss": "https://login.example.com"}))
+		assert.Equal("", tenant(josejwt.Claims{"iss": "%zz"}))
+		assert.Equal("", tenant(josejwt.Claims{}))
+	})
+
+	t.Run("TenantFromContext", func(t *testing.T) {
+		assert := assert.New(t)
+
+		ctx := context.Background()
+		assert.Equal("", TenantFromContext(ctx))
+		assert.Equal("acme", TenantFromContext(WithTenant(ctx, "acme")))
+	})
+}
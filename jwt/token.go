@@ -0,0 +1,52 @@
+package jwt
+
+import (
+	"net/http"
+
+	"github.com/SermoDigital/jose"
+	josejwt "github.com/SermoDigital/jose/jwt"
+)
+
+// Token is a verified token returned by VerifyToken, it holds the raw token, the parsed header and claims,
+// and the verification metadata, so downstream code can forward the token or log the kid without
+// re-extracting and re-parsing.
+type Token struct {
+	// Raw is the token string as verified.
+	Raw string
+	// Header is the protected header, it is nil for reference tokens.
+	// For nested tokens, it is the innermost header.
+	Header jose.Protected
+	// Claims is the verified claims.
+	Claims josejwt.Claims
+	// KeyID is the "kid" header of the token, if any.
+	KeyID string
+	// KeyIndex is the index of the key (in rotation) that verified the token, -1 for reference tokens.
+	KeyIndex int
+	// Backup reports whether the token was verified by the backup signing keys.
+	Backup bool
+
+	parsed josejwt.JWT
+}
+
+// Parsed returns the parsed and validated token object, it can be serialized again or validated with other keys
+// without parsing the raw token twice. It is nil for reference tokens and tokens from custom verifiers.
+func (t *Token) Parsed() josejwt.JWT {
+	return t.parsed
+}
+
+// SetAuthHeader attaches the raw token to an outbound request as a bearer token.
+//
+//  req, _ := http.NewRequest("GET", upstreamURL, nil)
+//  t.SetAuthHeader(req)
+//
+func (t *Token) SetAuthHeader(req *http.Request) {
+	req.Header.Set("Authorization", "Bearer "+t.Raw)
+}
+
+// TokenVerifier is a Verifier that returns the verified Token, *JWT implements it.
+type TokenVerifier interface {
+	Verifier
+	VerifyToken(token string) (*Token, error)
+}
+
+var _ TokenVerifier = (*JWT)(nil)
@@ -0,0 +1,71 @@
+package jwt
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	josecrypto "github.com/SermoDigital/jose/crypto"
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyToken(t *testing.T) {
+	t.Run("should return verification metadata", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"), []byte("key2"))
+		token, _ := New([]byte("key2")).Sign(josejwt.Claims{"sub": "alice"})
+		tk, err := jwter.VerifyToken(token)
+		assert.Nil(err)
+		assert.Equal(token, tk.Raw)
+		assert.Equal("alice", tk.Claims.Get("sub"))
+		assert.Equal("HS256", tk.Header.Get("alg"))
+		assert.Equal("", tk.KeyID)
+		assert.Equal(1, tk.KeyIndex)
+		assert.False(tk.Backup)
+		buf, err := tk.Parsed().Serialize([]byte("key2"))
+		assert.Nil(err)
+		assert.Equal(token, string(buf))
+		assert.Nil(tk.Parsed().Validate([]byte("key2"), josecrypto.SigningMethodHS256))
+
+		req := httptest.NewRequest("GET", "/", nil)
+		tk.SetAuthHeader(req)
+		assert.Equal("Bearer "+token, req.Header.Get("Authorization"))
+
+		jwter.SetBackupSigning(josecrypto.SigningMethodHS384, []byte("old key"))
+		token = signRawWithHeader([]byte("key1"), `{"alg":"HS256","kid":"k1"}`, `{"sub":"bob"}`)
+		tk, err = jwter.VerifyToken(token)
+		assert.Nil(err)
+		assert.Equal("k1", tk.KeyID)
+		assert.Equal(0, tk.KeyIndex)
+
+		old := New()
+		old.SetSigning(josecrypto.SigningMethodHS384, []byte("old key"))
+		token, _ = old.Sign(josejwt.Claims{"sub": "alice"})
+		tk, err = jwter.VerifyToken(token)
+		assert.Nil(err)
+		assert.Equal(0, tk.KeyIndex)
+		assert.True(tk.Backup)
+
+		_, err = jwter.VerifyToken(token[1:])
+		assert.NotNil(err)
+		_, err = verifyWithKeys(context.Background(), nil, josecrypto.SigningMethodHS256, nil)
+		assert.Equal("no keys to verify", err.Error())
+	})
+
+	t.Run("should return reference token", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"))
+		jwter.SetStore(NewMemoryStore())
+		token, _ := jwter.Sign(josejwt.Claims{"sub": "alice"})
+		tk, err := jwter.VerifyToken(token)
+		assert.Nil(err)
+		assert.Equal(token, tk.Raw)
+		assert.Nil(tk.Header)
+		assert.Nil(tk.Parsed())
+		assert.Equal(-1, tk.KeyIndex)
+		assert.Equal("alice", tk.Claims.Get("sub"))
+	})
+}
@@ -0,0 +1,36 @@
+package jwt
+
+import (
+	"errors"
+	"strings"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+)
+
+// VerifiedEmailValidator returns a validator preset that requires claim "email_verified" to be true.
+// If domains are given, claim "email" is also required and its domain must be one of them.
+// The returned validator can be customized further before being set to jwt:
+//
+//  validator := jwt.VerifiedEmailValidator("example.com")
+//  validator.SetIssuer("https://accounts.example.com")
+//  jwter.SetValidator(validator)
+//
+func VerifiedEmailValidator(domains ...string) *josejwt.Validator {
+	return &josejwt.Validator{Fn: func(claims josejwt.Claims) error {
+		if verified, _ := claims.Get("email_verified").(bool); !verified {
+			return errors.New(`claim "email_verified" is not true`)
+		}
+		if len(domains) == 0 {
+			return nil
+		}
+		email, _ := claims.Get("email").(string)
+		if i := strings.LastIndexByte(email, '@'); i > 0 {
+			for _, domain := range domains {
+				if strings.EqualFold(email[i+1:], domain) {
+					return nil
+				}
+			}
+		}
+		return errors.New(`claim "email" is not in allowed domains`)
+	}}
+}
@@ -0,0 +1,40 @@
+package jwt
+
+import (
+	"testing"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidator(t *testing.T) {
+	t.Run("VerifiedEmailValidator", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"))
+		jwter.SetValidator(VerifiedEmailValidator())
+
+		token, _ := jwter.Sign(josejwt.Claims{"email": "a@gmail.com"})
+		_, err := jwter.Verify(token)
+		assert.NotNil(err)
+		token, _ = jwter.Sign(josejwt.Claims{"email": "a@gmail.com", "email_verified": "true"})
+		_, err = jwter.Verify(token)
+		assert.NotNil(err)
+		token, _ = jwter.Sign(josejwt.Claims{"email": "a@gmail.com", "email_verified": true})
+		_, err = jwter.Verify(token)
+		assert.Nil(err)
+
+		validator := VerifiedEmailValidator("example.com")
+		validator.SetIssuer("Gear")
+		jwter.SetValidator(validator)
+		jwter.SetIssuer("Gear")
+		_, err = jwter.Verify(token)
+		assert.NotNil(err)
+		token, _ = jwter.Sign(josejwt.Claims{"email": "a@Example.com", "email_verified": true})
+		_, err = jwter.Verify(token)
+		assert.Nil(err)
+		token, _ = jwter.Sign(josejwt.Claims{"email_verified": true})
+		_, err = jwter.Verify(token)
+		assert.NotNil(err)
+	})
+}
@@ -0,0 +1,57 @@
+package jwt
+
+import (
+	"errors"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+)
+
+var errTokenVersionTooOld = errors.New(`claim "ver" is below the minimum version`)
+
+// SetVersion set a token version to jwt, Sign will write it as claim "ver" (if not present in content).
+// Bump it together with SetMinVersion to force-invalidate entire generations of tokens
+// after a claims-schema or security change. Default to 0, no "ver" will be added.
+func (j *JWT) SetVersion(version int) {
+	if version < 0 {
+		panic(errors.New("invalid token version"))
+	}
+	j.version = version
+}
+
+// SetMinVersion set the minimum accepted token version, Verify will reject tokens whose claim "ver"
+// is lower than it, tokens without "ver" are treated as version 0.
+//
+//  jwter.SetVersion(3)
+//  jwter.SetMinVersion(2) // tokens of version 2 are still accepted during the rollout
+//
+func (j *JWT) SetMinVersion(version int) {
+	if version < 0 {
+		panic(errors.New("invalid token version"))
+	}
+	j.minVersion = version
+}
+
+// checkVersion rejects tokens whose claim "ver" is lower than the minimum version.
+func (j *JWT) checkVersion(claims josejwt.Claims) error {
+	if j.minVersion == 0 {
+		return nil
+	}
+	if ver, _ := numericClaim(claims, "ver"); ver < float64(j.minVersion) {
+		return errTokenVersionTooOld
+	}
+	return nil
+}
+
+// numericClaim returns the claim as float64, claims decoded from JSON are float64,
+// but claims from a Store may keep their Go types.
+func numericClaim(claims josejwt.Claims, name string) (float64, bool) {
+	switch v := claims.Get(name).(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
@@ -0,0 +1,69 @@
+package jwt
+
+import (
+	"testing"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersion(t *testing.T) {
+	t.Run("should write ver claim", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"))
+		assert.Panics(func() {
+			jwter.SetVersion(-1)
+		})
+		assert.Panics(func() {
+			jwter.SetMinVersion(-1)
+		})
+
+		token, _ := jwter.Sign(josejwt.Claims{"test": "OK"})
+		claims, _ := jwter.Decode(token)
+		assert.False(claims.Has("ver"))
+
+		jwter.SetVersion(2)
+		token, _ = jwter.Sign(josejwt.Claims{"test": "OK"})
+		claims, _ = jwter.Decode(token)
+		assert.Equal(float64(2), claims.Get("ver"))
+
+		token, _ = jwter.Sign(josejwt.Claims{"test": "OK", "ver": 1})
+		claims, _ = jwter.Decode(token)
+		assert.Equal(float64(1), claims.Get("ver"))
+	})
+
+	t.Run("should enforce minimum version", func(t *testing.T) {
+		assert := assert.New(t)
+
+		jwter := New([]byte("key1"))
+		legacy, _ := jwter.Sign(josejwt.Claims{"test": "OK"})
+		jwter.SetVersion(1)
+		v1, _ := jwter.Sign(josejwt.Claims{"test": "OK"})
+		jwter.SetVersion(2)
+		v2, _ := jwter.Sign(josejwt.Claims{"test": "OK"})
+
+		jwter.SetMinVersion(1)
+		_, err := jwter.Verify(legacy)
+		assert.Contains(err.Error(), errTokenVersionTooOld.Error())
+		_, err = jwter.Verify(v1)
+		assert.Nil(err)
+
+		jwter.SetMinVersion(2)
+		_, err = jwter.Verify(v1)
+		assert.Contains(err.Error(), errTokenVersionTooOld.Error())
+		_, err = jwter.Verify(v2)
+		assert.Nil(err)
+
+		store := New()
+		store.SetStore(NewMemoryStore())
+		store.SetVersion(2)
+		store.SetMinVersion(2)
+		ref, _ := store.Sign(josejwt.Claims{"test": "OK"})
+		_, err = store.Verify(ref)
+		assert.Nil(err)
+		store.SetMinVersion(3)
+		_, err = store.Verify(ref)
+		assert.Contains(err.Error(), errTokenVersionTooOld.Error())
+	})
+}
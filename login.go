@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/go-http-utils/cookie"
+	"github.com/teambition/gear"
+)
+
+// LoginResponse is the JSON body responded by LoginHandler, it follows the OAuth 2.0 token response.
+type LoginResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// SetRefreshCookie makes LoginHandler deliver the remember-me (refresh) token as a cookie with the name,
+// instead of in the response body. It should be used with SetRememberMe.
+// If options omit, the cookie will be set with Path "/", HttpOnly and Secure.
+func (a *Auth) SetRefreshCookie(name string, options ...*cookie.Options) *Auth {
+	if name == "" {
+		panic(errors.New("invalid refresh cookie name"))
+	}
+	a.refreshCookie = name
+	a.refreshCookieOpts = &cookie.Options{Path: "/", HTTPOnly: true, Secure: true}
+	if len(options) > 0 && options[0] != nil {
+		a.refreshCookieOpts = options[0]
+	}
+	return a
+}
+
+// LoginHandler returns a gear middleware for login endpoint. It runs the app's credential check by
+// authenticate, signs a token with the returned claims (bound to a fingerprint if SetFingerprint enabled),
+// and a remember-me token as refresh token if SetRememberMe enabled, then responds a LoginResponse.
+// Errors returned by authenticate will be responded as 401 unless they are *gear.Error.
+//
+//  app.Use(func(ctx *gear.Context) error {
+//  	if ctx.Path == "/login" && ctx.Method == "POST" {
+//  		return auther.LoginHandler(func(ctx *gear.Context) (josejwt.Claims, error) {
+//  			user, err := checkPassword(ctx)
+//  			if err != nil {
+//  				return nil, err
+//  			}
+//  			return josejwt.Claims{"sub": user.ID}, nil
+//  		})(ctx)
+//  	}
+//  	return nil
+//  })
+//
+func (a *Auth) LoginHandler(authenticate func(ctx *gear.Context) (josejwt.Claims, error)) gear.Middleware {
+	if authenticate == nil {
+		panic(errors.New("invalid authenticate function"))
+	}
+	return func(ctx *gear.Context) error {
+		claims, err := authenticate(ctx)
+		if err != nil {
+			return gear.ErrUnauthorized.From(err)
+		}
+
+		res := LoginResponse{TokenType: "Bearer"}
+		if a.fingerprint != "" {
+			res.AccessToken, err = a.SignWithFingerprint(ctx, claims)
+		} else {
+			res.AccessToken, err = a.j.Sign(claims)
+		}
+		if err != nil {
+			return gear.ErrInternalServerError.From(err)
+		}
+		// "iat" and "exp" are set to the signed token, claims are not modified by Sign.
+		access, err := a.j.Decode(res.AccessToken)
+		if err != nil {
+			return gear.ErrInternalServerError.From(err)
+		}
+		if exp, ok := access.Expiration(); ok {
+			iat, ok := access.IssuedAt()
+			if !ok {
+				iat = time.Now()
+			}
+			res.ExpiresIn = int64(exp.Sub(iat).Seconds())
+		}
+
+		if a.remember != nil {
+			refresh, err := a.remember.Sign(claims)
+			if err != nil {
+				return gear.ErrInternalServerError.From(err)
+			}
+			if a.refreshCookie != "" {
+				ctx.Cookies.Set(a.refreshCookie, refresh, a.refreshCookieOpts)
+			} else {
+				res.RefreshToken = refresh
+			}
+		}
+		ctx.SetHeader(gear.HeaderCacheControl, "no-store")
+		return ctx.JSON(200, res)
+	}
+}
+
+// copyClaims returns a shallow copy of claims.
+func copyClaims(claims josejwt.Claims) josejwt.Claims {
+	res := make(josejwt.Claims, len(claims))
+	for key, val := range claims {
+		res[key] = val
+	}
+	return res
+}
@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/teambition/gear"
+	authjwt "github.com/teambition/gear-auth/jwt"
+)
+
+func TestLoginHandler(t *testing.T) {
+	authenticate := func(ctx *gear.Context) (jwt.Claims, error) {
+		switch ctx.Query("user") {
+		case "alice":
+			return jwt.Claims{"sub": "alice"}, nil
+		case "bob":
+			return nil, gear.ErrForbidden.WithMsg("account is locked")
+		}
+		return nil, errors.New("invalid credentials")
+	}
+
+	t.Run("should sign token", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := New([]byte("my key"))
+		assert.Panics(func() {
+			a.LoginHandler(nil)
+		})
+		a.JWT().SetExpiresIn(time.Hour)
+		app := gear.New()
+		app.Use(a.LoginHandler(authenticate))
+		srv := app.Start()
+		defer srv.Close()
+
+		host := "http://" + srv.Addr().String()
+		res, err := NewRequst().Get(host + "?user=alice")
+		assert.Nil(err)
+		assert.Equal(200, res.StatusCode)
+		assert.Equal("no-store", res.Header.Get(gear.HeaderCacheControl))
+		var body LoginResponse
+		assert.Nil(json.NewDecoder(res.Body).Decode(&body))
+		res.Body.Close()
+		assert.Equal("Bearer", body.TokenType)
+		assert.Equal(int64(3600), body.ExpiresIn)
+		assert.Equal("", body.RefreshToken)
+		claims, err := a.JWT().Verify(body.AccessToken)
+		assert.Nil(err)
+		assert.Equal("alice", claims.Get("sub"))
+
+		res, err = NewRequst().Get(host + "?user=eve")
+		assert.Nil(err)
+		assert.Equal(401, res.StatusCode)
+		text, _ := res.Text()
+		assert.Equal(`{"error":"Unauthorized","message":"invalid credentials"}`, text)
+
+		res, err = NewRequst().Get(host + "?user=bob")
+		assert.Nil(err)
+		assert.Equal(403, res.StatusCode)
+		res.Body.Close()
+	})
+
+	t.Run("should sign refresh token", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := New([]byte("my key"))
+		remember := authjwt.New([]byte("remember key"))
+		remember.SetExpiresIn(time.Hour * 24)
+		a.SetRememberMe(remember, func(ctx *gear.Context) string {
+			return ""
+		}, func(ctx *gear.Context, token string) error {
+			return nil
+		})
+		app := gear.New()
+		app.Use(a.LoginHandler(authenticate))
+		srv := app.Start()
+		defer srv.Close()
+
+		host := "http://" + srv.Addr().String()
+		res, err := NewRequst().Get(host + "?user=alice")
+		assert.Nil(err)
+		var body LoginResponse
+		assert.Nil(json.NewDecoder(res.Body).Decode(&body))
+		res.Body.Close()
+		assert.Equal(int64(0), body.ExpiresIn)
+		claims, err := remember.Verify(body.RefreshToken)
+		assert.Nil(err)
+		assert.Equal("alice", claims.Get("sub"))
+		claims, _ = a.JWT().Verify(body.AccessToken)
+		assert.False(claims.Has("exp"))
+
+		assert.Panics(func() {
+			a.SetRefreshCookie("")
+		})
+		a.SetRefreshCookie("refresh_token")
+		res, err = NewRequst().Get(host + "?user=alice")
+		assert.Nil(err)
+		body = LoginResponse{}
+		assert.Nil(json.NewDecoder(res.Body).Decode(&body))
+		res.Body.Close()
+		assert.Equal("", body.RefreshToken)
+		cookies := res.Cookies()
+		assert.Equal(1, len(cookies))
+		assert.Equal("refresh_token", cookies[0].Name)
+		assert.True(cookies[0].HttpOnly)
+		_, err = remember.Verify(cookies[0].Value)
+		assert.Nil(err)
+	})
+}
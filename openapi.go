@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"errors"
+	"sort"
+)
+
+// SecurityScheme represents an OpenAPI 3 security scheme object.
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Description  string `json:"description,omitempty"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+}
+
+// defaultSecuritySchemes describes the default TokenExtractor.
+func defaultSecuritySchemes() map[string]*SecurityScheme {
+	return map[string]*SecurityScheme{
+		"bearerAuth":  {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+		"accessToken": {Type: "apiKey", In: "query", Name: "access_token"},
+	}
+}
+
+// SetSecurityScheme describes a way the token is extracted as an OpenAPI 3 security scheme, see OpenAPISecuritySchemes.
+// The default TokenExtractor is described as "bearerAuth" and "accessToken", custom extractors set by SetTokenParser
+// or SetRememberMe can't be inspected, so they should be described by this method. Set nil to remove a scheme.
+//
+//  auther.SetTokenParser(func(ctx *gear.Context) string {
+//  	return ctx.GetHeader("X-Api-Token")
+//  })
+//  auther.SetSecurityScheme("apiToken", &auth.SecurityScheme{Type: "apiKey", In: "header", Name: "X-Api-Token"})
+//
+func (a *Auth) SetSecurityScheme(name string, scheme *SecurityScheme) *Auth {
+	if name == "" {
+		panic(errors.New("invalid security scheme name"))
+	}
+	if scheme == nil {
+		delete(a.schemes, name)
+		return a
+	}
+	if a.schemes == nil {
+		a.schemes = make(map[string]*SecurityScheme)
+	}
+	a.schemes[name] = scheme
+	return a
+}
+
+// OpenAPISecuritySchemes returns the OpenAPI 3 "components.securitySchemes" of the configured extractors,
+// so generated API docs stay in sync with the middleware configuration.
+//
+//  doc.Components.SecuritySchemes = auther.OpenAPISecuritySchemes()
+//
+func (a *Auth) OpenAPISecuritySchemes() map[string]*SecurityScheme {
+	schemes := make(map[string]*SecurityScheme, len(a.schemes))
+	for name, scheme := range a.schemes {
+		s := *scheme
+		schemes[name] = &s
+	}
+	return schemes
+}
+
+// OpenAPISecurity returns the OpenAPI 3 security requirements of a route that requires the scopes,
+// such as the permissions required by RequirePermissions. Any of the security schemes is accepted.
+// It returns an empty list if no scheme is described, which means no security in OpenAPI.
+//
+//  router.Delete("/orders/:id", auther.RequirePermissions("permissions", "orders:delete"), deleteOrder)
+//  doc.Paths["/orders/{id}"].Delete.Security = auther.OpenAPISecurity("orders:delete")
+//
+func (a *Auth) OpenAPISecurity(scopes ...string) []map[string][]string {
+	names := make([]string, 0, len(a.schemes))
+	for name := range a.schemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if scopes == nil {
+		scopes = []string{}
+	}
+	requirements := make([]map[string][]string, 0, len(names))
+	for _, name := range names {
+		requirements = append(requirements, map[string][]string{name: scopes})
+	}
+	return requirements
+}
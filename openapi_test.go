@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/teambition/gear"
+)
+
+func TestOpenAPI(t *testing.T) {
+	t.Run("should export the default security schemes", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := New([]byte("my key"))
+		buf, err := json.Marshal(a.OpenAPISecuritySchemes())
+		assert.Nil(err)
+		assert.Equal(`{"accessToken":{"type":"apiKey","in":"query","name":"access_token"},`+
+			`"bearerAuth":{"type":"http","scheme":"bearer","bearerFormat":"JWT"}}`, string(buf))
+
+		buf, err = json.Marshal(a.OpenAPISecurity("orders:delete"))
+		assert.Nil(err)
+		assert.Equal(`[{"accessToken":["orders:delete"]},{"bearerAuth":["orders:delete"]}]`, string(buf))
+		buf, err = json.Marshal(a.OpenAPISecurity())
+		assert.Nil(err)
+		assert.Equal(`[{"accessToken":[]},{"bearerAuth":[]}]`, string(buf))
+
+		a.OpenAPISecuritySchemes()["bearerAuth"].BearerFormat = "opaque"
+		assert.Equal("JWT", a.OpenAPISecuritySchemes()["bearerAuth"].BearerFormat)
+	})
+
+	t.Run("should export custom security schemes", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := New([]byte("my key"))
+		assert.Panics(func() {
+			a.SetSecurityScheme("", &SecurityScheme{Type: "apiKey"})
+		})
+		a.SetTokenParser(func(ctx *gear.Context) string {
+			return ctx.GetHeader("X-Api-Token")
+		})
+		assert.Equal(0, len(a.OpenAPISecuritySchemes()))
+		assert.Equal(0, len(a.OpenAPISecurity("orders:read")))
+
+		a.SetSecurityScheme("apiToken", &SecurityScheme{Type: "apiKey", In: "header", Name: "X-Api-Token"}).
+			SetSecurityScheme("rememberMe", &SecurityScheme{Type: "apiKey", In: "cookie", Name: "remember_me"})
+		buf, err := json.Marshal(a.OpenAPISecurity("orders:read"))
+		assert.Nil(err)
+		assert.Equal(`[{"apiToken":["orders:read"]},{"rememberMe":["orders:read"]}]`, string(buf))
+
+		a.SetSecurityScheme("rememberMe", nil)
+		assert.Equal(1, len(a.OpenAPISecuritySchemes()))
+		assert.Equal("X-Api-Token", a.OpenAPISecuritySchemes()["apiToken"].Name)
+	})
+}
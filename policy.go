@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/teambition/gear"
+)
+
+// PolicyInput is the input document of policy evaluation.
+type PolicyInput struct {
+	Method string                 `json:"method"`
+	Path   string                 `json:"path"`
+	Claims map[string]interface{} `json:"claims"`
+}
+
+// PolicyDecision is the result of policy evaluation. Obligations are extra instructions the request
+// must honor when allowed, such as fields to filter from the response.
+type PolicyDecision struct {
+	Allow       bool                   `json:"allow"`
+	Obligations map[string]interface{} `json:"obligations,omitempty"`
+}
+
+// PolicyEvaluator evaluates an authorization policy. OPAClient implements it with a remote OPA server,
+// an embedded Rego engine can be plugged by implementing it with the OPA Go SDK in the app.
+type PolicyEvaluator interface {
+	Evaluate(ctx context.Context, input *PolicyInput) (*PolicyDecision, error)
+}
+
+// OPAClient is a PolicyEvaluator that queries a policy decision from the OPA REST API.
+type OPAClient struct {
+	url    string
+	client *http.Client
+}
+
+// NewOPAClient returns an OPAClient querying the url of a OPA data document, such as
+// "http://localhost:8181/v1/data/httpapi/authz". The document can be a boolean (allow or deny),
+// or an object with "allow" and "obligations". An undefined document denies.
+func NewOPAClient(url string) *OPAClient {
+	if url == "" {
+		panic(errors.New("invalid OPA url"))
+	}
+	return &OPAClient{url: url, client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+// SetHTTPClient set a custom http.Client to query OPA, such as with mTLS.
+// Default to a client with 5 seconds timeout.
+func (c *OPAClient) SetHTTPClient(client *http.Client) *OPAClient {
+	if client == nil {
+		panic(errors.New("invalid http client"))
+	}
+	c.client = client
+	return c
+}
+
+// Evaluate implements the PolicyEvaluator interface.
+func (c *OPAClient) Evaluate(ctx context.Context, input *PolicyInput) (*PolicyDecision, error) {
+	body, err := json.Marshal(map[string]interface{}{"input": input})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("POST", c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OPA responded with status %d", res.StatusCode)
+	}
+
+	var result struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err = json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	decision := &PolicyDecision{}
+	if len(result.Result) == 0 {
+		return decision, nil
+	}
+	if err = json.Unmarshal(result.Result, &decision.Allow); err == nil {
+		return decision, nil
+	}
+	if err = json.Unmarshal(result.Result, decision); err != nil {
+		return nil, err
+	}
+	return decision, nil
+}
+
+// policyObligations is the key to cache the obligations of the policy decision on gear.Context.
+type policyObligations struct {
+	a *Auth
+}
+
+// RequirePolicy returns a gear middleware that evaluates the policy with the verified claims,
+// the request method and path as input. Requests without a valid token are rejected with 401,
+// denied requests with 403, and requests are rejected with 503 if the policy can't be evaluated.
+// Obligations of allowed decisions can be read by ObligationsFromCtx.
+//
+//  app.Use(auther.RequirePolicy(auth.NewOPAClient("http://localhost:8181/v1/data/httpapi/authz")))
+//
+func (a *Auth) RequirePolicy(policy PolicyEvaluator) gear.Middleware {
+	if policy == nil {
+		panic(errors.New("invalid policy evaluator"))
+	}
+	return func(ctx *gear.Context) error {
+		claims, err := a.FromCtx(ctx)
+		if err != nil {
+			return a.challenge(ctx, err)
+		}
+		decision, err := policy.Evaluate(ctx, &PolicyInput{Method: ctx.Method, Path: ctx.Path, Claims: claims})
+		if err != nil {
+			return gear.ErrServiceUnavailable.WithMsg("policy evaluation failed")
+		}
+		if !decision.Allow {
+			return a.challenge(ctx, gear.ErrForbidden.WithMsg("denied by policy"))
+		}
+		ctx.SetAny(policyObligations{a}, decision.Obligations)
+		return nil
+	}
+}
+
+// ObligationsFromCtx returns the obligations of the allowed policy decision for the request,
+// it returns nil if there is no obligation or RequirePolicy is not used.
+//
+//  obligations := auther.ObligationsFromCtx(ctx)
+//  if fields, ok := obligations["filtered_fields"].([]interface{}); ok {
+//  	// remove fields from the response
+//  }
+//
+func (a *Auth) ObligationsFromCtx(ctx *gear.Context) map[string]interface{} {
+	val, err := ctx.Any(policyObligations{a})
+	if err != nil {
+		return nil
+	}
+	obligations, _ := val.(map[string]interface{})
+	return obligations
+}
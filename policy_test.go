@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/teambition/gear"
+)
+
+func TestPolicy(t *testing.T) {
+	t.Run("OPAClient", func(t *testing.T) {
+		assert := assert.New(t)
+
+		assert.Panics(func() {
+			NewOPAClient("")
+		})
+		assert.Panics(func() {
+			NewOPAClient("http://opa").SetHTTPClient(nil)
+		})
+
+		var inputs []PolicyInput
+		response := `{"result": true}`
+		status := 200
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Input PolicyInput `json:"input"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			inputs = append(inputs, body.Input)
+			w.WriteHeader(status)
+			w.Write([]byte(response))
+		}))
+		defer ts.Close()
+
+		client := NewOPAClient(ts.URL + "/v1/data/httpapi/authz").SetHTTPClient(ts.Client())
+		input := &PolicyInput{Method: "GET", Path: "/orders", Claims: map[string]interface{}{"sub": "alice"}}
+		decision, err := client.Evaluate(context.Background(), input)
+		assert.Nil(err)
+		assert.True(decision.Allow)
+		assert.Equal("alice", inputs[0].Claims["sub"])
+		assert.Equal("/orders", inputs[0].Path)
+
+		response = `{"result": {"allow": true, "obligations": {"filtered_fields": ["price"]}}}`
+		decision, err = client.Evaluate(context.Background(), input)
+		assert.Nil(err)
+		assert.True(decision.Allow)
+		assert.Equal([]interface{}{"price"}, decision.Obligations["filtered_fields"])
+
+		response = `{}`
+		decision, err = client.Evaluate(context.Background(), input)
+		assert.Nil(err)
+		assert.False(decision.Allow)
+
+		status = 500
+		_, err = client.Evaluate(context.Background(), input)
+		assert.Equal("OPA responded with status 500", err.Error())
+	})
+
+	t.Run("RequirePolicy", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := New([]byte("my key"))
+		assert.Panics(func() {
+			a.RequirePolicy(nil)
+		})
+
+		response := `{"result": {"allow": true, "obligations": {"filtered_fields": ["price"]}}}`
+		opa := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Input PolicyInput `json:"input"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			if body.Input.Claims["sub"] != "alice" || body.Input.Method != "GET" {
+				w.Write([]byte(`{"result": false}`))
+				return
+			}
+			w.Write([]byte(response))
+		}))
+		defer opa.Close()
+
+		app := gear.New()
+		app.Use(a.RequirePolicy(NewOPAClient(opa.URL)))
+		app.Use(func(ctx *gear.Context) error {
+			return ctx.JSON(200, a.ObligationsFromCtx(ctx))
+		})
+		srv := app.Start()
+		defer srv.Close()
+
+		req := NewRequst()
+		host := "http://" + srv.Addr().String()
+
+		res, err := req.Get(host)
+		assert.Nil(err)
+		assert.Equal(401, res.StatusCode)
+		assert.Equal("Bearer", res.Header.Get("WWW-Authenticate"))
+		res.Body.Close()
+
+		token, _ := a.JWT().Sign(jwt.Claims{"sub": "bob"})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(403, res.StatusCode)
+		assert.Equal(`Bearer error="insufficient_scope", error_description="denied by policy"`,
+			res.Header.Get("WWW-Authenticate"))
+		body, _ := res.Text()
+		assert.Equal(`{"error":"Forbidden","message":"denied by policy"}`, body)
+
+		token, _ = a.JWT().Sign(jwt.Claims{"sub": "alice"})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(200, res.StatusCode)
+		body, _ = res.Text()
+		assert.Equal(`{"filtered_fields":["price"]}`, body)
+
+		response = `{"result": {"allow": "yes"}}`
+		res, err = req.Get(host)
+		assert.Nil(err)
+		assert.Equal(503, res.StatusCode)
+		res.Body.Close()
+	})
+}
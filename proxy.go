@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	josejwt "github.com/SermoDigital/jose/jwt"
+	"github.com/teambition/gear"
+	"github.com/teambition/gear-auth/jwt"
+)
+
+// Upstream is the token forwarding config of an upstream service.
+type Upstream struct {
+	// Audience is the audience of the upstream. Inbound tokens already having the audience are forwarded
+	// as is, otherwise a narrowed token is re-minted with the audience.
+	Audience string
+	// Claims are the claim names copied from the inbound token to re-minted tokens, "sub" is always copied.
+	Claims []string
+	// ExpiresIn is the expiration of re-minted tokens, default to 5 minutes.
+	ExpiresIn time.Duration
+}
+
+// TokenForwarder attaches tokens to outbound requests toward upstreams, for gateway-style services.
+// Requests to hosts without Upstream config are refused, so that tokens never leak to unknown hosts.
+type TokenForwarder struct {
+	a         *Auth
+	signer    jwt.Signer
+	upstreams map[string]Upstream
+}
+
+// NewTokenForwarder returns a TokenForwarder for the auth, signer is used to re-mint narrowed tokens,
+// it should not set audience by itself.
+//
+//  forwarder := auther.NewTokenForwarder(minter).
+//  	AddUpstream("billing.internal:8080", auth.Upstream{Audience: "billing", Claims: []string{"tenant"}})
+//  // in handler
+//  req, _ := http.NewRequest("GET", "http://billing.internal:8080/invoices", nil)
+//  if err := forwarder.Attach(ctx, req); err != nil {
+//  	return err
+//  }
+//
+func (a *Auth) NewTokenForwarder(signer jwt.Signer) *TokenForwarder {
+	if signer == nil {
+		panic(errors.New("invalid token signer"))
+	}
+	return &TokenForwarder{a: a, signer: signer, upstreams: make(map[string]Upstream)}
+}
+
+// AddUpstream adds the Upstream config for the host (with port if any) of outbound request URLs.
+func (f *TokenForwarder) AddUpstream(host string, upstream Upstream) *TokenForwarder {
+	if host == "" || upstream.Audience == "" {
+		panic(errors.New("invalid upstream"))
+	}
+	if upstream.ExpiresIn <= 0 {
+		upstream.ExpiresIn = 5 * time.Minute
+	}
+	f.upstreams[host] = upstream
+	return f
+}
+
+// Attach verifies the inbound token of the ctx, and attaches the original or a re-minted token
+// to the outbound request as a bearer token.
+func (f *TokenForwarder) Attach(ctx *gear.Context, req *http.Request) error {
+	upstream, ok := f.upstreams[req.URL.Host]
+	if !ok {
+		return errors.New("unknown upstream: " + req.URL.Host)
+	}
+	t, err := f.a.TokenFromCtx(ctx)
+	if err != nil {
+		return err
+	}
+	if aud, _ := t.Claims.Audience(); containsString(aud, upstream.Audience) {
+		t.SetAuthHeader(req)
+		return nil
+	}
+
+	claims := josejwt.Claims{"aud": upstream.Audience}
+	// upstream.Claims is shared by concurrent requests, so it is never appended to.
+	for _, name := range append([]string{"sub"}, upstream.Claims...) {
+		if t.Claims.Has(name) {
+			claims.Set(name, t.Claims.Get(name))
+		}
+	}
+	token, err := f.signer.Sign(claims, upstream.ExpiresIn)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+func containsString(list []string, str string) bool {
+	for _, s := range list {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
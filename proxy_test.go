@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/SermoDigital/jose/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/teambition/gear"
+	authjwt "github.com/teambition/gear-auth/jwt"
+)
+
+func TestTokenForwarder(t *testing.T) {
+	t.Run("should attach original or re-minted token", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := New([]byte("my key"))
+		minter := authjwt.New([]byte("upstream key"))
+		assert.Panics(func() {
+			a.NewTokenForwarder(nil)
+		})
+		forwarder := a.NewTokenForwarder(minter).
+			AddUpstream("orders.internal", Upstream{Audience: "orders"}).
+			AddUpstream("billing.internal:8080", Upstream{Audience: "billing", Claims: []string{"tenant"}})
+		assert.Panics(func() {
+			forwarder.AddUpstream("", Upstream{})
+		})
+
+		authorizations := map[string]string{}
+		app := gear.New()
+		app.UseHandler(a)
+		app.Use(func(ctx *gear.Context) error {
+			for _, url := range []string{"http://orders.internal/", "http://billing.internal:8080/", "http://evil.com/"} {
+				req, _ := http.NewRequest("GET", url, nil)
+				if err := forwarder.Attach(ctx, req); err != nil {
+					authorizations[req.URL.Host] = err.Error()
+				} else {
+					authorizations[req.URL.Host] = req.Header.Get("Authorization")
+				}
+			}
+			return ctx.End(204)
+		})
+		srv := app.Start()
+		defer srv.Close()
+
+		req := NewRequst()
+		host := "http://" + srv.Addr().String()
+
+		token, _ := a.JWT().Sign(jwt.Claims{"sub": "alice", "aud": []string{"gateway", "orders"}, "tenant": "t1", "role": "admin"})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err := req.Get(host)
+		assert.Nil(err)
+		assert.Equal(204, res.StatusCode)
+		res.Body.Close()
+
+		assert.Equal("Bearer "+token, authorizations["orders.internal"])
+		assert.Equal("unknown upstream: evil.com", authorizations["evil.com"])
+		claims, err := minter.Verify(authorizations["billing.internal:8080"][7:])
+		assert.Nil(err)
+		assert.Equal("alice", claims.Get("sub"))
+		assert.Equal("t1", claims.Get("tenant"))
+		assert.Nil(claims.Get("role"))
+		aud, _ := claims.Audience()
+		assert.Equal([]string{"billing"}, aud)
+		exp, _ := claims.Expiration()
+		assert.True(exp.Before(time.Now().Add(5*time.Minute + time.Second)))
+	})
+
+	t.Run("should not modify the claim names of upstreams", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := New([]byte("my key"))
+		names := make([]string, 1, 2)
+		names[0] = "tenant"
+		forwarder := a.NewTokenForwarder(authjwt.New([]byte("upstream key"))).
+			AddUpstream("billing.internal", Upstream{Audience: "billing", Claims: names})
+
+		app := gear.New()
+		app.UseHandler(a)
+		app.Use(func(ctx *gear.Context) error {
+			req, _ := http.NewRequest("GET", "http://billing.internal/", nil)
+			if err := forwarder.Attach(ctx, req); err != nil {
+				return err
+			}
+			return ctx.End(204)
+		})
+		srv := app.Start()
+		defer srv.Close()
+
+		req := NewRequst()
+		token, _ := a.JWT().Sign(jwt.Claims{"sub": "alice", "tenant": "t1"})
+		req.Headers["Authorization"] = "Bearer " + token
+		res, err := req.Get("http://" + srv.Addr().String())
+		assert.Nil(err)
+		assert.Equal(204, res.StatusCode)
+		res.Body.Close()
+		assert.Equal([]string{"tenant", ""}, names[:2])
+	})
+}
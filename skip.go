@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"strings"
+
+	"github.com/teambition/gear"
+)
+
+// SkipPaths returns a skip function for SetSkipper, it skips requests with any of the paths.
+// A path ending with "/*" matches all paths under it.
+//
+//  auther.SetSkipper(auth.SkipPaths("/health", "/metrics", "/login", "/static/*"))
+//
+func SkipPaths(paths ...string) func(*gear.Context) bool {
+	exact := make(map[string]bool, len(paths))
+	var prefixes []string
+	for _, path := range paths {
+		if strings.HasSuffix(path, "/*") {
+			prefixes = append(prefixes, path[:len(path)-1])
+		} else {
+			exact[path] = true
+		}
+	}
+	return func(ctx *gear.Context) bool {
+		if exact[ctx.Path] {
+			return true
+		}
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(ctx.Path, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// SkipMethods returns a skip function for SetSkipper, it skips requests with any of the HTTP methods.
+//
+//  auther.SetSkipper(auth.SkipMethods(http.MethodOptions, http.MethodHead))
+//
+func SkipMethods(methods ...string) func(*gear.Context) bool {
+	return func(ctx *gear.Context) bool {
+		for _, method := range methods {
+			if strings.EqualFold(ctx.Method, method) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// SkipAny returns a skip function for SetSkipper, it skips requests if any of the skip functions returns true.
+//
+//  auther.SetSkipper(auth.SkipAny(auth.SkipPaths("/health"), auth.SkipMethods(http.MethodOptions)))
+//
+func SkipAny(skippers ...func(*gear.Context) bool) func(*gear.Context) bool {
+	return func(ctx *gear.Context) bool {
+		for _, skip := range skippers {
+			if skip(ctx) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/teambition/gear"
+)
+
+func TestSkipper(t *testing.T) {
+	t.Run("should skip paths and methods", func(t *testing.T) {
+		assert := assert.New(t)
+
+		a := New([]byte("my key"))
+		a.SetSkipper(SkipAny(
+			SkipPaths("/health", "/static/*"),
+			SkipMethods(http.MethodOptions),
+		))
+		app := gear.New()
+		app.UseHandler(a)
+		app.Use(func(ctx *gear.Context) error {
+			return ctx.End(204)
+		})
+		srv := app.Start()
+		defer srv.Close()
+
+		req := NewRequst()
+		host := "http://" + srv.Addr().String()
+
+		for path, status := range map[string]int{
+			"/health":        204,
+			"/health/x":      401,
+			"/static/app.js": 204,
+			"/static":        401,
+			"/staticx/a":     401,
+			"/orders":        401,
+		} {
+			res, err := req.Get(host + path)
+			assert.Nil(err)
+			assert.Equal(status, res.StatusCode, path)
+			res.Body.Close()
+		}
+
+		res, err := req.Options(host + "/orders")
+		assert.Nil(err)
+		assert.Equal(204, res.StatusCode)
+		res.Body.Close()
+		res, err = req.Post(host + "/health")
+		assert.Nil(err)
+		assert.Equal(204, res.StatusCode)
+		res.Body.Close()
+	})
+}